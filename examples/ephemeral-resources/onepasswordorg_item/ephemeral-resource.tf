@@ -0,0 +1,10 @@
+ephemeral "onepasswordorg_item" "db" {
+  vault = "vault-id"
+  title = "database"
+}
+
+provider "postgresql" {
+  host     = ephemeral.onepasswordorg_item.db.hostname
+  username = ephemeral.onepasswordorg_item.db.username
+  password = ephemeral.onepasswordorg_item.db.password
+}
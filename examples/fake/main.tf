@@ -99,7 +99,7 @@ resource "onepasswordorg_vault_group_access" "test" {
 
   vault_id = each.value.vault_id
   group_id = each.value.group_id
-  permissions = {
+  permissions {
     allow_viewing           = true
     allow_editing           = true
     allow_managing          = false
@@ -123,7 +123,7 @@ resource "onepasswordorg_vault_user_access" "test" {
 
   vault_id = each.value.vault_id
   user_id  = each.value.user_id
-  permissions = {
+  permissions {
     allow_viewing           = false
     allow_editing           = true
     allow_managing          = false
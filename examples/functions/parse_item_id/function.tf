@@ -0,0 +1,3 @@
+output "item_vault_id" {
+  value = provider::onepasswordorg::parse_item_id(onepasswordorg_item.test.id).vault_id
+}
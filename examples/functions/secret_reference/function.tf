@@ -0,0 +1,4 @@
+output "db_password_reference" {
+  # op://infra/database/prod/password
+  value = provider::onepasswordorg::secret_reference("infra", "database", "password", "prod")
+}
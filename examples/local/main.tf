@@ -33,7 +33,7 @@ resource "onepasswordorg_vault" "test" {
 resource "onepasswordorg_vault_group_access" "test" {
   vault_id = onepasswordorg_vault.test.id
   group_id = onepasswordorg_group.test.id
-  permissions = {
+  permissions {
     archive_items           = true
     copy_and_share_items    = true
     create_items            = true
@@ -52,7 +52,7 @@ resource "onepasswordorg_vault_group_access" "test" {
 resource "onepasswordorg_vault_user_access" "test" {
   vault_id = onepasswordorg_vault.test.id
   user_id  = onepasswordorg_user.test.id
-  permissions = {
+  permissions {
     archive_items           = false
     copy_and_share_items    = false
     create_items            = false
@@ -11,7 +11,7 @@ resource "onepasswordorg_group" "group0" {
 resource "onepasswordorg_vault_group_access" "business_full" {
   vault_id = onepasswordorg_vault.vault0.id
   group_id = onepasswordorg_group.group0.id
-  permissions = {
+  permissions {
     view_items              = true
     create_items            = true
     edit_items              = true
@@ -35,7 +35,7 @@ resource "onepasswordorg_group" "group1" {
 resource "onepasswordorg_vault_group_access" "team_view" {
   vault_id = onepasswordorg_vault.vault0.id
   group_id = onepasswordorg_group.group1.id
-  permissions = {
+  permissions {
     allow_viewing = true
   }
 }
@@ -48,7 +48,7 @@ resource "onepasswordorg_group" "group2" {
 resource "onepasswordorg_vault_group_access" "business_regular" {
   vault_id = onepasswordorg_vault.vault0.id
   group_id = onepasswordorg_group.group2.id
-  permissions = {
+  permissions {
     view_items              = true
     create_items            = true
     edit_items              = true
@@ -72,7 +72,7 @@ resource "onepasswordorg_group" "group3" {
 resource "onepasswordorg_vault_group_access" "business_manage" {
   vault_id = onepasswordorg_vault.vault0.id
   group_id = onepasswordorg_group.group3.id
-  permissions = {
+  permissions {
     manage_vault = true
   }
 }
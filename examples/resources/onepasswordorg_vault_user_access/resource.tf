@@ -11,7 +11,7 @@ resource "onepasswordorg_user" "user0" {
 resource "onepasswordorg_vault_user_access" "business_full" {
   vault_id = onepasswordorg_vault.vault0.id
   user_id  = onepasswordorg_user.user0.id
-  permissions = {
+  permissions {
     view_items              = true
     create_items            = true
     edit_items              = true
@@ -35,7 +35,7 @@ resource "onepasswordorg_user" "user1" {
 resource "onepasswordorg_vault_user_access" "team_view" {
   vault_id = onepasswordorg_vault.vault0.id
   user_id  = onepasswordorg_user.user1.id
-  permissions = {
+  permissions {
     allow_viewing = true
   }
 }
@@ -48,7 +48,7 @@ resource "onepasswordorg_user" "user2" {
 resource "onepasswordorg_vault_user_access" "business_regular" {
   vault_id = onepasswordorg_vault.vault0.id
   user_id  = onepasswordorg_user.user2.id
-  permissions = {
+  permissions {
     view_items              = true
     create_items            = true
     edit_items              = true
@@ -72,7 +72,7 @@ resource "onepasswordorg_user" "user3" {
 resource "onepasswordorg_vault_user_access" "business_manage" {
   vault_id = onepasswordorg_vault.vault0.id
   user_id  = onepasswordorg_user.user3.id
-  permissions = {
+  permissions {
     manage_vault = true
   }
 }
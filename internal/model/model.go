@@ -27,6 +27,8 @@ type MembershipRole int
 const (
 	MembershipRoleMember MembershipRole = iota
 	MembershipRoleManager
+	// MembershipRoleUnknown is the role of the members with a role the provider doesn't know.
+	MembershipRoleUnknown
 )
 
 // Role represents a 1password user membership into a group.
@@ -86,6 +88,7 @@ type Section struct {
 
 type URL struct {
 	URL     string
+	Label   string
 	Primary bool
 }
 
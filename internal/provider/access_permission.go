@@ -1,79 +1,123 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
 )
 
-var permissionsAttribute = &schema.Schema{
-	Description: `The permissions of the access. Note: Not all permissions are available in all plans, and some permissions require others. More info in [1password docs](https://developer.1password.com/docs/cli/vault-permissions/).`,
-	// TypeMap is currenty not supported in v2 sdk.
-	Type:     schema.TypeList,
-	Optional: true,
-	Elem: &schema.Resource{
-		Schema: map[string]*schema.Schema{
-			"allow_viewing":           {Type: schema.TypeBool, Optional: true, Default: false},
-			"allow_editing":           {Type: schema.TypeBool, Optional: true, Default: false},
-			"allow_managing":          {Type: schema.TypeBool, Optional: true, Default: false},
-			"view_items":              {Type: schema.TypeBool, Optional: true, Default: false},
-			"create_items":            {Type: schema.TypeBool, Optional: true, Default: false},
-			"edit_items":              {Type: schema.TypeBool, Optional: true, Default: false},
-			"archive_items":           {Type: schema.TypeBool, Optional: true, Default: false},
-			"delete_items":            {Type: schema.TypeBool, Optional: true, Default: false},
-			"view_and_copy_passwords": {Type: schema.TypeBool, Optional: true, Default: false},
-			"view_item_history":       {Type: schema.TypeBool, Optional: true, Default: false},
-			"import_items":            {Type: schema.TypeBool, Optional: true, Default: false},
-			"export_items":            {Type: schema.TypeBool, Optional: true, Default: false},
-			"copy_and_share_items":    {Type: schema.TypeBool, Optional: true, Default: false},
-			"print_items":             {Type: schema.TypeBool, Optional: true, Default: false},
-			"manage_vault":            {Type: schema.TypeBool, Optional: true, Default: false},
-		},
-	},
+var accessPermissionNames = []string{
+	"allow_viewing",
+	"allow_editing",
+	"allow_managing",
+	"view_items",
+	"create_items",
+	"edit_items",
+	"archive_items",
+	"delete_items",
+	"view_and_copy_passwords",
+	"view_item_history",
+	"import_items",
+	"export_items",
+	"copy_and_share_items",
+	"print_items",
+	"manage_vault",
 }
 
-func ensureDefaultValue(v interface{}) bool {
-	if v == nil {
-		return false
+// permissionsBlock returns the `permissions` block, a list block with a single element like the
+// SDKv2 resources had, so the configurations and states are the same.
+func permissionsBlock() schema.ListNestedBlock {
+	attrs := map[string]schema.Attribute{}
+	for _, name := range accessPermissionNames {
+		// Not set permissions are not granted.
+		attrs[name] = schema.BoolAttribute{
+			Optional: true,
+			Computed: true,
+			Default:  booldefault.StaticBool(false),
+		}
 	}
-	return v.(bool)
+
+	return schema.ListNestedBlock{
+		Description:  `The permissions of the access. Note: Not all permissions are available in all plans, and some permissions require others. More info in [1password docs](https://developer.1password.com/docs/cli/vault-permissions/).`,
+		NestedObject: schema.NestedBlockObject{Attributes: attrs},
+		Validators:   []validator.List{maxOneElement{}},
+	}
+}
+
+// maxOneElement validates the list has one element at most.
+type maxOneElement struct{}
+
+func (v maxOneElement) Description(ctx context.Context) string {
+	return "list must have one element at most"
+}
+
+func (v maxOneElement) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
 }
 
-func dataToAccessPermissions(ap map[string]interface{}) model.AccessPermissions {
+func (v maxOneElement) ValidateList(ctx context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if len(req.ConfigValue.Elements()) > 1 {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid permissions", "Only one permissions block can be set.")
+	}
+}
+
+func dataToAccessPermissions(aps []AccessPermissions) model.AccessPermissions {
+	// Without permissions block there are no permissions granted.
+	if len(aps) == 0 {
+		return model.AccessPermissions{}
+	}
+	ap := aps[0]
+
 	return model.AccessPermissions{
-		AllowViewing:         ensureDefaultValue(ap["allow_viewing"]),
-		AllowEditing:         ensureDefaultValue(ap["allow_editing"]),
-		AllowManaging:        ensureDefaultValue(ap["allow_managing"]),
-		ViewItems:            ensureDefaultValue(ap["view_items"]),
-		CreateItems:          ensureDefaultValue(ap["create_items"]),
-		EditItems:            ensureDefaultValue(ap["edit_items"]),
-		ArchiveItems:         ensureDefaultValue(ap["archive_items"]),
-		DeleteItems:          ensureDefaultValue(ap["delete_items"]),
-		ViewAndCopyPasswords: ensureDefaultValue(ap["view_and_copy_passwords"]),
-		ViewItemHistory:      ensureDefaultValue(ap["view_item_history"]),
-		ImportItems:          ensureDefaultValue(ap["import_items"]),
-		ExportItems:          ensureDefaultValue(ap["export_items"]),
-		CopyAndShareItems:    ensureDefaultValue(ap["copy_and_share_items"]),
-		PrintItems:           ensureDefaultValue(ap["print_items"]),
-		ManageVault:          ensureDefaultValue(ap["manage_vault"]),
+		AllowViewing:         ap.AllowViewing.ValueBool(),
+		AllowEditing:         ap.AllowEditing.ValueBool(),
+		AllowManaging:        ap.AllowManaging.ValueBool(),
+		ViewItems:            ap.ViewItems.ValueBool(),
+		CreateItems:          ap.CreateItems.ValueBool(),
+		EditItems:            ap.EditItems.ValueBool(),
+		ArchiveItems:         ap.ArchiveItems.ValueBool(),
+		DeleteItems:          ap.DeleteItems.ValueBool(),
+		ViewAndCopyPasswords: ap.ViewAndCopyPasswords.ValueBool(),
+		ViewItemHistory:      ap.ViewItemHistory.ValueBool(),
+		ImportItems:          ap.ImportItems.ValueBool(),
+		ExportItems:          ap.ExportItems.ValueBool(),
+		CopyAndShareItems:    ap.CopyAndShareItems.ValueBool(),
+		PrintItems:           ap.PrintItems.ValueBool(),
+		ManageVault:          ap.ManageVault.ValueBool(),
 	}
 }
 
-func accessPermissionsToData(m model.AccessPermissions) map[string]interface{} {
-	return map[string]interface{}{
-		"allow_viewing":           m.AllowViewing,
-		"allow_editing":           m.AllowEditing,
-		"allow_managing":          m.AllowManaging,
-		"view_items":              m.ViewItems,
-		"create_items":            m.CreateItems,
-		"edit_items":              m.EditItems,
-		"archive_items":           m.ArchiveItems,
-		"delete_items":            m.DeleteItems,
-		"view_and_copy_passwords": m.ViewAndCopyPasswords,
-		"view_item_history":       m.ViewItemHistory,
-		"import_items":            m.ImportItems,
-		"export_items":            m.ExportItems,
-		"copy_and_share_items":    m.CopyAndShareItems,
-		"print_items":             m.PrintItems,
-		"manage_vault":            m.ManageVault,
+// accessPermissionsToData returns the permissions block, without permissions granted it's kept
+// not set if it was not set (prior), so the optional block doesn't show differences.
+func accessPermissionsToData(m model.AccessPermissions, prior []AccessPermissions) []AccessPermissions {
+	if len(prior) == 0 && m == (model.AccessPermissions{}) {
+		return []AccessPermissions{}
 	}
+
+	return []AccessPermissions{{
+		AllowViewing:         types.BoolValue(m.AllowViewing),
+		AllowEditing:         types.BoolValue(m.AllowEditing),
+		AllowManaging:        types.BoolValue(m.AllowManaging),
+		ViewItems:            types.BoolValue(m.ViewItems),
+		CreateItems:          types.BoolValue(m.CreateItems),
+		EditItems:            types.BoolValue(m.EditItems),
+		ArchiveItems:         types.BoolValue(m.ArchiveItems),
+		DeleteItems:          types.BoolValue(m.DeleteItems),
+		ViewAndCopyPasswords: types.BoolValue(m.ViewAndCopyPasswords),
+		ViewItemHistory:      types.BoolValue(m.ViewItemHistory),
+		ImportItems:          types.BoolValue(m.ImportItems),
+		ExportItems:          types.BoolValue(m.ExportItems),
+		CopyAndShareItems:    types.BoolValue(m.CopyAndShareItems),
+		PrintItems:           types.BoolValue(m.PrintItems),
+		ManageVault:          types.BoolValue(m.ManageVault),
+	}}
 }
@@ -28,19 +28,19 @@ data "onepasswordorg_group" "test" {
 `
 	// Prepare storage.
 	repo := getFakeRepository(t)
-	_, err := repo.CreateGroup(context.TODO(), model.Group{Name: "test-group", Description: "Test group"})
+	g, err := repo.CreateGroup(context.TODO(), model.Group{Name: "test-group", Description: "Test group"})
 	require.NoError(t, err)
-	defer func() { _ = repo.DeleteGroup(context.TODO(), "test-group") }()
+	defer func() { _ = repo.DeleteGroup(context.TODO(), g.ID) }()
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: config,
 				Check: resource.ComposeAggregateTestCheckFunc(
-					resource.TestCheckResourceAttr("data.onepasswordorg_group.test", "id", "test-group"), // Fake uses user name ID.
+					resource.TestCheckResourceAttr("data.onepasswordorg_group.test", "id", g.ID),
 					resource.TestCheckResourceAttr("data.onepasswordorg_group.test", "description", "Test group"),
 					resource.TestCheckResourceAttr("data.onepasswordorg_group.test", "name", "test-group"),
 				),
@@ -65,8 +65,8 @@ data "onepasswordorg_group" "test" {
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config:      config,
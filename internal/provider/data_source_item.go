@@ -202,7 +202,7 @@ func dataSourceItem() *schema.Resource {
 }
 
 func terraformID(item model.Item) string {
-	return fmt.Sprintf("vaults/%s/items/%s", item.Vault.ID, item.ID)
+	return packItemID(item.Vault.ID, item.ID)
 }
 
 func dataSourceItemRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
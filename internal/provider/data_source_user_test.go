@@ -28,19 +28,19 @@ data "onepasswordorg_user" "test" {
 `
 	// Prepare storage.
 	repo := getFakeRepository(t)
-	_, err := repo.CreateUser(context.TODO(), model.User{Email: "test@slok.dev", Name: "Test user"})
+	u, err := repo.CreateUser(context.TODO(), model.User{Email: "test@slok.dev", Name: "Test user"})
 	require.NoError(t, err)
-	defer func() { _ = repo.DeleteUser(context.TODO(), "test@slok.dev") }()
+	defer func() { _ = repo.DeleteUser(context.TODO(), u.ID) }()
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: config,
 				Check: resource.ComposeAggregateTestCheckFunc(
-					resource.TestCheckResourceAttr("data.onepasswordorg_user.test", "id", "test@slok.dev"), // Fake uses user email ID.
+					resource.TestCheckResourceAttr("data.onepasswordorg_user.test", "id", u.ID),
 					resource.TestCheckResourceAttr("data.onepasswordorg_user.test", "email", "test@slok.dev"),
 					resource.TestCheckResourceAttr("data.onepasswordorg_user.test", "name", "Test user"),
 				),
@@ -65,8 +65,8 @@ data "onepasswordorg_user" "test" {
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config:      config,
@@ -28,19 +28,19 @@ data "onepasswordorg_vault" "test" {
 `
 	// Prepare storage.
 	repo := getFakeRepository(t)
-	_, err := repo.CreateVault(context.TODO(), model.Vault{Name: "test-vault", Description: "Test vault"})
+	v, err := repo.CreateVault(context.TODO(), model.Vault{Name: "test-vault", Description: "Test vault"})
 	require.NoError(t, err)
-	defer func() { _ = repo.DeleteVault(context.TODO(), "test-vault") }()
+	defer func() { _ = repo.DeleteVault(context.TODO(), v.ID) }()
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: config,
 				Check: resource.ComposeAggregateTestCheckFunc(
-					resource.TestCheckResourceAttr("data.onepasswordorg_vault.test", "id", "test-vault"), // Fake uses user name ID.
+					resource.TestCheckResourceAttr("data.onepasswordorg_vault.test", "id", v.ID),
 					resource.TestCheckResourceAttr("data.onepasswordorg_vault.test", "description", "Test vault"),
 					resource.TestCheckResourceAttr("data.onepasswordorg_vault.test", "name", "test-vault"),
 				),
@@ -65,8 +65,8 @@ data "onepasswordorg_vault" "test" {
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config:      config,
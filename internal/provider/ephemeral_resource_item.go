@@ -0,0 +1,283 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+)
+
+type itemEphemeralResource struct {
+	p ProviderConfig
+}
+
+var (
+	_ ephemeral.EphemeralResourceWithConfigure      = &itemEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithValidateConfig = &itemEphemeralResource{}
+)
+
+func newItemEphemeralResource() ephemeral.EphemeralResource {
+	return &itemEphemeralResource{}
+}
+
+func (r *itemEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_item"
+}
+
+func (r *itemEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this ephemeral resource to get details of an item by its vault uuid and either the title or the uuid of the item, without storing them on the plan nor the state (requires Terraform 1.10 or higher).",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The Terraform resource identifier for this item in the format `vaults/<vault_id>/items/<item_id>`",
+				Computed:    true,
+			},
+			"vault": schema.StringAttribute{
+				Description: vaultUUIDDescription,
+				Required:    true,
+			},
+			"uuid": schema.StringAttribute{
+				Description: "The UUID of the item to retrieve. This field will be populated with the UUID of the item if the item it looked up by its title.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"title": schema.StringAttribute{
+				Description: "The title of the item to retrieve. This field will be populated with the title of the item if the item it looked up by its UUID.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"category": schema.StringAttribute{
+				Description: fmt.Sprintf(enumDescription, categoryDescription, categories),
+				Computed:    true,
+			},
+			"url": schema.StringAttribute{
+				Description: urlDescription,
+				Computed:    true,
+			},
+			"hostname": schema.StringAttribute{
+				Description: dbHostnameDescription,
+				Computed:    true,
+			},
+			"database": schema.StringAttribute{
+				Description: dbDatabaseDescription,
+				Computed:    true,
+			},
+			"port": schema.StringAttribute{
+				Description: dbPortDescription,
+				Computed:    true,
+			},
+			"type": schema.StringAttribute{
+				Description: fmt.Sprintf(enumDescription, dbTypeDescription, dbTypes),
+				Computed:    true,
+			},
+			"tags": schema.ListAttribute{
+				Description: tagsDescription,
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"username": schema.StringAttribute{
+				Description: usernameDescription,
+				Computed:    true,
+			},
+			"password": schema.StringAttribute{
+				Description: passwordDescription,
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"note_value": schema.StringAttribute{
+				Description: noteValueDescription,
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"section": schema.ListNestedAttribute{
+				Description: sectionsDescription,
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Description: sectionIDDescription,
+							Computed:    true,
+						},
+						"label": schema.StringAttribute{
+							Description: sectionLabelDescription,
+							Computed:    true,
+						},
+						"field": schema.ListNestedAttribute{
+							Description: sectionFieldsDescription,
+							Computed:    true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"id": schema.StringAttribute{
+										Description: fieldIDDescription,
+										Computed:    true,
+									},
+									"label": schema.StringAttribute{
+										Description: fieldLabelDescription,
+										Computed:    true,
+									},
+									"purpose": schema.StringAttribute{
+										Description: fmt.Sprintf(enumDescription, fieldPurposeDescription, fieldPurposes),
+										Computed:    true,
+									},
+									"type": schema.StringAttribute{
+										Description: fmt.Sprintf(enumDescription, fieldTypeDescription, fieldTypes),
+										Computed:    true,
+									},
+									"value": schema.StringAttribute{
+										Description: fieldValueDescription,
+										Computed:    true,
+										Sensitive:   true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *itemEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Not configured yet (e.g: validation).
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.p = req.ProviderData.(ProviderConfig)
+}
+
+func (r *itemEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var config Item
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Not known yet, it will be validated when known.
+	if config.UUID.IsUnknown() || config.Title.IsUnknown() {
+		return
+	}
+
+	if config.UUID.IsNull() == config.Title.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("uuid"), "Invalid item", "Exactly one of `uuid` or `title` must be set.")
+	}
+}
+
+func (r *itemEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
+	}
+
+	var config Item
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vaultID := config.VaultID.ValueString()
+	var item *model.Item
+	var err error
+	if !config.UUID.IsNull() {
+		item, err = r.p.repo.GetItemByID(ctx, config.UUID.ValueString())
+		if err == nil && item.Vault.ID != vaultID {
+			err = fmt.Errorf("item %q is not in vault %q", item.ID, vaultID)
+		}
+	} else {
+		item, err = r.p.repo.GetItemByTitle(ctx, vaultID, config.Title.ValueString())
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading item", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, itemToEphemeralData(*item))...)
+}
+
+func itemToEphemeralData(item model.Item) Item {
+	data := Item{
+		ID:        types.StringValue(terraformID(item)),
+		VaultID:   types.StringValue(item.Vault.ID),
+		UUID:      types.StringValue(item.ID),
+		Title:     types.StringValue(item.Title),
+		Category:  types.StringValue(strings.ToLower(item.Category)),
+		URL:       types.StringNull(),
+		Hostname:  types.StringNull(),
+		Database:  types.StringNull(),
+		Port:      types.StringNull(),
+		Type:      types.StringNull(),
+		Tags:      []types.String{},
+		Username:  types.StringNull(),
+		Password:  types.StringNull(),
+		NoteValue: types.StringNull(),
+		Section:   []Section{},
+	}
+
+	for _, u := range item.URLs {
+		if u.Primary {
+			data.URL = types.StringValue(u.URL)
+		}
+	}
+
+	for _, t := range item.Tags {
+		data.Tags = append(data.Tags, types.StringValue(t))
+	}
+
+	for _, s := range item.Sections {
+		section := Section{
+			ID:    types.StringValue(s.ID),
+			Label: types.StringValue(s.Label),
+			Field: []Field{},
+		}
+
+		for _, f := range item.Fields {
+			if f.Section != nil && f.Section.ID == s.ID {
+				section.Field = append(section.Field, Field{
+					ID:      types.StringValue(f.ID),
+					Label:   types.StringValue(f.Label),
+					Purpose: types.StringValue(f.Purpose),
+					Type:    types.StringValue(f.Type),
+					Value:   types.StringValue(f.Value),
+				})
+			}
+		}
+
+		data.Section = append(data.Section, section)
+	}
+
+	for _, f := range item.Fields {
+		switch f.Purpose {
+		case "USERNAME":
+			data.Username = types.StringValue(f.Value)
+		case "PASSWORD":
+			data.Password = types.StringValue(f.Value)
+		case "NOTES":
+			data.NoteValue = types.StringValue(f.Value)
+		default:
+			if f.Section != nil {
+				continue
+			}
+
+			// The database items fields are not on a section.
+			switch strings.ToLower(f.Label) {
+			case "hostname":
+				data.Hostname = types.StringValue(f.Value)
+			case "database":
+				data.Database = types.StringValue(f.Value)
+			case "port":
+				data.Port = types.StringValue(f.Value)
+			case "type":
+				data.Type = types.StringValue(f.Value)
+			}
+		}
+	}
+
+	return data
+}
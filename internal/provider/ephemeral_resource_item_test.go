@@ -0,0 +1,176 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+)
+
+func TestEphemeralItemOpen(t *testing.T) {
+	tests := map[string]struct {
+		config func(vaultID, itemID string) map[string]tftypes.Value
+		expErr bool
+	}{
+		"Getting the item by title should return the item.": {
+			config: func(vaultID, itemID string) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"vault": tftypes.NewValue(tftypes.String, vaultID),
+					"title": tftypes.NewValue(tftypes.String, "test-item"),
+				}
+			},
+		},
+
+		"Getting the item by UUID should return the item.": {
+			config: func(vaultID, itemID string) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"vault": tftypes.NewValue(tftypes.String, vaultID),
+					"uuid":  tftypes.NewValue(tftypes.String, itemID),
+				}
+			},
+		},
+
+		"Getting the item by UUID on a different vault should fail.": {
+			config: func(vaultID, itemID string) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"vault": tftypes.NewValue(tftypes.String, "other-vault-id"),
+					"uuid":  tftypes.NewValue(tftypes.String, itemID),
+				}
+			},
+			expErr: true,
+		},
+
+		"Getting a missing item should fail.": {
+			config: func(vaultID, itemID string) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"vault": tftypes.NewValue(tftypes.String, vaultID),
+					"title": tftypes.NewValue(tftypes.String, "missing-item"),
+				}
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+			ctx := context.TODO()
+
+			server, repo := newConfiguredProviderServer(t)
+			vault, err := repo.CreateVault(ctx, model.Vault{Name: "test-vault"})
+			require.NoError(err)
+			section := model.Section{ID: "s1", Label: "Section"}
+			item, err := repo.CreateItem(ctx, model.Item{
+				Vault:    *vault,
+				Title:    "test-item",
+				Category: "login",
+				Tags:     []string{"tag"},
+				Sections: []model.Section{section},
+				Fields: []model.Field{
+					{ID: "username", Type: "STRING", Purpose: "USERNAME", Label: "username", Value: "user"},
+					{ID: "password", Type: "CONCEALED", Purpose: "PASSWORD", Label: "password", Value: "item-secret"},
+					{ID: "f1", Type: "CONCEALED", Label: "token", Value: "section-secret", Section: &section},
+				},
+			})
+			require.NoError(err)
+
+			schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+			require.NoError(err)
+			typ := schemaResp.EphemeralResourceSchemas["onepasswordorg_item"].ValueType()
+
+			eserver, ok := server.(tfprotov6.EphemeralResourceServer)
+			require.True(ok)
+			resp, err := eserver.OpenEphemeralResource(ctx, &tfprotov6.OpenEphemeralResourceRequest{
+				TypeName: "onepasswordorg_item",
+				Config:   newDynamicValue(t, typ, test.config(vault.ID, item.ID)),
+			})
+			require.NoError(err)
+
+			if test.expErr {
+				assert.NotEmpty(resp.Diagnostics)
+				return
+			}
+			require.Empty(resp.Diagnostics)
+
+			result, err := resp.Result.Unmarshal(typ)
+			require.NoError(err)
+			assert.Equal(tftypes.NewValue(tftypes.String, "vaults/"+vault.ID+"/items/"+item.ID), getStateAttribute(t, result, "id"))
+			assert.Equal(tftypes.NewValue(tftypes.String, item.ID), getStateAttribute(t, result, "uuid"))
+			assert.Equal(tftypes.NewValue(tftypes.String, "test-item"), getStateAttribute(t, result, "title"))
+			assert.Equal(tftypes.NewValue(tftypes.String, "login"), getStateAttribute(t, result, "category"))
+			assert.Equal(tftypes.NewValue(tftypes.String, "user"), getStateAttribute(t, result, "username"))
+			assert.Equal(tftypes.NewValue(tftypes.String, "item-secret"), getStateAttribute(t, result, "password"))
+
+			var sections []tftypes.Value
+			require.NoError(getStateAttribute(t, result, "section").As(&sections))
+			require.Len(sections, 1)
+			assert.Equal(tftypes.NewValue(tftypes.String, "Section"), getStateAttribute(t, sections[0], "label"))
+			var fields []tftypes.Value
+			require.NoError(getStateAttribute(t, sections[0], "field").As(&fields))
+			require.Len(fields, 1)
+			assert.Equal(tftypes.NewValue(tftypes.String, "section-secret"), getStateAttribute(t, fields[0], "value"))
+		})
+	}
+}
+
+func TestEphemeralItemValidate(t *testing.T) {
+	tests := map[string]struct {
+		config map[string]tftypes.Value
+		expErr bool
+	}{
+		"The item title should be valid.": {
+			config: map[string]tftypes.Value{
+				"vault": tftypes.NewValue(tftypes.String, "test-vault-id"),
+				"title": tftypes.NewValue(tftypes.String, "test-item"),
+			},
+		},
+
+		"Without the item title nor the UUID should fail.": {
+			config: map[string]tftypes.Value{
+				"vault": tftypes.NewValue(tftypes.String, "test-vault-id"),
+			},
+			expErr: true,
+		},
+
+		"The item title and the UUID should fail.": {
+			config: map[string]tftypes.Value{
+				"vault": tftypes.NewValue(tftypes.String, "test-vault-id"),
+				"title": tftypes.NewValue(tftypes.String, "test-item"),
+				"uuid":  tftypes.NewValue(tftypes.String, "test-item-id"),
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.TODO()
+
+			server, _ := newConfiguredProviderServer(t)
+			schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+			require.NoError(err)
+			typ := schemaResp.EphemeralResourceSchemas["onepasswordorg_item"].ValueType()
+
+			eserver, ok := server.(tfprotov6.EphemeralResourceServer)
+			require.True(ok)
+			resp, err := eserver.ValidateEphemeralResourceConfig(ctx, &tfprotov6.ValidateEphemeralResourceConfigRequest{
+				TypeName: "onepasswordorg_item",
+				Config:   newDynamicValue(t, typ, test.config),
+			})
+			require.NoError(err)
+
+			if test.expErr {
+				assert.NotEmpty(t, resp.Diagnostics)
+			} else {
+				assert.Empty(t, resp.Diagnostics)
+			}
+		})
+	}
+}
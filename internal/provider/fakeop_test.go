@@ -0,0 +1,238 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/provider"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/fake"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclitest"
+)
+
+// TestAccFakeOpCli will check the provider manages the resources end to end using the op cli
+// (the fake op one), instead of the fake storage repository.
+func TestAccFakeOpCli(t *testing.T) {
+	// Use the op cli instead of the fake repository.
+	t.Setenv(provider.EnvVarOpFakeStoragePath, "")
+	t.Setenv("TFC_RUN_ID", "")
+	storagePath := filepath.Join(t.TempDir(), "storage.json")
+	t.Setenv(onepasswordclitest.EnvVarFakeOpStoragePath, storagePath)
+	binPath := onepasswordclitest.BuildFakeOp(t)
+	cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+	t.Setenv(provider.EnvVarOpCliRecordPath, cassettePath)
+
+	config := func(description string) string {
+		// The terraform block stops the test framework from adding an empty provider block.
+		return fmt.Sprintf(`
+terraform {}
+
+provider "onepasswordorg" {
+  address     = "test.1password.com"
+  email       = "test@example.com"
+  secret_key  = "test-secret-key"
+  password    = "test-password"
+  op_cli_path = %q
+}
+
+resource "onepasswordorg_user" "test" {
+  email = "user@example.com"
+  name  = "User"
+}
+
+resource "onepasswordorg_group" "test" {
+  name        = "test-group"
+  description = %q
+}
+
+resource "onepasswordorg_group_member" "test" {
+  group_id = onepasswordorg_group.test.id
+  user_id  = onepasswordorg_user.test.id
+  role     = "manager"
+}
+
+resource "onepasswordorg_vault" "test" {
+  name = "test-vault"
+}
+
+resource "onepasswordorg_vault_group_access" "test" {
+  vault_id = onepasswordorg_vault.test.id
+  group_id = onepasswordorg_group.test.id
+  permissions {
+    allow_viewing = true
+    allow_editing = true
+  }
+}
+
+resource "onepasswordorg_item" "test" {
+  vault    = onepasswordorg_vault.test.id
+  title    = "test-item"
+  category = "login"
+  username = "user"
+  password = "item-secret"
+}
+`, binPath, description)
+	}
+
+	assertOnFakeOpStorage := func(s *terraform.State) error {
+		repo, err := fake.NewRepository(storagePath)
+		if err != nil {
+			return err
+		}
+		ctx := context.TODO()
+
+		user, err := repo.GetUserByEmail(ctx, "user@example.com")
+		assert.NoError(t, err)
+		group, err := repo.GetGroupByName(ctx, "test-group")
+		assert.NoError(t, err)
+		vault, err := repo.GetVaultByName(ctx, "test-vault")
+		assert.NoError(t, err)
+		if t.Failed() {
+			return fmt.Errorf("objects missing on fake op storage")
+		}
+
+		m, err := repo.GetMembershipByID(ctx, group.ID, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, &model.Membership{GroupID: group.ID, UserID: user.ID, Role: model.MembershipRoleManager}, m)
+
+		a, err := repo.GetVaultGroupAccessByID(ctx, vault.ID, group.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, model.AccessPermissions{AllowViewing: true, AllowEditing: true}, a.Permissions)
+
+		_, err = repo.GetItemByTitle(ctx, vault.ID, "test-item")
+		assert.NoError(t, err)
+
+		return nil
+	}
+
+	// The op commands are recorded without secrets.
+	assertCassetteScrubbed := func(s *terraform.State) error {
+		data, err := os.ReadFile(cassettePath)
+		if err != nil {
+			return err
+		}
+		assert.Contains(t, string(data), "test-group")
+		assert.NotContains(t, string(data), "item-secret")
+		assert.NotContains(t, string(data), "test-password")
+		return nil
+	}
+
+	// Execute test.
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("Test group"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					assertOnFakeOpStorage,
+					resource.TestCheckResourceAttr("onepasswordorg_item.test", "password", "item-secret"),
+				),
+			},
+			{
+				Config: config("Test group modified"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					assertOnFakeOpStorage,
+					resource.TestCheckResourceAttr("onepasswordorg_group.test", "description", "Test group modified"),
+					assertCassetteScrubbed,
+				),
+			},
+		},
+	})
+}
+
+// TestAccFakeOpCliRetriesRateLimits will check the op commands that fail with rate limit errors are
+// retried until they succeed, half of the fake op commands are rate limited (with a fixed seed so the
+// faults are the same on every run), without retrying them the apply would fail.
+func TestAccFakeOpCliRetriesRateLimits(t *testing.T) {
+	// Use the op cli instead of the fake repository.
+	t.Setenv(provider.EnvVarOpFakeStoragePath, "")
+	t.Setenv("TFC_RUN_ID", "")
+	storagePath := filepath.Join(t.TempDir(), "storage.json")
+	t.Setenv(onepasswordclitest.EnvVarFakeOpStoragePath, storagePath)
+	binPath := onepasswordclitest.BuildFakeOp(t)
+	t.Setenv(fake.EnvVarOpFakeRateLimitRate, "0.5")
+	t.Setenv(fake.EnvVarOpFakeSeed, "42")
+
+	// The terraform block stops the test framework from adding an empty provider block.
+	config := fmt.Sprintf(`
+terraform {}
+
+provider "onepasswordorg" {
+  address        = "test.1password.com"
+  email          = "test@example.com"
+  secret_key     = "test-secret-key"
+  password       = "test-password"
+  op_cli_path    = %q
+  max_retries    = 30
+  retry_max_wait = "20ms"
+}
+
+resource "onepasswordorg_group" "test" {
+  name = "test-group"
+}
+
+resource "onepasswordorg_vault" "test" {
+  name = "test-vault"
+}
+
+resource "onepasswordorg_vault_group_access" "test" {
+  vault_id = onepasswordorg_vault.test.id
+  group_id = onepasswordorg_group.test.id
+  permissions {
+    allow_viewing = true
+  }
+}
+
+resource "onepasswordorg_item" "test" {
+  vault    = onepasswordorg_vault.test.id
+  title    = "test-item"
+  category = "login"
+  password = "item-secret"
+}
+`, binPath)
+
+	assertOnFakeOpStorage := func(s *terraform.State) error {
+		// Read the storage without the faults.
+		t.Setenv(fake.EnvVarOpFakeRateLimitRate, "0")
+		defer t.Setenv(fake.EnvVarOpFakeRateLimitRate, "0.5")
+
+		repo, err := fake.NewRepository(storagePath)
+		if err != nil {
+			return err
+		}
+		ctx := context.TODO()
+
+		group, err := repo.GetGroupByName(ctx, "test-group")
+		assert.NoError(t, err)
+		vault, err := repo.GetVaultByName(ctx, "test-vault")
+		assert.NoError(t, err)
+		if t.Failed() {
+			return fmt.Errorf("objects missing on fake op storage")
+		}
+
+		_, err = repo.GetVaultGroupAccessByID(ctx, vault.ID, group.ID)
+		assert.NoError(t, err)
+		_, err = repo.GetItemByTitle(ctx, vault.ID, "test-item")
+		assert.NoError(t, err)
+
+		return nil
+	}
+
+	// Execute test.
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  assertOnFakeOpStorage,
+			},
+		},
+	})
+}
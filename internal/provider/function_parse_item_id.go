@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type parseItemIDFunction struct{}
+
+var _ function.Function = &parseItemIDFunction{}
+
+func newParseItemIDFunction() function.Function {
+	return &parseItemIDFunction{}
+}
+
+// parsedItemID is the result of the parse item ID function.
+type parsedItemID struct {
+	VaultID types.String `tfsdk:"vault_id"`
+	ItemID  types.String `tfsdk:"item_id"`
+}
+
+func (f *parseItemIDFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_item_id"
+}
+
+func (f *parseItemIDFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Parses an item ID.",
+		Description: "Parses an item Terraform ID in the format `vaults/<vault_id>/items/<item_id>` into an object with the `vault_id` and `item_id` attributes.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "id",
+				Description: "The Terraform ID of the item.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"vault_id": types.StringType,
+				"item_id":  types.StringType,
+			},
+		},
+	}
+}
+
+func (f *parseItemIDFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var id string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &id))
+	if resp.Error != nil {
+		return
+	}
+
+	vaultID, itemID, err := unpackItemID(id)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, parsedItemID{
+		VaultID: types.StringValue(vaultID),
+		ItemID:  types.StringValue(itemID),
+	})
+}
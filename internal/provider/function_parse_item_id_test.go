@@ -0,0 +1,72 @@
+package provider_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseItemIDFunction(t *testing.T) {
+	tests := map[string]struct {
+		id         string
+		expVaultID string
+		expItemID  string
+		expErr     bool
+	}{
+		"A valid item ID should return the vault and item IDs.": {
+			id:         "vaults/test-vault-id/items/test-item-id",
+			expVaultID: "test-vault-id",
+			expItemID:  "test-item-id",
+		},
+
+		"An empty ID should fail.": {
+			id:     "",
+			expErr: true,
+		},
+
+		"An ID without the item should fail.": {
+			id:     "vaults/test-vault-id/items/",
+			expErr: true,
+		},
+
+		"An ID with a different format should fail.": {
+			id:     "test-vault-id/test-item-id",
+			expErr: true,
+		},
+
+		"An ID with wrong prefixes should fail.": {
+			id:     "vault/test-vault-id/item/test-item-id",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			resp := callFunction(t, "parse_item_id", tftypes.NewValue(tftypes.String, test.id))
+
+			if test.expErr {
+				require.NotNil(resp.Error)
+				assert.Equal(ptr[int64](0), resp.Error.FunctionArgument)
+				return
+			}
+			require.Nil(resp.Error)
+
+			typ := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+				"vault_id": tftypes.String,
+				"item_id":  tftypes.String,
+			}}
+			got, err := resp.Result.Unmarshal(typ)
+			require.NoError(err)
+			exp := tftypes.NewValue(typ, map[string]tftypes.Value{
+				"vault_id": tftypes.NewValue(tftypes.String, test.expVaultID),
+				"item_id":  tftypes.NewValue(tftypes.String, test.expItemID),
+			})
+			assert.Equal(exp, got)
+		})
+	}
+}
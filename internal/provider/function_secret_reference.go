@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type secretReferenceFunction struct{}
+
+var _ function.Function = &secretReferenceFunction{}
+
+func newSecretReferenceFunction() function.Function {
+	return &secretReferenceFunction{}
+}
+
+func (f *secretReferenceFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "secret_reference"
+}
+
+func (f *secretReferenceFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Returns the secret reference of an item field.",
+		Description: "Returns the secret reference of an item field in the format `op://<vault>/<item>/[<section>/]<field>`. The names can only have alphanumeric characters, `-`, `_`, `.` and whitespaces, use the IDs otherwise.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "vault",
+				Description: "The name or ID of the vault.",
+			},
+			function.StringParameter{
+				Name:        "item",
+				Description: "The name or ID of the item.",
+			},
+			function.StringParameter{
+				Name:        "field",
+				Description: "The name or ID of the field.",
+			},
+			function.StringParameter{
+				Name:           "section",
+				Description:    "The name or ID of the section, `null` or empty if the field is not on a section.",
+				AllowNullValue: true,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *secretReferenceFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var vault, item, field string
+	var section types.String
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &vault, &item, &field, &section))
+	if resp.Error != nil {
+		return
+	}
+
+	names := []string{vault, item, field}
+	if section.ValueString() != "" {
+		names = append(names, section.ValueString())
+	}
+	for i, name := range names {
+		if err := validateSecretReferenceName(name); err != nil {
+			resp.Error = function.NewArgumentFuncError(int64(i), err.Error())
+			return
+		}
+	}
+
+	resp.Error = resp.Result.Set(ctx, packSecretReference(vault, item, section.ValueString(), field))
+}
@@ -0,0 +1,99 @@
+package provider_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretReferenceFunction(t *testing.T) {
+	tests := map[string]struct {
+		vault, item, field string
+		section            *string
+		expRef             string
+		expErrArg          *int64
+	}{
+		"A field without section should return the reference without section.": {
+			vault:  "test-vault",
+			item:   "test-item",
+			field:  "password",
+			expRef: "op://test-vault/test-item/password",
+		},
+
+		"A field with an empty section should return the reference without section.": {
+			vault:   "test-vault",
+			item:    "test-item",
+			field:   "password",
+			section: ptr(""),
+			expRef:  "op://test-vault/test-item/password",
+		},
+
+		"A field with section should return the reference with the section.": {
+			vault:   "test-vault",
+			item:    "Test item_1.0",
+			field:   "api token",
+			section: ptr("prod-section"),
+			expRef:  "op://test-vault/Test item_1.0/prod-section/api token",
+		},
+
+		"An empty vault should fail.": {
+			vault:     "",
+			item:      "test-item",
+			field:     "password",
+			expErrArg: ptr[int64](0),
+		},
+
+		"An item with unsupported characters should fail.": {
+			vault:     "test-vault",
+			item:      "test/item",
+			field:     "password",
+			expErrArg: ptr[int64](1),
+		},
+
+		"A field with unsupported characters should fail.": {
+			vault:     "test-vault",
+			item:      "test-item",
+			field:     "pass:word",
+			expErrArg: ptr[int64](2),
+		},
+
+		"A section with unsupported characters should fail.": {
+			vault:     "test-vault",
+			item:      "test-item",
+			field:     "password",
+			section:   ptr("sec@tion"),
+			expErrArg: ptr[int64](3),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var section interface{}
+			if test.section != nil {
+				section = *test.section
+			}
+			resp := callFunction(t, "secret_reference",
+				tftypes.NewValue(tftypes.String, test.vault),
+				tftypes.NewValue(tftypes.String, test.item),
+				tftypes.NewValue(tftypes.String, test.field),
+				tftypes.NewValue(tftypes.String, section),
+			)
+
+			if test.expErrArg != nil {
+				require.NotNil(resp.Error)
+				assert.Equal(test.expErrArg, resp.Error.FunctionArgument)
+				return
+			}
+			require.Nil(resp.Error)
+
+			got, err := resp.Result.Unmarshal(tftypes.String)
+			require.NoError(err)
+			assert.Equal(tftypes.NewValue(tftypes.String, test.expRef), got)
+		})
+	}
+}
@@ -3,13 +3,21 @@ package provider_test
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"regexp"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/provider"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/fake"
 )
 
 // getFakeRepoTmpFile returns a temp file that can be used for the fake repository storage.
@@ -20,54 +28,121 @@ func getFakeRepoTmpFile(prefix string) (path string, delete func()) {
 	if err != nil {
 		panic(err)
 	}
-	return f.Name(), func() { _ = os.Remove(f.Name()) }
+	return f.Name(), func() {
+		_ = os.Remove(f.Name())
+		_ = os.Remove(f.Name() + ".lock")
+	}
+}
+
+// fakeIDRegexp matches the 1password like IDs generated by the fake repository.
+var fakeIDRegexp = regexp.MustCompile(`^[a-z0-9]{26}$`)
+
+// getFakeIDFromState returns the ID of a resource on the Terraform state, checking it's a fake
+// repository generated ID.
+func getFakeIDFromState(t *testing.T, s *terraform.State, resourceName string) string {
+	rs, ok := s.RootModule().Resources[resourceName]
+	if !ok {
+		t.Fatalf("resource %q not found on state", resourceName)
+	}
+
+	id := rs.Primary.ID
+	assert.Regexp(t, fakeIDRegexp, id)
+
+	return id
 }
 
-func assertUserOnFakeStorage(t *testing.T, expUser *model.User) resource.TestCheckFunc {
+// upgradeResourceState upgrades the raw JSON state of a resource from a schema version as Terraform
+// does, and returns the upgraded state.
+func upgradeResourceState(t *testing.T, typeName string, version int64, rawState string) tftypes.Value {
+	ctx := context.TODO()
+	server, err := provider.ProviderServer(ctx)
+	require.NoError(t, err)
+	s := server()
+
+	schemaResp, err := s.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	resp, err := s.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: typeName,
+		Version:  version,
+		RawState: &tfprotov6.RawState{JSON: []byte(rawState)},
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Diagnostics)
+
+	state, err := resp.UpgradedState.Unmarshal(schemaResp.ResourceSchemas[typeName].ValueType())
+	require.NoError(t, err)
+
+	return state
+}
+
+// getStateAttribute returns the value of a nested attribute of a state.
+func getStateAttribute(t *testing.T, state tftypes.Value, names ...string) tftypes.Value {
+	p := tftypes.NewAttributePath()
+	for _, name := range names {
+		p = p.WithAttributeName(name)
+	}
+
+	v, _, err := tftypes.WalkAttributePath(state, p)
+	require.NoError(t, err)
+
+	return v.(tftypes.Value)
+}
+
+func assertUserOnFakeStorage(t *testing.T, resourceName string, expUser *model.User) resource.TestCheckFunc {
 	assert := assert.New(t)
 
 	return resource.TestCheckFunc(func(s *terraform.State) error {
 		repo := getFakeRepository(t)
 
-		gotUser, err := repo.GetUserByID(context.TODO(), expUser.ID)
+		// The IDs are generated by the fake, get them from the state.
+		id := getFakeIDFromState(t, s, resourceName)
+		exp := *expUser
+		exp.ID = id
+
+		gotUser, err := repo.GetUserByID(context.TODO(), id)
 		assert.NoError(err)
-		assert.Equal(expUser, gotUser)
+		assert.Equal(&exp, gotUser)
 		return nil
 	})
 }
 
-func assertUserDeletedOnFakeStorage(t *testing.T, userID string) resource.TestCheckFunc {
+func assertUserDeletedOnFakeStorage(t *testing.T, email string) resource.TestCheckFunc {
 	assert := assert.New(t)
 
 	return resource.TestCheckFunc(func(s *terraform.State) error {
 		repo := getFakeRepository(t)
 
-		_, err := repo.GetUserByID(context.TODO(), userID)
+		_, err := repo.GetUserByEmail(context.TODO(), email)
 		assert.Error(err)
 		return nil
 	})
 }
 
-func assertGroupOnFakeStorage(t *testing.T, expGroup *model.Group) resource.TestCheckFunc {
+func assertGroupOnFakeStorage(t *testing.T, resourceName string, expGroup *model.Group) resource.TestCheckFunc {
 	assert := assert.New(t)
 
 	return resource.TestCheckFunc(func(s *terraform.State) error {
 		repo := getFakeRepository(t)
 
-		gotGroup, err := repo.GetGroupByID(context.TODO(), expGroup.ID)
+		// The IDs are generated by the fake, get them from the state.
+		id := getFakeIDFromState(t, s, resourceName)
+		exp := *expGroup
+		exp.ID = id
+
+		gotGroup, err := repo.GetGroupByID(context.TODO(), id)
 		assert.NoError(err)
-		assert.Equal(expGroup, gotGroup)
+		assert.Equal(&exp, gotGroup)
 		return nil
 	})
 }
 
-func assertGroupDeletedOnFakeStorage(t *testing.T, groupID string) resource.TestCheckFunc {
+func assertGroupDeletedOnFakeStorage(t *testing.T, name string) resource.TestCheckFunc {
 	assert := assert.New(t)
 
 	return resource.TestCheckFunc(func(s *terraform.State) error {
 		repo := getFakeRepository(t)
 
-		_, err := repo.GetGroupByID(context.TODO(), groupID)
+		_, err := repo.GetGroupByName(context.TODO(), name)
 		assert.Error(err)
 		return nil
 	})
@@ -98,26 +173,31 @@ func assertGroupMemberDeletedOnFakeStorage(t *testing.T, groupID, userID string)
 	})
 }
 
-func assertVaultOnFakeStorage(t *testing.T, expVault *model.Vault) resource.TestCheckFunc {
+func assertVaultOnFakeStorage(t *testing.T, resourceName string, expVault *model.Vault) resource.TestCheckFunc {
 	assert := assert.New(t)
 
 	return resource.TestCheckFunc(func(s *terraform.State) error {
 		repo := getFakeRepository(t)
 
-		gotVault, err := repo.GetVaultByID(context.TODO(), expVault.ID)
+		// The IDs are generated by the fake, get them from the state.
+		id := getFakeIDFromState(t, s, resourceName)
+		exp := *expVault
+		exp.ID = id
+
+		gotVault, err := repo.GetVaultByID(context.TODO(), id)
 		assert.NoError(err)
-		assert.Equal(expVault, gotVault)
+		assert.Equal(&exp, gotVault)
 		return nil
 	})
 }
 
-func assertVaultDeletedOnFakeStorage(t *testing.T, vaultID string) resource.TestCheckFunc {
+func assertVaultDeletedOnFakeStorage(t *testing.T, name string) resource.TestCheckFunc {
 	assert := assert.New(t)
 
 	return resource.TestCheckFunc(func(s *terraform.State) error {
 		repo := getFakeRepository(t)
 
-		_, err := repo.GetVaultByID(context.TODO(), vaultID)
+		_, err := repo.GetVaultByName(context.TODO(), name)
 		assert.Error(err)
 		return nil
 	})
@@ -172,3 +252,71 @@ func assertVaultUserAccessDeletedOnFakeStorage(t *testing.T, vaultID, userID str
 		return nil
 	})
 }
+
+// newConfiguredProviderServer returns a provider server configured to use a new fake storage, and the
+// fake storage repository.
+func newConfiguredProviderServer(t *testing.T) (tfprotov6.ProviderServer, storage.Repository) {
+	ctx := context.TODO()
+	unsetProviderEnv(t)
+	fakePath := filepath.Join(t.TempDir(), "storage.json")
+	t.Setenv(provider.EnvVarOpFakeStoragePath, fakePath)
+
+	server, err := provider.ProviderServer(ctx)
+	require.NoError(t, err)
+	s := server()
+
+	schemaResp, err := s.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	resp, err := s.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{
+		Config: newDynamicValue(t, schemaResp.Provider.ValueType(), nil),
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Diagnostics)
+
+	repo, err := fake.NewRepository(fakePath)
+	require.NoError(t, err)
+
+	return s, repo
+}
+
+// newDynamicValue returns the value of an object type with the attributes, the missing attributes are null.
+func newDynamicValue(t *testing.T, typ tftypes.Type, attrs map[string]tftypes.Value) *tfprotov6.DynamicValue {
+	objType := typ.(tftypes.Object)
+	values := map[string]tftypes.Value{}
+	for name, attrType := range objType.AttributeTypes {
+		v, ok := attrs[name]
+		if !ok {
+			v = tftypes.NewValue(attrType, nil)
+		}
+		values[name] = v
+	}
+
+	dv, err := tfprotov6.NewDynamicValue(typ, tftypes.NewValue(typ, values))
+	require.NoError(t, err)
+
+	return &dv
+}
+
+// callFunction calls a provider function with the arguments.
+func callFunction(t *testing.T, name string, args ...tftypes.Value) *tfprotov6.CallFunctionResponse {
+	ctx := context.TODO()
+	server, err := provider.ProviderServer(ctx)
+	require.NoError(t, err)
+
+	dvArgs := []*tfprotov6.DynamicValue{}
+	for _, arg := range args {
+		dv, err := tfprotov6.NewDynamicValue(arg.Type(), arg)
+		require.NoError(t, err)
+		dvArgs = append(dvArgs, &dv)
+	}
+
+	resp, err := server().CallFunction(ctx, &tfprotov6.CallFunctionRequest{
+		Name:      name,
+		Arguments: dvArgs,
+	})
+	require.NoError(t, err)
+
+	return resp
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// packItemID returns the Terraform ID of an item.
+func packItemID(vaultID, itemID string) string {
+	return "vaults/" + vaultID + "/items/" + itemID
+}
+
+func unpackItemID(id string) (vaultID, itemID string, err error) {
+	s := strings.Split(id, "/")
+	if len(s) != 4 || s[0] != "vaults" || s[2] != "items" || s[1] == "" || s[3] == "" {
+		return "", "", fmt.Errorf(
+			"invalid item ID format: %s (expected vaults/<VAULT ID>/items/<ITEM ID>)", id)
+	}
+
+	return s[1], s[3], nil
+}
+
+// secretReferenceNameRegexp matches the names that can be used on a secret reference, names with other
+// characters need to be referenced by their ID.
+// More info in [1password docs](https://developer.1password.com/docs/cli/secret-reference-syntax/).
+var secretReferenceNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9\-_. ]+$`)
+
+func validateSecretReferenceName(name string) error {
+	if !secretReferenceNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid secret reference name %q, only alphanumeric characters, '-', '_', '.' and whitespaces are supported (use the ID instead)", name)
+	}
+
+	return nil
+}
+
+// packSecretReference returns the secret reference of an item field, the section is optional.
+func packSecretReference(vault, item, section, field string) string {
+	if section == "" {
+		return "op://" + vault + "/" + item + "/" + field
+	}
+
+	return "op://" + vault + "/" + item + "/" + section + "/" + field
+}
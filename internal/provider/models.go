@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -11,14 +12,26 @@ type User struct {
 }
 
 type Item struct {
-	ID      types.String `tfsdk:"id"`
-	VaultID types.String `tfsdk:"vault"`
-	Title   types.String `tfsdk:"title"`
-	Section []Section    `tfsdk:"section"`
+	ID        types.String   `tfsdk:"id"`
+	VaultID   types.String   `tfsdk:"vault"`
+	UUID      types.String   `tfsdk:"uuid"`
+	Title     types.String   `tfsdk:"title"`
+	Category  types.String   `tfsdk:"category"`
+	URL       types.String   `tfsdk:"url"`
+	Hostname  types.String   `tfsdk:"hostname"`
+	Database  types.String   `tfsdk:"database"`
+	Port      types.String   `tfsdk:"port"`
+	Type      types.String   `tfsdk:"type"`
+	Tags      []types.String `tfsdk:"tags"`
+	Username  types.String   `tfsdk:"username"`
+	Password  types.String   `tfsdk:"password"`
+	NoteValue types.String   `tfsdk:"note_value"`
+	Section   []Section      `tfsdk:"section"`
 }
 
 type Section struct {
 	ID    types.String `tfsdk:"id"`
+	Label types.String `tfsdk:"label"`
 	Field []Field      `tfsdk:"field"`
 }
 
@@ -50,17 +63,19 @@ type Member struct {
 }
 
 type VaultGroupAccess struct {
-	ID          types.String       `tfsdk:"id"`
-	VaultID     types.String       `tfsdk:"vault_id"`
-	GroupID     types.String       `tfsdk:"group_id"`
-	Permissions *AccessPermissions `tfsdk:"permissions"`
+	ID          types.String        `tfsdk:"id"`
+	VaultID     types.String        `tfsdk:"vault_id"`
+	GroupID     types.String        `tfsdk:"group_id"`
+	Permissions []AccessPermissions `tfsdk:"permissions"`
+	Timeouts    timeouts.Value      `tfsdk:"timeouts"`
 }
 
 type VaultUserAccess struct {
-	ID          types.String       `tfsdk:"id"`
-	VaultID     types.String       `tfsdk:"vault_id"`
-	UserID      types.String       `tfsdk:"user_id"`
-	Permissions *AccessPermissions `tfsdk:"permissions"`
+	ID          types.String        `tfsdk:"id"`
+	VaultID     types.String        `tfsdk:"vault_id"`
+	UserID      types.String        `tfsdk:"user_id"`
+	Permissions []AccessPermissions `tfsdk:"permissions"`
+	Timeouts    timeouts.Value      `tfsdk:"timeouts"`
 }
 
 type AccessPermissions struct {
@@ -2,14 +2,24 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/cache"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/connect"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/fake"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/instrumented"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/scim"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/telemetry"
 )
 
 const (
@@ -18,8 +28,20 @@ const (
 	envVarOpSecretKey       = "OP_SECRET_KEY"
 	envVarOpPassword        = "OP_PASSWORD"
 	envVarOpShorthand       = "OP_SHORTHAND"
+	envVarOpServiceAccount  = "OP_SERVICE_ACCOUNT_TOKEN"
+	envVarOpConnectHost     = "OP_CONNECT_HOST"
+	envVarOpConnectToken    = "OP_CONNECT_TOKEN"
+	envVarOpSCIMBridgeURL   = "OP_SCIM_BRIDGE_URL"
+	envVarOpSCIMToken       = "OP_SCIM_TOKEN"
 	EnvVarOpFakeStoragePath = "OP_FAKE_STORAGE_PATH"
 	EnvVarOpCliPath         = "OP_CLI_PATH"
+	EnvVarOpCliRecordPath   = "OP_CLI_RECORD_PATH"
+)
+
+// Error summaries.
+const (
+	configErrSummary = "Unable to configure client:"
+	createErrSummary = "Unable to create op client:"
 )
 
 type ProviderConfig struct {
@@ -29,13 +51,23 @@ type ProviderConfig struct {
 
 // Provider configuration.
 type providerData struct {
-	Address         string
-	Email           string
-	SecretKey       string
-	Password        string
-	Shorthand       string
-	FakeStoragePath string
-	CliPath         string
+	Address             string
+	Email               string
+	SecretKey           string
+	Password            string
+	Shorthand           string
+	FakeStoragePath     string
+	CliPath             string
+	OpConfigDir         string
+	MaxRetries          int
+	MaxConcurrentOps    int
+	RetryMaxWait        string
+	CommandTimeout      string
+	ServiceAccountToken string
+	ConnectURL          string
+	ConnectToken        string
+	SCIMURL             string
+	SCIMToken           string
 }
 
 func (p *ProviderConfig) configureAddress(config providerData) (string, error) {
@@ -134,7 +166,7 @@ func (p *ProviderConfig) configureFakeStoragePath(config providerData) (string,
 func (p *ProviderConfig) configureCliPath(config providerData) (string, error) {
 	// If not set get from env, the value has priority.
 	var cliPath string
-	if config.FakeStoragePath == "" {
+	if config.CliPath == "" {
 		cliPath = os.Getenv(EnvVarOpCliPath)
 	} else {
 		cliPath = config.CliPath
@@ -143,47 +175,313 @@ func (p *ProviderConfig) configureCliPath(config providerData) (string, error) {
 	return cliPath, nil
 }
 
+// configureOpConfigDir returns the op config dir, if not set a private temporary one will be created
+// so the provider doesn't modify the user's op configuration, unless the user config is required
+// (e.g: signin with an account shorthand that has been added by the user), that will use the op default.
+func (p *ProviderConfig) configureOpConfigDir(config providerData, useUserConfig bool) (string, error) {
+	if config.OpConfigDir != "" {
+		return config.OpConfigDir, nil
+	}
+
+	if useUserConfig {
+		return "", nil
+	}
+
+	return newTempOpConfigDir()
+}
+
+// tempOpConfigDirs are the temporary op config dirs created by the provider instances.
+var tempOpConfigDirs struct {
+	sync.Mutex
+	dirs []string
+}
+
+func newTempOpConfigDir() (string, error) {
+	dir, err := os.MkdirTemp("", "terraform-provider-onepasswordorg-op-")
+	if err != nil {
+		return "", fmt.Errorf("could not create temporary op config dir: %w", err)
+	}
+
+	tempOpConfigDirs.Lock()
+	tempOpConfigDirs.dirs = append(tempOpConfigDirs.dirs, dir)
+	tempOpConfigDirs.Unlock()
+
+	return dir, nil
+}
+
+// CleanUp removes the temporary resources created by the provider instances (e.g: op config dirs)
+// and flushes the telemetry, it should be called when the plugin exits.
+func CleanUp() error {
+	tempOpConfigDirs.Lock()
+	defer tempOpConfigDirs.Unlock()
+
+	// Clean up everything we can even if something fails.
+	var errs []error
+	for _, dir := range tempOpConfigDirs.dirs {
+		err := os.RemoveAll(dir)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not remove temporary op config dir: %w", err))
+		}
+	}
+	tempOpConfigDirs.dirs = nil
+
+	err := telemetry.Shutdown(context.Background())
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *ProviderConfig) configureServiceAccountToken(config providerData) (string, error) {
+	// If not set get from env, the value has priority.
+	var token string
+	if config.ServiceAccountToken == "" {
+		token = os.Getenv(envVarOpServiceAccount)
+	} else {
+		token = config.ServiceAccountToken
+	}
+
+	if token == "" {
+		return "", nil
+	}
+
+	// Service accounts don't signin, don't allow mixing user account credentials to avoid
+	// ambiguity on the account being used.
+	userAccountConfig := []struct{ name, value string }{
+		{name: "email", value: config.Email + os.Getenv(envVarOpEmail)},
+		{name: "secret key", value: config.SecretKey + os.Getenv(envVarOpSecretKey)},
+		{name: "password", value: config.Password + os.Getenv(envVarOpPassword)},
+		{name: "shorthand", value: config.Shorthand + os.Getenv(envVarOpShorthand)},
+	}
+	for _, c := range userAccountConfig {
+		if c.value != "" {
+			return "", fmt.Errorf("service account token can't be used with user account credentials, %s is set", c.name)
+		}
+	}
+
+	return token, nil
+}
+
+func (p *ProviderConfig) configureConnectURL(config providerData) (string, error) {
+	// If not set get from env, the value has priority.
+	var connectURL string
+	if config.ConnectURL == "" {
+		connectURL = os.Getenv(envVarOpConnectHost)
+	} else {
+		connectURL = config.ConnectURL
+	}
+
+	return connectURL, nil
+}
+
+func (p *ProviderConfig) configureConnectToken(config providerData) (string, error) {
+	// If not set get from env, the value has priority.
+	var token string
+	if config.ConnectToken == "" {
+		token = os.Getenv(envVarOpConnectToken)
+	} else {
+		token = config.ConnectToken
+	}
+
+	if token == "" {
+		return "", fmt.Errorf("connect token cannot be an empty string")
+	}
+
+	return token, nil
+}
+
+func (p *ProviderConfig) configureSCIMURL(config providerData) (string, error) {
+	// If not set get from env, the value has priority.
+	var scimURL string
+	if config.SCIMURL == "" {
+		scimURL = os.Getenv(envVarOpSCIMBridgeURL)
+	} else {
+		scimURL = config.SCIMURL
+	}
+
+	return scimURL, nil
+}
+
+func (p *ProviderConfig) configureSCIMToken(config providerData) (string, error) {
+	// If not set get from env, the value has priority.
+	var token string
+	if config.SCIMToken == "" {
+		token = os.Getenv(envVarOpSCIMToken)
+	} else {
+		token = config.SCIMToken
+	}
+
+	if token == "" {
+		return "", fmt.Errorf("scim token cannot be an empty string")
+	}
+
+	return token, nil
+}
+
+func (p *ProviderConfig) configureRetryMaxWait(config providerData) (time.Duration, error) {
+	maxWait, err := time.ParseDuration(config.RetryMaxWait)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %w", err)
+	}
+
+	if maxWait <= 0 {
+		return 0, fmt.Errorf("retry max wait must be greater than 0")
+	}
+
+	return maxWait, nil
+}
+
+func (p *ProviderConfig) configureCommandTimeout(config providerData) (time.Duration, error) {
+	timeout, err := time.ParseDuration(config.CommandTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %w", err)
+	}
+
+	if timeout <= 0 {
+		return 0, fmt.Errorf("command timeout must be greater than 0")
+	}
+
+	return timeout, nil
+}
+
+// Provider attribute descriptions, the SDKv2 and the framework provider schemas must be the same.
+var (
+	providerAddressDescription                 = fmt.Sprintf("Set account 1password domain address (e.g: something.1password.com). Also `%s` env var can be used.", envVarOpAddress)
+	providerEmailDescription                   = fmt.Sprintf("Set account 1password email. Also `%s` env var can be used.", envVarOpEmail)
+	providerSecretKeyDescription               = fmt.Sprintf("Set account 1password secret key. Also `%s` env var can be used.", envVarOpSecretKey)
+	providerPasswordDescription                = fmt.Sprintf("Set account 1password password. Also `%s` env var can be used.", envVarOpPassword)
+	providerShorthandDescription               = fmt.Sprintf("Set account 1password shorthand when 2FA is enabeled. Also `%s` env var can be used.", envVarOpShorthand)
+	providerFakeStoragePathDescription         = fmt.Sprintf("File to a path where the provider will store the data as if it is 1password (this is used only on development). Also `%s` env var can be used.", EnvVarOpFakeStoragePath)
+	providerOpCliPathDescription               = fmt.Sprintf("The path that points to the op cli binary. Also `%s` env var can be used. (by default `op` on system path, ignored if run in Terraform cloud).", EnvVarOpCliPath)
+	providerOpConfigDirDescription             = "The directory the op cli will use as its config directory (accounts, sessions...). By default a private temporary directory is used on every provider instance and removed when the provider exits, except when signing in with `shorthand` that will use the op default one (where the account has been added)."
+	providerServiceAccountTokenDescription     = fmt.Sprintf("Set 1password service account token, when used the op cli doesn't signin and the user account credentials (`email`, `secret_key`, `password` and `shorthand`) can't be used. Service accounts can't manage users nor groups. Also `%s` env var can be used.", envVarOpServiceAccount)
+	providerConnectURLDescription              = fmt.Sprintf("Set 1password Connect server URL, when used the provider will use the Connect API instead of the op cli. Connect can only manage items and read vaults. Also `%s` env var can be used.", envVarOpConnectHost)
+	providerConnectTokenDescription            = fmt.Sprintf("Set 1password Connect server token. Also `%s` env var can be used.", envVarOpConnectToken)
+	providerSCIMURLDescription                 = fmt.Sprintf("Set 1password SCIM bridge URL, when used the provider will use the SCIM API instead of the op cli. SCIM can only manage users, groups and group members (without group descriptions nor manager roles). Also `%s` env var can be used.", envVarOpSCIMBridgeURL)
+	providerSCIMTokenDescription               = fmt.Sprintf("Set 1password SCIM bridge bearer token. Also `%s` env var can be used.", envVarOpSCIMToken)
+	providerMaxRetriesDescription              = "The number of times an op cli command that failed with a transient error (e.g: rate limits, network errors) will be retried. `0` disables the retries."
+	providerMaxConcurrentOperationsDescription = "The maximum number of op cli commands that will run at the same time. Mutating commands on the same vault or group always run one at a time."
+	providerRetryMaxWaitDescription            = "The maximum time waited between op cli command retries, the wait grows exponentially up to this value (e.g: `30s`, `1m`)."
+	providerCommandTimeoutDescription          = "The maximum time a single op cli command (including the signin) can run before being killed (e.g: `30s`, `2m`), this way a hung op (e.g: waiting on a 2FA prompt) doesn't block Terraform forever. Resource operations are also bounded by the resources `timeouts`."
+)
+
+// Provider attribute defaults, they are applied when configuring the provider.
+const (
+	defaultMaxRetries              = 3
+	defaultMaxConcurrentOperations = 4
+	defaultRetryMaxWait            = "30s"
+	defaultCommandTimeout          = "2m"
+)
+
 // Provider The 1Password Connect terraform provider
 func Provider() *schema.Provider {
+	return newSDKProvider(newProviderConfigs())
+}
+
+// newSDKProvider returns the SDKv2 provider, the configurations are shared with the framework provider.
+func newSDKProvider(configs *providerConfigs) *schema.Provider {
 	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"address": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: fmt.Sprintf("Set account 1password domain address (e.g: something.1password.com). Also `%s` env var can be used.", envVarOpAddress),
+				Description: providerAddressDescription,
 			},
 			"email": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: fmt.Sprintf("Set account 1password email. Also `%s` env var can be used.", envVarOpEmail),
+				Description: providerEmailDescription,
 			},
 			"secret_key": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
-				Description: fmt.Sprintf("Set account 1password secret key. Also `%s` env var can be used.", envVarOpSecretKey),
+				Description: providerSecretKeyDescription,
 			},
 			"password": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
-				Description: fmt.Sprintf("Set account 1password password. Also `%s` env var can be used.", envVarOpPassword),
+				Description: providerPasswordDescription,
 			},
 			"shorthand": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
-				Description: fmt.Sprintf("Set account 1password shorthand when 2FA is enabeled. Also `%s` env var can be used.", envVarOpShorthand),
+				Description: providerShorthandDescription,
 			},
 			"fake_storage_path": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: fmt.Sprintf("File to a path where the provider will store the data as if it is 1password (this is used only on development). Also `%s` env var can be used.", EnvVarOpFakeStoragePath),
+				Description: providerFakeStoragePathDescription,
 			},
 			"op_cli_path": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: fmt.Sprintf("The path that points to the op cli binary. Also `%s` env var can be used. (by default `op` on system path, ignored if run in Terraform cloud).", EnvVarOpCliPath),
+				Description: providerOpCliPathDescription,
+			},
+			"op_config_dir": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: providerOpConfigDirDescription,
+			},
+			"service_account_token": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"email", "secret_key", "password", "shorthand"},
+				Description:   providerServiceAccountTokenDescription,
+			},
+			"connect_url": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"service_account_token", "scim_url", "email", "secret_key", "password", "shorthand"},
+				Description:   providerConnectURLDescription,
+			},
+			"connect_token": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				RequiredWith: []string{"connect_url"},
+				Description:  providerConnectTokenDescription,
+			},
+			"scim_url": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"service_account_token", "connect_url", "email", "secret_key", "password", "shorthand"},
+				Description:   providerSCIMURLDescription,
+			},
+			"scim_token": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				RequiredWith: []string{"scim_url"},
+				Description:  providerSCIMTokenDescription,
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  providerMaxRetriesDescription,
+			},
+			"max_concurrent_operations": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  providerMaxConcurrentOperationsDescription,
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				Description:  providerRetryMaxWaitDescription,
+			},
+			"command_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				Description:  providerCommandTimeoutDescription,
 			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
@@ -193,94 +491,351 @@ func Provider() *schema.Provider {
 			"onepasswordorg_vault": dataSourceVault(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"onepasswordorg_group":              resourceGroup(),
-			"onepasswordorg_group_member":       resourceGroupMember(),
-			"onepasswordorg_item":               resourceItem(),
-			"onepasswordorg_user":               resourceUser(),
-			"onepasswordorg_vault":              resourceVault(),
-			"onepasswordorg_vault_group_access": resourceVaultGroupAccess(),
-			"onepasswordorg_vault_user_access":  resourceVaultUserAccess(),
+			"onepasswordorg_group":        resourceGroup(),
+			"onepasswordorg_group_member": resourceGroupMember(),
+			"onepasswordorg_item":         resourceItem(),
+			"onepasswordorg_user":         resourceUser(),
+			"onepasswordorg_vault":        resourceVault(),
 		},
 	}
 	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-		p := ProviderConfig{}
 		config := providerData{
-			Address:         d.Get("address").(string),
-			Email:           d.Get("email").(string),
-			SecretKey:       d.Get("secret_key").(string),
-			Password:        d.Get("password").(string),
-			Shorthand:       d.Get("shorthand").(string),
-			FakeStoragePath: d.Get("fake_storage_path").(string),
-			CliPath:         d.Get("op_cli_path").(string),
+			Address:             d.Get("address").(string),
+			Email:               d.Get("email").(string),
+			SecretKey:           d.Get("secret_key").(string),
+			Password:            d.Get("password").(string),
+			Shorthand:           d.Get("shorthand").(string),
+			FakeStoragePath:     d.Get("fake_storage_path").(string),
+			CliPath:             d.Get("op_cli_path").(string),
+			OpConfigDir:         d.Get("op_config_dir").(string),
+			MaxRetries:          defaultMaxRetries,
+			MaxConcurrentOps:    defaultMaxConcurrentOperations,
+			RetryMaxWait:        defaultRetryMaxWait,
+			CommandTimeout:      defaultCommandTimeout,
+			ServiceAccountToken: d.Get("service_account_token").(string),
+			ConnectURL:          d.Get("connect_url").(string),
+			ConnectToken:        d.Get("connect_token").(string),
+			SCIMURL:             d.Get("scim_url").(string),
+			SCIMToken:           d.Get("scim_token").(string),
+		}
+
+		// The defaults are not on the schema, the muxed providers need to prepare the same configuration.
+		if v, ok := d.GetOkExists("max_retries"); ok { //nolint:staticcheck // `0` disables the retries, GetOk can't tell it from not set.
+			config.MaxRetries = v.(int)
+		}
+		if v, ok := d.GetOk("max_concurrent_operations"); ok {
+			config.MaxConcurrentOps = v.(int)
+		}
+		if v, ok := d.GetOk("retry_max_wait"); ok {
+			config.RetryMaxWait = v.(string)
+		}
+		if v, ok := d.GetOk("command_timeout"); ok {
+			config.CommandTimeout = v.(string)
+		}
+
+		p, diags := configs.configure(ctx, config)
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		return p, diags
+	}
+	return provider
+}
+
+// providerConfigs are the configured providers by configuration. The SDKv2 and the framework providers
+// served together are both configured by Terraform with the same configuration, this way the
+// repository (e.g: the op cli signin) is created once and shared.
+type providerConfigs struct {
+	mu      sync.Mutex
+	configs map[providerData]configuredProvider
+}
+
+// configuredProvider is a configured provider with the diagnostics of its configuration.
+type configuredProvider struct {
+	config ProviderConfig
+	diags  diag.Diagnostics
+}
+
+func newProviderConfigs() *providerConfigs {
+	return &providerConfigs{configs: map[providerData]configuredProvider{}}
+}
+
+// configure returns the configured provider of the configuration, configuring it the first time. The
+// configuration diagnostics are returned to every caller, so all the providers fail the same way.
+func (c *providerConfigs) configure(ctx context.Context, config providerData) (ProviderConfig, diag.Diagnostics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cp, ok := c.configs[config]; ok {
+		return cp.config, cp.diags
+	}
+
+	p, diags := configureProvider(ctx, config)
+	c.configs[config] = configuredProvider{config: p, diags: diags}
+
+	return p, diags
+}
+
+// configureProvider creates the repository of the provider configuration.
+func configureProvider(ctx context.Context, config providerData) (ProviderConfig, diag.Diagnostics) {
+	p := ProviderConfig{}
+
+	// Telemetry is only enabled when OpenTelemetry exporters are configured with env vars.
+	telemetryEnabled, err := telemetry.Setup(ctx)
+	if err != nil {
+		return p, diag.Errorf(configErrSummary + "Invalid telemetry:\n\n" + err.Error())
+	}
+
+	// Get if we are in fake mode.
+	fakeStoragePath, err := p.configureFakeStoragePath(config)
+	if err != nil {
+		return p, diag.Errorf(configErrSummary + "Invalid fake storage path:\n\n" + err.Error())
+	}
+
+	connectURL, err := p.configureConnectURL(config)
+	if err != nil {
+		return p, diag.Errorf(configErrSummary + "Invalid connect URL:\n\n" + err.Error())
+	}
+
+	scimURL, err := p.configureSCIMURL(config)
+	if err != nil {
+		return p, diag.Errorf(configErrSummary + "Invalid SCIM URL:\n\n" + err.Error())
+	}
+
+	// Create fake, connect, SCIM or regular mode.
+	// If the user has set the fake storage path then we are going to use a fake repository.
+	// If the user has set the connect URL then we are going to use the 1password Connect API.
+	// If the user has set the SCIM URL then we are going to use the 1password SCIM bridge API.
+	// If the user didn't, we will use the op cli based repository (a.k.a real 1password APIs).
+	var repo storage.Repository
+	switch {
+	case fakeStoragePath != "":
+		repo, err = fake.NewRepository(fakeStoragePath)
+		if err != nil {
+			return p, diag.Errorf(createErrSummary + "Unable to create 1password fake storage:\n\n" + err.Error())
 		}
-		// Error summaries
-		const (
-			configErrSummary = "Unable to configure client:"
-			createErrSummary = "Unable to create op client:"
-		)
-
-		// Get if we are in fake mode.
-		fakeStoragePath, err := p.configureFakeStoragePath(config)
+	case connectURL != "":
+		connectToken, err := p.configureConnectToken(config)
 		if err != nil {
-			return nil, diag.Errorf(configErrSummary + "Invalid fake storage path:\n\n" + err.Error())
+			return p, diag.Errorf(configErrSummary + "Invalid connect token:\n\n" + err.Error())
 		}
 
-		// Create fake or regular mode.
-		// If the user has set the fake storage path then we are going to use a fake repository.
-		// If the user didn't, we will use the op cli based repository (a.k.a real 1password APIs).
-		var repo storage.Repository
-		if fakeStoragePath != "" {
-			repo, err = fake.NewRepository(fakeStoragePath)
-			if err != nil {
-				return nil, diag.Errorf(createErrSummary + "Unable to create 1password fake storage:\n\n" + err.Error())
-			}
-		} else {
-			address, err := p.configureAddress(config)
-			if err != nil {
-				return nil, diag.Errorf(configErrSummary + "Invalid address:\n\n" + err.Error())
-			}
+		repo, err = connect.NewRepository(connect.RepositoryConfig{
+			URL:   connectURL,
+			Token: connectToken,
+		})
+		if err != nil {
+			return p, diag.Errorf(createErrSummary + "Unable to create 1password connect repository:\n\n" + err.Error())
+		}
+	case scimURL != "":
+		scimToken, err := p.configureSCIMToken(config)
+		if err != nil {
+			return p, diag.Errorf(configErrSummary + "Invalid SCIM token:\n\n" + err.Error())
+		}
 
-			email, err := p.configureEmail(config)
-			if err != nil {
-				return nil, diag.Errorf(configErrSummary + "Invalid email:\n\n" + err.Error())
-			}
+		repo, err = scim.NewRepository(scim.RepositoryConfig{
+			URL:   scimURL,
+			Token: scimToken,
+		})
+		if err != nil {
+			return p, diag.Errorf(createErrSummary + "Unable to create 1password SCIM repository:\n\n" + err.Error())
+		}
+	default:
+		cliPath, err := p.configureCliPath(config)
+		if err != nil {
+			return p, diag.Errorf(configErrSummary + "Invalid cli path:\n\n" + err.Error())
+		}
 
-			secretKey, err := p.configureSecretKey(config)
-			if err != nil {
-				return nil, diag.Errorf(configErrSummary + "Invalid secret key:\n\n" + err.Error())
-			}
+		retryMaxWait, err := p.configureRetryMaxWait(config)
+		if err != nil {
+			return p, diag.Errorf(configErrSummary + "Invalid retry max wait:\n\n" + err.Error())
+		}
 
-			password, err := p.configurePassword(config)
+		commandTimeout, err := p.configureCommandTimeout(config)
+		if err != nil {
+			return p, diag.Errorf(configErrSummary + "Invalid command timeout:\n\n" + err.Error())
+		}
+
+		serviceAccountToken, err := p.configureServiceAccountToken(config)
+		if err != nil {
+			return p, diag.Errorf(configErrSummary + "Invalid service account token:\n\n" + err.Error())
+		}
+
+		// Don't let a hung op block the configuration (e.g: signin waiting on a 2FA prompt).
+		cmdCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+
+		// Create OP cli.
+		// Service accounts don't signin, they use the token on every command.
+		var cli onepasswordcli.OpCli
+		if serviceAccountToken != "" {
+			configDir, err := p.configureOpConfigDir(config, false)
 			if err != nil {
-				return nil, diag.Errorf(configErrSummary + "Invalid password:\n\n" + err.Error())
+				return p, diag.Errorf(configErrSummary + "Invalid op config dir:\n\n" + err.Error())
 			}
 
-			shorthand, err := p.configureShorthand(config)
+			cli, err = onepasswordcli.NewServiceAccountOpCli(cmdCtx, cliPath, configDir, serviceAccountToken)
 			if err != nil {
-				return nil, diag.Errorf(configErrSummary + "Invalid shorthand:\n\n" + err.Error())
+				return p, diag.Errorf(createErrSummary + "Unable to create 1password op cmd client:\n\n" + err.Error())
+			}
+		} else {
+			var diags diag.Diagnostics
+			cli, diags = p.newSigninOpCli(cmdCtx, config, cliPath)
+			if diags.HasError() {
+				return p, diags
 			}
+		}
 
-			cliPath, err := p.configureCliPath(config)
+		cli, err = onepasswordcli.NewTimeoutOpCli(onepasswordcli.TimeoutOpCliConfig{
+			Cli:     cli,
+			Timeout: commandTimeout,
+		})
+		if err != nil {
+			return p, diag.Errorf(createErrSummary + "Unable to create 1password op cmd timeout client:\n\n" + err.Error())
+		}
+
+		if telemetryEnabled {
+			cli, err = onepasswordcli.NewInstrumentedOpCli(onepasswordcli.InstrumentedOpCliConfig{Cli: cli})
 			if err != nil {
-				return nil, diag.Errorf(configErrSummary + "Invalid cli path:\n\n" + err.Error())
+				return p, diag.Errorf(createErrSummary + "Unable to create 1password op cmd instrumented client:\n\n" + err.Error())
 			}
+		}
+
+		// Limit the concurrent op commands, too many of them sharing the session fail randomly.
+		cli, err = onepasswordcli.NewConcurrencyLimitOpCli(onepasswordcli.ConcurrencyLimitOpCliConfig{
+			Cli:                     cli,
+			MaxConcurrentOperations: config.MaxConcurrentOps,
+		})
+		if err != nil {
+			return p, diag.Errorf(createErrSummary + "Unable to create 1password op cmd concurrency limited client:\n\n" + err.Error())
+		}
 
-			// Create OP cli.
-			cli, err := onepasswordcli.NewOpCli(cliPath, address, email, secretKey, password, shorthand)
+		// Retry the commands that failed with transient errors.
+		cli, err = onepasswordcli.NewRetryOpCli(onepasswordcli.RetryOpCliConfig{
+			Cli:        cli,
+			MaxRetries: config.MaxRetries,
+			MaxWait:    retryMaxWait,
+		})
+		if err != nil {
+			return p, diag.Errorf(createErrSummary + "Unable to create 1password op cmd retry client:\n\n" + err.Error())
+		}
+
+		// Record the op commands into a cassette to be replayed on tests (development only).
+		if recordPath := os.Getenv(EnvVarOpCliRecordPath); recordPath != "" {
+			cli, err = onepasswordcli.NewRecordingOpCli(onepasswordcli.RecordingOpCliConfig{
+				Cli:          cli,
+				CassettePath: recordPath,
+				Secrets: []string{
+					serviceAccountToken,
+					config.Password, os.Getenv(envVarOpPassword),
+					config.SecretKey, os.Getenv(envVarOpSecretKey),
+				},
+			})
 			if err != nil {
-				return nil, diag.Errorf(createErrSummary + "Unable to create 1password op cmd client:\n\n" + err.Error())
+				return p, diag.Errorf(createErrSummary + "Unable to create 1password op cmd recording client:\n\n" + err.Error())
 			}
+		}
 
-			// Create  repository.
+		if v, ok := cli.(onepasswordcli.OpVersioner); ok {
+			tflog.Info(ctx, "Using op cli", map[string]interface{}{"op_version": v.OpVersion().String()})
+		}
+
+		// Create  repository.
+		if serviceAccountToken != "" {
+			repo, err = onepasswordcli.NewServiceAccountRepository(cli)
+		} else {
 			repo, err = onepasswordcli.NewRepository(cli)
-			if err != nil {
-				return nil, diag.Errorf(createErrSummary + "Unable to create 1password op repository:\n\n" + err.Error())
-			}
 		}
-		p.repo = repo
-		p.configured = true
+		if err != nil {
+			return p, diag.Errorf(createErrSummary + "Unable to create 1password op repository:\n\n" + err.Error())
+		}
+	}
 
-		return p, nil
+	// Cache the lists (e.g: group members) for the provider lifetime, a plan with lots of
+	// members of the same group would list the group once per member otherwise.
+	repo, err = cache.NewRepository(repo)
+	if err != nil {
+		return p, diag.Errorf(createErrSummary + "Unable to create 1password cache repository:\n\n" + err.Error())
 	}
-	return provider
+
+	if telemetryEnabled {
+		repo, err = instrumented.NewRepository(instrumented.RepositoryConfig{Repository: repo})
+		if err != nil {
+			return p, diag.Errorf(createErrSummary + "Unable to create 1password instrumented repository:\n\n" + err.Error())
+		}
+	}
+
+	p.repo = repo
+	p.configured = true
+
+	return p, nil
+}
+
+// newSigninOpCli returns an op cli signed in with the user account credentials.
+func (p *ProviderConfig) newSigninOpCli(ctx context.Context, config providerData, cliPath string) (onepasswordcli.OpCli, diag.Diagnostics) {
+	address, err := p.configureAddress(config)
+	if err != nil {
+		return nil, diag.Errorf(configErrSummary + "Invalid address:\n\n" + err.Error())
+	}
+
+	email, err := p.configureEmail(config)
+	if err != nil {
+		return nil, diag.Errorf(configErrSummary + "Invalid email:\n\n" + err.Error())
+	}
+
+	secretKey, err := p.configureSecretKey(config)
+	if err != nil {
+		return nil, diag.Errorf(configErrSummary + "Invalid secret key:\n\n" + err.Error())
+	}
+
+	password, err := p.configurePassword(config)
+	if err != nil {
+		return nil, diag.Errorf(configErrSummary + "Invalid password:\n\n" + err.Error())
+	}
+
+	shorthand, err := p.configureShorthand(config)
+	if err != nil {
+		return nil, diag.Errorf(configErrSummary + "Invalid shorthand:\n\n" + err.Error())
+	}
+
+	// Account shorthands are added by the user on its op config.
+	configDir, err := p.configureOpConfigDir(config, shorthand != "")
+	if err != nil {
+		return nil, diag.Errorf(configErrSummary + "Invalid op config dir:\n\n" + err.Error())
+	}
+
+	cli, err := onepasswordcli.NewOpCli(ctx, cliPath, configDir, address, email, secretKey, password, shorthand)
+	if err != nil {
+		return nil, diag.Errorf(createErrSummary + "Unable to create 1password op cmd client:\n\n" + err.Error())
+	}
+
+	return cli, nil
+}
+
+// defaultResourceTimeout is the default timeout of the resource operations, the users can
+// change it with the resource `timeouts` block.
+const defaultResourceTimeout = 5 * time.Minute
+
+// defaultResourceTimeouts returns the default timeouts of the SDKv2 resource operations.
+func defaultResourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultResourceTimeout),
+		Read:   schema.DefaultTimeout(defaultResourceTimeout),
+		Update: schema.DefaultTimeout(defaultResourceTimeout),
+		Delete: schema.DefaultTimeout(defaultResourceTimeout),
+	}
+}
+
+func validateDuration(v interface{}, k string) (ws []string, es []error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %q to be string", k)}
+	}
+
+	if _, err := time.ParseDuration(s); err != nil {
+		return nil, []error{fmt.Errorf("expected %q to be a valid duration (e.g: 30s, 1m): %w", k, err)}
+	}
+
+	return nil, nil
 }
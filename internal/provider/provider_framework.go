@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	fprovider "github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// ProviderServer returns the provider server that serves the SDKv2 provider and the framework
+// provider together, the resources are being ported one by one to the framework.
+func ProviderServer(ctx context.Context) (func() tfprotov6.ProviderServer, error) {
+	configs := newProviderConfigs()
+
+	sdkServer, err := tf5to6server.UpgradeServer(ctx, newSDKProvider(configs).GRPCProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	servers := []func() tfprotov6.ProviderServer{
+		func() tfprotov6.ProviderServer { return sdkServer },
+		providerserver.NewProtocol6(newFrameworkProvider(configs)),
+	}
+
+	muxServer, err := tf6muxserver.NewMuxServer(ctx, servers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return muxServer.ProviderServer, nil
+}
+
+// frameworkProvider is the terraform-plugin-framework provider, it has the same schema and
+// configuration as the SDKv2 one.
+type frameworkProvider struct {
+	configs *providerConfigs
+}
+
+var (
+	_ fprovider.ProviderWithEphemeralResources = &frameworkProvider{}
+	_ fprovider.ProviderWithFunctions          = &frameworkProvider{}
+)
+
+func newFrameworkProvider(configs *providerConfigs) fprovider.Provider {
+	return &frameworkProvider{configs: configs}
+}
+
+// frameworkProviderData is the framework provider configuration.
+type frameworkProviderData struct {
+	Address                 types.String `tfsdk:"address"`
+	Email                   types.String `tfsdk:"email"`
+	SecretKey               types.String `tfsdk:"secret_key"`
+	Password                types.String `tfsdk:"password"`
+	Shorthand               types.String `tfsdk:"shorthand"`
+	FakeStoragePath         types.String `tfsdk:"fake_storage_path"`
+	CliPath                 types.String `tfsdk:"op_cli_path"`
+	OpConfigDir             types.String `tfsdk:"op_config_dir"`
+	ServiceAccountToken     types.String `tfsdk:"service_account_token"`
+	ConnectURL              types.String `tfsdk:"connect_url"`
+	ConnectToken            types.String `tfsdk:"connect_token"`
+	SCIMURL                 types.String `tfsdk:"scim_url"`
+	SCIMToken               types.String `tfsdk:"scim_token"`
+	MaxRetries              types.Int64  `tfsdk:"max_retries"`
+	MaxConcurrentOperations types.Int64  `tfsdk:"max_concurrent_operations"`
+	RetryMaxWait            types.String `tfsdk:"retry_max_wait"`
+	CommandTimeout          types.String `tfsdk:"command_timeout"`
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req fprovider.MetadataRequest, resp *fprovider.MetadataResponse) {
+	resp.TypeName = "onepasswordorg"
+}
+
+func (p *frameworkProvider) Schema(ctx context.Context, req fprovider.SchemaRequest, resp *fprovider.SchemaResponse) {
+	// The validations and defaults are on the SDKv2 provider schema, both providers get the same configuration.
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"address":                   schema.StringAttribute{Optional: true, Description: providerAddressDescription},
+			"email":                     schema.StringAttribute{Optional: true, Description: providerEmailDescription},
+			"secret_key":                schema.StringAttribute{Optional: true, Sensitive: true, Description: providerSecretKeyDescription},
+			"password":                  schema.StringAttribute{Optional: true, Sensitive: true, Description: providerPasswordDescription},
+			"shorthand":                 schema.StringAttribute{Optional: true, Sensitive: true, Description: providerShorthandDescription},
+			"fake_storage_path":         schema.StringAttribute{Optional: true, Description: providerFakeStoragePathDescription},
+			"op_cli_path":               schema.StringAttribute{Optional: true, Description: providerOpCliPathDescription},
+			"op_config_dir":             schema.StringAttribute{Optional: true, Description: providerOpConfigDirDescription},
+			"service_account_token":     schema.StringAttribute{Optional: true, Sensitive: true, Description: providerServiceAccountTokenDescription},
+			"connect_url":               schema.StringAttribute{Optional: true, Description: providerConnectURLDescription},
+			"connect_token":             schema.StringAttribute{Optional: true, Sensitive: true, Description: providerConnectTokenDescription},
+			"scim_url":                  schema.StringAttribute{Optional: true, Description: providerSCIMURLDescription},
+			"scim_token":                schema.StringAttribute{Optional: true, Sensitive: true, Description: providerSCIMTokenDescription},
+			"max_retries":               schema.Int64Attribute{Optional: true, Description: providerMaxRetriesDescription},
+			"max_concurrent_operations": schema.Int64Attribute{Optional: true, Description: providerMaxConcurrentOperationsDescription},
+			"retry_max_wait":            schema.StringAttribute{Optional: true, Description: providerRetryMaxWaitDescription},
+			"command_timeout":           schema.StringAttribute{Optional: true, Description: providerCommandTimeoutDescription},
+		},
+	}
+}
+
+func (p *frameworkProvider) Configure(ctx context.Context, req fprovider.ConfigureRequest, resp *fprovider.ConfigureResponse) {
+	var data frameworkProviderData
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Same defaults as the SDKv2 provider, so both get the same configured provider.
+	config := providerData{
+		Address:             data.Address.ValueString(),
+		Email:               data.Email.ValueString(),
+		SecretKey:           data.SecretKey.ValueString(),
+		Password:            data.Password.ValueString(),
+		Shorthand:           data.Shorthand.ValueString(),
+		FakeStoragePath:     data.FakeStoragePath.ValueString(),
+		CliPath:             data.CliPath.ValueString(),
+		OpConfigDir:         data.OpConfigDir.ValueString(),
+		MaxRetries:          defaultMaxRetries,
+		MaxConcurrentOps:    defaultMaxConcurrentOperations,
+		RetryMaxWait:        defaultRetryMaxWait,
+		CommandTimeout:      defaultCommandTimeout,
+		ServiceAccountToken: data.ServiceAccountToken.ValueString(),
+		ConnectURL:          data.ConnectURL.ValueString(),
+		ConnectToken:        data.ConnectToken.ValueString(),
+		SCIMURL:             data.SCIMURL.ValueString(),
+		SCIMToken:           data.SCIMToken.ValueString(),
+	}
+	if !data.MaxRetries.IsNull() {
+		config.MaxRetries = int(data.MaxRetries.ValueInt64())
+	}
+	if !data.MaxConcurrentOperations.IsNull() {
+		config.MaxConcurrentOps = int(data.MaxConcurrentOperations.ValueInt64())
+	}
+	if !data.RetryMaxWait.IsNull() {
+		config.RetryMaxWait = data.RetryMaxWait.ValueString()
+	}
+	if !data.CommandTimeout.IsNull() {
+		config.CommandTimeout = data.CommandTimeout.ValueString()
+	}
+
+	providerConfig, diags := p.configs.configure(ctx, config)
+	for _, d := range diags {
+		if d.Severity == diag.Warning {
+			resp.Diagnostics.AddWarning(d.Summary, d.Detail)
+			continue
+		}
+		resp.Diagnostics.AddError(d.Summary, d.Detail)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.DataSourceData = providerConfig
+	resp.ResourceData = providerConfig
+	resp.EphemeralResourceData = providerConfig
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newVaultGroupAccessResource,
+		newVaultUserAccessResource,
+	}
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		newItemEphemeralResource,
+	}
+}
+
+func (p *frameworkProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		newParseItemIDFunction,
+		newSecretReferenceFunction,
+	}
+}
@@ -1,10 +1,18 @@
 package provider_test
 
 import (
+	"context"
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/slok/terraform-provider-onepasswordorg/internal/provider"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/fake"
@@ -21,14 +29,16 @@ import (
 //
 // To run the tests you will need to set `OP_FAKE_STORAGE_PATH` pointing to the fake storage file.
 
-var testAccProviders map[string]*schema.Provider
-var testAccProvider *schema.Provider
+// testAccProtoV6ProviderFactories serve the SDKv2 and the framework providers muxed, as Terraform uses them.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"onepasswordorg": func() (tfprotov6.ProviderServer, error) {
+		server, err := provider.ProviderServer(context.Background())
+		if err != nil {
+			return nil, err
+		}
 
-func init() {
-	testAccProvider = provider.Provider()
-	testAccProviders = map[string]*schema.Provider{
-		"onepasswordorg": testAccProvider,
-	}
+		return server(), nil
+	},
 }
 
 func testAccPreCheck(t *testing.T) {
@@ -53,3 +63,310 @@ func getFakeRepository(t *testing.T) storage.Repository {
 
 	return r
 }
+
+func TestProviderConfigureServiceAccountToken(t *testing.T) {
+	tests := map[string]struct {
+		config map[string]interface{}
+		expErr bool
+	}{
+		"A service account token without user account credentials should configure the provider.": {
+			config: map[string]interface{}{
+				"service_account_token": "ops_test",
+			},
+		},
+
+		"A service account token with user account credentials should fail.": {
+			config: map[string]interface{}{
+				"service_account_token": "ops_test",
+				"email":                 "test@test.io",
+				"password":              "test",
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			unsetProviderEnv(t)
+			setFakeOpCli(t)
+
+			p := provider.Provider()
+			diags := p.Configure(context.TODO(), terraform.NewResourceConfigRaw(test.config))
+
+			if test.expErr {
+				assert.True(t, diags.HasError())
+			} else {
+				assert.False(t, diags.HasError(), diags)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureConnect(t *testing.T) {
+	tests := map[string]struct {
+		config map[string]interface{}
+		expErr bool
+	}{
+		"A connect URL with a token should configure the provider.": {
+			config: map[string]interface{}{
+				"connect_url":   "http://localhost:8080",
+				"connect_token": "test",
+			},
+		},
+
+		"A connect URL without a token should fail.": {
+			config: map[string]interface{}{
+				"connect_url": "http://localhost:8080",
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			unsetProviderEnv(t)
+
+			p := provider.Provider()
+			diags := p.Configure(context.TODO(), terraform.NewResourceConfigRaw(test.config))
+
+			if test.expErr {
+				assert.True(t, diags.HasError())
+			} else {
+				assert.False(t, diags.HasError(), diags)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureSCIM(t *testing.T) {
+	tests := map[string]struct {
+		config map[string]interface{}
+		expErr bool
+	}{
+		"A SCIM URL with a token should configure the provider.": {
+			config: map[string]interface{}{
+				"scim_url":   "https://scim.test.io",
+				"scim_token": "test",
+			},
+		},
+
+		"A SCIM URL without a token should fail.": {
+			config: map[string]interface{}{
+				"scim_url": "https://scim.test.io",
+			},
+			expErr: true,
+		},
+
+		"A SCIM URL with a connect URL should fail.": {
+			config: map[string]interface{}{
+				"scim_url":      "https://scim.test.io",
+				"scim_token":    "test",
+				"connect_url":   "http://localhost:8080",
+				"connect_token": "test",
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			unsetProviderEnv(t)
+
+			p := provider.Provider()
+			diags := p.Validate(terraform.NewResourceConfigRaw(test.config))
+			if !diags.HasError() {
+				diags = p.Configure(context.TODO(), terraform.NewResourceConfigRaw(test.config))
+			}
+
+			if test.expErr {
+				assert.True(t, diags.HasError())
+			} else {
+				assert.False(t, diags.HasError(), diags)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureOpConfigDir(t *testing.T) {
+	tests := map[string]struct {
+		config     map[string]interface{}
+		expTempDir bool
+	}{
+		"Without an op config dir, a temporary one should be created.": {
+			config: map[string]interface{}{
+				"service_account_token": "test",
+			},
+			expTempDir: true,
+		},
+
+		"With an op config dir, a temporary one should not be created.": {
+			config: map[string]interface{}{
+				"service_account_token": "test",
+				"op_config_dir":         "/tmp/test",
+			},
+			expTempDir: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			unsetProviderEnv(t)
+			setFakeOpCli(t)
+			tmpDir := t.TempDir()
+			t.Setenv("TMPDIR", tmpDir)
+
+			p := provider.Provider()
+			diags := p.Configure(context.TODO(), terraform.NewResourceConfigRaw(test.config))
+			assert.False(t, diags.HasError(), diags)
+
+			dirs, _ := filepath.Glob(filepath.Join(tmpDir, "terraform-provider-onepasswordorg-op-*"))
+			assert.Equal(t, test.expTempDir, len(dirs) == 1)
+
+			// Clean up should remove the temporary dirs.
+			err := provider.CleanUp()
+			assert.NoError(t, err)
+			dirs, _ = filepath.Glob(filepath.Join(tmpDir, "terraform-provider-onepasswordorg-op-*"))
+			assert.Empty(t, dirs)
+		})
+	}
+}
+
+func TestProviderConfigureCommandTimeout(t *testing.T) {
+	tests := map[string]struct {
+		commandTimeout string
+		expErr         bool
+	}{
+		"A valid command timeout should configure the provider.": {
+			commandTimeout: "30s",
+		},
+
+		"A zero command timeout should fail.": {
+			commandTimeout: "0s",
+			expErr:         true,
+		},
+
+		"An invalid command timeout should fail.": {
+			commandTimeout: "whatever",
+			expErr:         true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			unsetProviderEnv(t)
+			setFakeOpCli(t)
+
+			config := map[string]interface{}{
+				"service_account_token": "ops_test",
+				"command_timeout":       test.commandTimeout,
+			}
+			p := provider.Provider()
+			diags := p.Validate(terraform.NewResourceConfigRaw(config))
+			if !diags.HasError() {
+				diags = p.Configure(context.TODO(), terraform.NewResourceConfigRaw(config))
+			}
+
+			if test.expErr {
+				assert.True(t, diags.HasError())
+			} else {
+				assert.False(t, diags.HasError(), diags)
+			}
+		})
+	}
+}
+
+// unsetProviderEnv unsets the env vars used to configure the provider, so the tests only use the
+// provider configuration.
+func unsetProviderEnv(t *testing.T) {
+	envs := []string{
+		provider.EnvVarOpFakeStoragePath,
+		"OP_EMAIL",
+		"OP_SECRET_KEY",
+		"OP_PASSWORD",
+		"OP_SHORTHAND",
+		"OP_SERVICE_ACCOUNT_TOKEN",
+		"OP_CONNECT_HOST",
+		"OP_CONNECT_TOKEN",
+		"OP_SCIM_BRIDGE_URL",
+		"OP_SCIM_TOKEN",
+		"TFC_RUN_ID",
+	}
+	for _, env := range envs {
+		t.Setenv(env, "")
+	}
+}
+
+// setFakeOpCli sets an op cli stand-in that only knows how to return its version, enough
+// to configure the provider with a service account.
+func setFakeOpCli(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake op cli is a shell script")
+	}
+
+	binPath := filepath.Join(t.TempDir(), "op")
+	err := os.WriteFile(binPath, []byte("#!/bin/sh\n[ \"$*\" = \"--version\" ] && echo 2.24.0\n"), 0755)
+	if err != nil {
+		t.Fatalf("could not write fake op cli: %s", err)
+	}
+	t.Setenv(provider.EnvVarOpCliPath, binPath)
+}
+
+func TestProviderServerSchema(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	server, err := provider.ProviderServer(context.TODO())
+	require.NoError(err)
+
+	// The muxed providers schemas must be the same.
+	resp, err := server().GetProviderSchema(context.TODO(), &tfprotov6.GetProviderSchemaRequest{})
+	require.NoError(err)
+	for _, d := range resp.Diagnostics {
+		assert.Fail(d.Summary, d.Detail)
+	}
+
+	// The resources are served by the SDKv2 or the framework provider.
+	expResources := []string{
+		"onepasswordorg_group",
+		"onepasswordorg_group_member",
+		"onepasswordorg_item",
+		"onepasswordorg_user",
+		"onepasswordorg_vault",
+		"onepasswordorg_vault_group_access",
+		"onepasswordorg_vault_user_access",
+	}
+	for _, r := range expResources {
+		assert.Contains(resp.ResourceSchemas, r)
+	}
+	assert.Contains(resp.EphemeralResourceSchemas, "onepasswordorg_item")
+	assert.Contains(resp.Functions, "secret_reference")
+	assert.Contains(resp.Functions, "parse_item_id")
+}
+
+func TestProviderServerConfigureFailure(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	ctx := context.TODO()
+	unsetProviderEnv(t)
+
+	server, err := provider.ProviderServer(ctx)
+	require.NoError(err)
+	s := server()
+
+	schemaResp, err := s.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	require.NoError(err)
+	config := newDynamicValue(t, schemaResp.Provider.ValueType(), map[string]tftypes.Value{
+		"service_account_token": tftypes.NewValue(tftypes.String, "test"),
+		"command_timeout":       tftypes.NewValue(tftypes.String, "wrong"),
+	})
+
+	// The configuration is shared by the muxed providers, every configuration with the same failing
+	// configuration should fail with the configuration error, not only the first one.
+	for i := 0; i < 2; i++ {
+		resp, err := s.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: config})
+		require.NoError(err)
+		require.Len(resp.Diagnostics, 1)
+		assert.Equal(tfprotov6.DiagnosticSeverityError, resp.Diagnostics[0].Severity)
+		assert.Contains(resp.Diagnostics[0].Summary, "Invalid command timeout")
+	}
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -9,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 )
 
 func resourceGroup() *schema.Resource {
@@ -23,6 +25,7 @@ func resourceGroup() *schema.Resource {
 		ReadContext:   resourceGroupRead,
 		UpdateContext: resourceGroupUpdate,
 		DeleteContext: resourceGroupDelete,
+		Timeouts:      defaultResourceTimeouts(),
 
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -78,6 +81,12 @@ func resourceGroupRead(ctx context.Context, data *schema.ResourceData, meta inte
 	id := data.Id()
 	group, err := p.repo.GetGroupByID(ctx, id)
 	if err != nil {
+		// Missing group means it has been deleted outside Terraform, remove it from the state so it's planned again.
+		if errors.Is(err, storage.ErrNotFound) {
+			data.SetId("")
+			return diags
+		}
+
 		return diag.Errorf("Error reading group:" + fmt.Sprintf("Could not get group %q, unexpected error: %s", id, err.Error()))
 	}
 
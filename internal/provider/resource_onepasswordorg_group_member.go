@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -11,6 +12,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 )
 
 func resourceGroupMember() *schema.Resource {
@@ -24,6 +26,7 @@ A 1password group membership will make a user part of a group with a role on tha
 		ReadContext:   resourceGroupMemberRead,
 		UpdateContext: resourceGroupMemberUpdate,
 		DeleteContext: resourceGroupMemberDelete,
+		Timeouts:      defaultResourceTimeouts(),
 
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -95,6 +98,12 @@ func resourceGroupMemberRead(ctx context.Context, data *schema.ResourceData, met
 
 	member, err := p.repo.GetMembershipByID(ctx, groupID, userID)
 	if err != nil {
+		// Missing group member means it has been deleted outside Terraform, remove it from the state so it's planned again.
+		if errors.Is(err, storage.ErrNotFound) {
+			data.SetId("")
+			return diags
+		}
+
 		return diag.Errorf("Error reading group:" + fmt.Sprintf("Could not get group %q, unexpected error: %s", id, err.Error()))
 	}
 
@@ -128,9 +128,9 @@ resource "onepasswordorg_group_member" "test_member" {
 
 			// Execute test.
 			resource.Test(t, resource.TestCase{
-				PreCheck:     func() { testAccPreCheck(t) },
-				Providers:    testAccProviders,
-				CheckDestroy: assertGroupMemberDeletedOnFakeStorage(t, test.expMember.GroupID, test.expMember.UserID),
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy:             assertGroupMemberDeletedOnFakeStorage(t, test.expMember.GroupID, test.expMember.UserID),
 				Steps: []resource.TestStep{
 					{
 						Config:      test.config,
@@ -180,8 +180,8 @@ resource "onepasswordorg_group_member" "test_member" {
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: configCreate,
@@ -1,14 +1,19 @@
 package provider_test
 
 import (
+	"context"
 	"os"
 	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/provider"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/fake"
 )
 
 // TestAccGroupCreateDelete will check a group is created and deleted.
@@ -26,7 +31,6 @@ resource "onepasswordorg_group" "test_group" {
 }
 `,
 			expGroup: model.Group{
-				ID:          "test-group",
 				Name:        "test-group",
 				Description: "Test group",
 			},
@@ -39,7 +43,6 @@ resource "onepasswordorg_group" "test_group" {
 }
 `,
 			expGroup: model.Group{
-				ID:          "test-group",
 				Name:        "test-group",
 				Description: "Managed by Terraform",
 			},
@@ -76,8 +79,7 @@ resource "onepasswordorg_group" "test_group" {
 			var checks resource.TestCheckFunc
 			if test.expErr == nil {
 				checks = resource.ComposeAggregateTestCheckFunc(
-					assertGroupOnFakeStorage(t, &test.expGroup),
-					resource.TestCheckResourceAttr("onepasswordorg_group.test_group", "id", test.expGroup.ID),
+					assertGroupOnFakeStorage(t, "onepasswordorg_group.test_group", &test.expGroup),
 					resource.TestCheckResourceAttr("onepasswordorg_group.test_group", "name", test.expGroup.Name),
 					resource.TestCheckResourceAttr("onepasswordorg_group.test_group", "description", test.expGroup.Description),
 				)
@@ -85,9 +87,9 @@ resource "onepasswordorg_group" "test_group" {
 
 			// Execute test.
 			resource.Test(t, resource.TestCase{
-				PreCheck:     func() { testAccPreCheck(t) },
-				Providers:    testAccProviders,
-				CheckDestroy: assertGroupDeletedOnFakeStorage(t, test.expGroup.ID),
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy:             assertGroupDeletedOnFakeStorage(t, test.expGroup.Name),
 				Steps: []resource.TestStep{
 					{
 						Config:      test.config,
@@ -122,35 +124,31 @@ resource "onepasswordorg_group" "test_group" {
 }
 `
 
-	// Fake repo IDs are based on emails.
 	expGroupCreate := model.Group{
-		ID:          "test-group",
 		Name:        "test-group",
 		Description: "Test group",
 	}
 
 	expGroupUpdate := model.Group{
-		ID:          "test-group",
 		Name:        "test-group",
 		Description: "Test group modified",
 	}
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: configCreate,
 				Check: resource.ComposeAggregateTestCheckFunc(
-					assertGroupOnFakeStorage(t, &expGroupCreate),
+					assertGroupOnFakeStorage(t, "onepasswordorg_group.test_group", &expGroupCreate),
 				),
 			},
 			{
 				Config: configUpdate,
 				Check: resource.ComposeAggregateTestCheckFunc(
-					assertGroupOnFakeStorage(t, &expGroupUpdate),
-					resource.TestCheckResourceAttr("onepasswordorg_group.test_group", "id", "test-group"), // Fake uses name as IDs.
+					assertGroupOnFakeStorage(t, "onepasswordorg_group.test_group", &expGroupUpdate),
 					resource.TestCheckResourceAttr("onepasswordorg_group.test_group", "name", "test-group"),
 					resource.TestCheckResourceAttr("onepasswordorg_group.test_group", "description", "Test group modified"),
 				),
@@ -158,3 +156,178 @@ resource "onepasswordorg_group" "test_group" {
 		},
 	})
 }
+
+// TestAccGroupRename will check a renamed group is updated in place, keeping its ID.
+func TestAccGroupRename(t *testing.T) {
+	// Prepare fake storage.
+	path, delete := getFakeRepoTmpFile("TestAccGroupRename")
+	defer delete()
+	_ = os.Setenv(provider.EnvVarOpFakeStoragePath, path)
+
+	// Test tf data.
+	configCreate := `
+resource "onepasswordorg_group" "test_group" {
+  name  	  = "test-group"
+  description = "Test group"
+}
+`
+	configUpdate := `
+resource "onepasswordorg_group" "test_group" {
+  name  	  = "test-group-renamed"
+  description = "Test group"
+}
+`
+
+	var groupID string
+	storeGroupID := func(s *terraform.State) error {
+		groupID = getFakeIDFromState(t, s, "onepasswordorg_group.test_group")
+		return nil
+	}
+
+	// Execute test.
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: configCreate,
+				Check:  storeGroupID,
+			},
+			{
+				Config: configUpdate,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPtr("onepasswordorg_group.test_group", "id", &groupID),
+					assertGroupOnFakeStorage(t, "onepasswordorg_group.test_group", &model.Group{Name: "test-group-renamed", Description: "Test group"}),
+					assertGroupDeletedOnFakeStorage(t, "test-group"),
+				),
+			},
+		},
+	})
+}
+
+// TestAccGroupDeletedOutsideTerraform will check a group deleted outside Terraform is planned to be created again.
+func TestAccGroupDeletedOutsideTerraform(t *testing.T) {
+	// Prepare fake storage.
+	path, delete := getFakeRepoTmpFile("TestAccGroupDeletedOutsideTerraform")
+	defer delete()
+	_ = os.Setenv(provider.EnvVarOpFakeStoragePath, path)
+
+	// Test tf data.
+	config := `
+resource "onepasswordorg_group" "test_group" {
+  name  	  = "test-group"
+  description = "Test group"
+}
+`
+
+	// Execute test.
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				PreConfig: func() {
+					repo := getFakeRepository(t)
+					group, err := repo.GetGroupByName(context.TODO(), "test-group")
+					if err != nil {
+						t.Fatalf("could not get group: %s", err)
+					}
+					err = repo.DeleteGroup(context.TODO(), group.ID)
+					if err != nil {
+						t.Fatalf("could not delete group: %s", err)
+					}
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// TestAccGroupCreateTimeout will check a slow group creation fails when it reaches the resource timeout.
+func TestAccGroupCreateTimeout(t *testing.T) {
+	// Prepare fake storage.
+	path, delete := getFakeRepoTmpFile("TestAccGroupCreateTimeout")
+	defer delete()
+	_ = os.Setenv(provider.EnvVarOpFakeStoragePath, path)
+	t.Setenv(fake.EnvVarOpFakeLatency, "3s")
+
+	// Test tf data.
+	config := `
+resource "onepasswordorg_group" "test_group" {
+  name  	  = "test-group"
+  description = "Test group"
+
+  timeouts {
+    create = "1s"
+  }
+}
+`
+
+	// Execute test.
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             assertGroupDeletedOnFakeStorage(t, "test-group"),
+		Steps: []resource.TestStep{
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile("context deadline exceeded"),
+			},
+		},
+	})
+}
+
+// TestAccGroupCreatePartialFailure will check the groups that didn't fail are created when others fail.
+func TestAccGroupCreatePartialFailure(t *testing.T) {
+	// Prepare fake storage.
+	path, delete := getFakeRepoTmpFile("TestAccGroupCreatePartialFailure")
+	defer delete()
+	_ = os.Setenv(provider.EnvVarOpFakeStoragePath, path)
+	t.Setenv(fake.EnvVarOpFakeErrorIDs, "test-group-failing")
+
+	// Test tf data.
+	config := `
+resource "onepasswordorg_group" "test_group" {
+  name  	  = "test-group"
+  description = "Test group"
+}
+
+resource "onepasswordorg_group" "test_group_failing" {
+  name  	  = "test-group-failing"
+  description = "Test group"
+}
+`
+
+	// Execute test.
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config,
+				ExpectError: regexp.MustCompile("fake injected error"),
+			},
+			{
+				// The failed apply doesn't run the checks, check the storage before planning again.
+				PreConfig: func() {
+					t.Setenv(fake.EnvVarOpFakeErrorIDs, "")
+					repo := getFakeRepository(t)
+					group, err := repo.GetGroupByName(context.TODO(), "test-group")
+					if assert.NoError(t, err) {
+						assert.Equal(t, "Test group", group.Description)
+					}
+					_, err = repo.GetGroupByName(context.TODO(), "test-group-failing")
+					assert.ErrorIs(t, err, storage.ErrNotFound)
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 )
 
 func resourceItem() *schema.Resource {
@@ -21,6 +23,7 @@ func resourceItem() *schema.Resource {
 		ReadContext:   resourceItemRead,
 		UpdateContext: resourceItemUpdate,
 		DeleteContext: resourceItemDelete,
+		Timeouts:      defaultResourceTimeouts(),
 
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -198,9 +201,19 @@ func resourceItemRead(ctx context.Context, data *schema.ResourceData, meta inter
 
 	// Get group.
 	id := data.Id()
-	_, itemUUID := vaultAndItemUUID(id)
+	_, itemUUID, err := unpackItemID(id)
+	if err != nil {
+		return diag.Errorf("Error getting item ID:" + err.Error())
+	}
+
 	item, err := p.repo.GetItemByID(ctx, itemUUID)
 	if err != nil {
+		// Missing item means it has been deleted outside Terraform, remove it from the state so it's planned again.
+		if errors.Is(err, storage.ErrNotFound) {
+			data.SetId("")
+			return diags
+		}
+
 		return diag.Errorf("Error reading group:" + fmt.Sprintf("Could not get item %q, unexpected error: %s", id, err.Error()))
 	}
 
@@ -239,23 +252,17 @@ func resourceItemDelete(ctx context.Context, data *schema.ResourceData, meta int
 
 	// Get group.
 	id := data.Id()
-	_, itemUUID := vaultAndItemUUID(id)
-	err := p.repo.DeleteItem(ctx, itemUUID)
+	_, itemUUID, err := unpackItemID(id)
 	if err != nil {
-		return diag.Errorf("Error deleting item:" + fmt.Sprintf("Could not get item %q, unexpected error: %s", id, err.Error()))
+		return diag.Errorf("Error getting item ID:" + err.Error())
 	}
 
-	return diags
-}
-
-func vaultAndItemUUID(tfID string) (vaultUUID, itemUUID string) {
-	elements := strings.Split(tfID, "/")
-
-	if len(elements) != 4 {
-		return "", ""
+	err = p.repo.DeleteItem(ctx, itemUUID)
+	if err != nil {
+		return diag.Errorf("Error deleting item:" + fmt.Sprintf("Could not get item %q, unexpected error: %s", id, err.Error()))
 	}
 
-	return elements[1], elements[3]
+	return diags
 }
 
 func itemToData(item *model.Item, data *schema.ResourceData) {
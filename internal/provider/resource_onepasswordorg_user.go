@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -9,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 )
 
 func resourceUser() *schema.Resource {
@@ -22,6 +24,7 @@ When a 1password user resources is created, it will be invited  by email.
 		ReadContext:   resourceUserRead,
 		UpdateContext: resourceUserUpdate,
 		DeleteContext: resourceUserDelete,
+		Timeouts:      defaultResourceTimeouts(),
 
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -78,6 +81,12 @@ func resourceUserRead(ctx context.Context, data *schema.ResourceData, meta inter
 	id := data.Id()
 	user, err := p.repo.GetUserByID(ctx, id)
 	if err != nil {
+		// Missing user means it has been deleted outside Terraform, remove it from the state so it's planned again.
+		if errors.Is(err, storage.ErrNotFound) {
+			data.SetId("")
+			return diags
+		}
+
 		return diag.Errorf("Error reading user:" + fmt.Sprintf("Could not get user %q, unexpected error: %s", id, err.Error()))
 	}
 
@@ -26,7 +26,6 @@ resource "onepasswordorg_user" "test_user" {
 }
 `,
 			expUser: model.User{
-				ID:    "testuser@test.test",
 				Name:  "Test user",
 				Email: "testuser@test.test",
 			},
@@ -82,8 +81,7 @@ resource "onepasswordorg_user" "test_user" {
 			var checks resource.TestCheckFunc
 			if test.expErr == nil {
 				checks = resource.ComposeAggregateTestCheckFunc(
-					assertUserOnFakeStorage(t, &test.expUser),
-					resource.TestCheckResourceAttr("onepasswordorg_user.test_user", "id", test.expUser.ID),
+					assertUserOnFakeStorage(t, "onepasswordorg_user.test_user", &test.expUser),
 					resource.TestCheckResourceAttr("onepasswordorg_user.test_user", "name", test.expUser.Name),
 					resource.TestCheckResourceAttr("onepasswordorg_user.test_user", "email", test.expUser.Email),
 				)
@@ -91,9 +89,9 @@ resource "onepasswordorg_user" "test_user" {
 
 			// Execute test.
 			resource.Test(t, resource.TestCase{
-				PreCheck:     func() { testAccPreCheck(t) },
-				Providers:    testAccProviders,
-				CheckDestroy: assertUserDeletedOnFakeStorage(t, test.expUser.Email),
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy:             assertUserDeletedOnFakeStorage(t, test.expUser.Email),
 				Steps: []resource.TestStep{
 					{
 						Config:      test.config,
@@ -127,35 +125,31 @@ resource "onepasswordorg_user" "test_user" {
 }
 `
 
-	// Fake repo IDs are based on emails.
 	expUserCreate := model.User{
-		ID:    "testuser@test.test",
 		Name:  "Test user",
 		Email: "testuser@test.test",
 	}
 
 	expUserUpdate := model.User{
-		ID:    "testuser@test.test",
 		Name:  "Test user modified",
 		Email: "testuser@test.test",
 	}
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: configCreate,
 				Check: resource.ComposeAggregateTestCheckFunc(
-					assertUserOnFakeStorage(t, &expUserCreate),
+					assertUserOnFakeStorage(t, "onepasswordorg_user.test_user", &expUserCreate),
 				),
 			},
 			{
 				Config: configUpdate,
 				Check: resource.ComposeAggregateTestCheckFunc(
-					assertUserOnFakeStorage(t, &expUserUpdate),
-					resource.TestCheckResourceAttr("onepasswordorg_user.test_user", "id", "testuser@test.test"), // Fake uses email as IDs.
+					assertUserOnFakeStorage(t, "onepasswordorg_user.test_user", &expUserUpdate),
 					resource.TestCheckResourceAttr("onepasswordorg_user.test_user", "name", "Test user modified"),
 					resource.TestCheckResourceAttr("onepasswordorg_user.test_user", "email", "testuser@test.test"),
 				),
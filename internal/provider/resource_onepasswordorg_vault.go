@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -9,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 )
 
 type resourceVaultType struct{}
@@ -22,6 +24,7 @@ Provides a vault resource.
 		ReadContext:   resourceVaultRead,
 		UpdateContext: resourceVaultUpdate,
 		DeleteContext: resourceVaultDelete,
+		Timeouts:      defaultResourceTimeouts(),
 
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -77,6 +80,12 @@ func resourceVaultRead(ctx context.Context, data *schema.ResourceData, meta inte
 	id := data.Id()
 	vault, err := p.repo.GetVaultByID(ctx, id)
 	if err != nil {
+		// Missing vault means it has been deleted outside Terraform, remove it from the state so it's planned again.
+		if errors.Is(err, storage.ErrNotFound) {
+			data.SetId("")
+			return diags
+		}
+
 		return diag.Errorf("Error reading vault:" + fmt.Sprintf("Could not get vault %q, unexpected error: %s", id, err.Error()))
 	}
 
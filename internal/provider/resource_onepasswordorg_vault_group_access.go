@@ -2,174 +2,242 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 )
 
-func resourceVaultGroupAccess() *schema.Resource {
-	return &schema.Resource{
+type vaultGroupAccessResource struct {
+	p ProviderConfig
+}
+
+var (
+	_ resource.ResourceWithConfigure   = &vaultGroupAccessResource{}
+	_ resource.ResourceWithImportState = &vaultGroupAccessResource{}
+)
+
+func newVaultGroupAccessResource() resource.Resource {
+	return &vaultGroupAccessResource{}
+}
+
+func (r *vaultGroupAccessResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_vault_group_access"
+}
+
+func (r *vaultGroupAccessResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
 		Description: `
 Provides vault access for a group.
     `,
-		CreateContext: resourceVaultGroupAccessCreate,
-		ReadContext:   resourceVaultGroupAccessRead,
-		UpdateContext: resourceVaultGroupAccessUpdate,
-		DeleteContext: resourceVaultGroupAccessDelete,
-
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
-		Schema: map[string]*schema.Schema{
-			"id": {
-				Type:     schema.TypeString,
-				Computed: true,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				Description:   "The ID of this resource.",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
-			"vault_id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "The vault ID.",
+			"vault_id": schema.StringAttribute{
+				Required:      true,
+				Description:   "The vault ID.",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
 			},
-			"group_id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "The group ID.",
+			"group_id": schema.StringAttribute{
+				Required:      true,
+				Description:   "The group ID.",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
 			},
-			"permissions": permissionsAttribute,
+		},
+		Blocks: map[string]schema.Block{
+			"permissions": permissionsBlock(),
+			"timeouts":    timeouts.Block(ctx, timeouts.Opts{Create: true, Read: true, Update: true, Delete: true}),
 		},
 	}
 }
 
-func resourceVaultGroupAccessCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	p := meta.(ProviderConfig)
-	var diags diag.Diagnostics
-	if !p.configured {
-		return diag.Errorf("Provider not configured:" + "The provider hasn't been configured before apply.")
+func (r *vaultGroupAccessResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Not configured yet (e.g: validation).
+	if req.ProviderData == nil {
+		return
 	}
 
-	m, err := dataToVaultGroupAccess(data)
-	if err != nil {
-		return diag.Errorf(err.Error())
+	r.p = req.ProviderData.(ProviderConfig)
+}
+
+func (r *vaultGroupAccessResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
 	}
 
-	err = p.repo.EnsureVaultGroupAccess(ctx, *m)
-	if err != nil {
-		return diag.Errorf(err.Error())
+	var plan VaultGroupAccess
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	vaultGroupAccessToData(*m, data)
+	timeout, diags := plan.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	return diags
+	m := dataToVaultGroupAccess(plan)
+	err := r.p.repo.EnsureVaultGroupAccess(ctx, m)
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating group access", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, vaultGroupAccessToData(m, plan.Permissions, plan.Timeouts))...)
 }
 
-func resourceVaultGroupAccessRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	p := meta.(ProviderConfig)
-	var diags diag.Diagnostics
-	if !p.configured {
-		return diag.Errorf("Provider not configured:" + "The provider hasn't been configured before apply.")
+func (r *vaultGroupAccessResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
+	}
+
+	var state VaultGroupAccess
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := state.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	id := data.Id()
+	id := state.ID.ValueString()
 	vaultID, groupID, err := unpackVaultGroupAccessID(id)
 	if err != nil {
-		return diag.Errorf("Error getting member ID: " + "Could not get member ID:" + err.Error())
+		resp.Diagnostics.AddError("Error getting group access ID", "Could not get group access ID: "+err.Error())
+		return
 	}
 
-	vaultGroupAccess, err := p.repo.GetVaultGroupAccessByID(ctx, vaultID, groupID)
+	vaultGroupAccess, err := r.p.repo.GetVaultGroupAccessByID(ctx, vaultID, groupID)
 	if err != nil {
-		return diag.Errorf("Error reading group access:" + fmt.Sprintf("Could not get group access %q, unexpected error: %s", id, err.Error()))
+		// Missing vault group access means it has been deleted outside Terraform, remove it from the state so it's planned again.
+		if errors.Is(err, storage.ErrNotFound) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError("Error reading group access", fmt.Sprintf("Could not get group access %q, unexpected error: %s", id, err.Error()))
+		return
 	}
 
-	vaultGroupAccessToData(*vaultGroupAccess, data)
-	return diags
+	resp.Diagnostics.Append(resp.State.Set(ctx, vaultGroupAccessToData(*vaultGroupAccess, state.Permissions, state.Timeouts))...)
 }
 
-func resourceVaultGroupAccessUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	p := meta.(ProviderConfig)
-	var diags diag.Diagnostics
-	if !p.configured {
-		return diag.Errorf("Provider not configured:" + "The provider hasn't been configured before apply.")
+func (r *vaultGroupAccessResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
 	}
 
-	m, err := dataToVaultGroupAccess(data)
-	if err != nil {
-		return diag.Errorf(err.Error())
+	var plan VaultGroupAccess
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	err = p.repo.EnsureVaultGroupAccess(ctx, *m)
-	if err != nil {
-		return diag.Errorf(err.Error())
+	timeout, diags := plan.Timeouts.Update(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	vaultGroupAccessToData(*m, data)
+	m := dataToVaultGroupAccess(plan)
+	err := r.p.repo.EnsureVaultGroupAccess(ctx, m)
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating group access", err.Error())
+		return
+	}
 
-	return diags
+	resp.Diagnostics.Append(resp.State.Set(ctx, vaultGroupAccessToData(m, plan.Permissions, plan.Timeouts))...)
 }
 
-func resourceVaultGroupAccessDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	p := meta.(ProviderConfig)
-	var diags diag.Diagnostics
-	if !p.configured {
-		return diag.Errorf("Provider not configured:" + "The provider hasn't been configured before apply.")
+func (r *vaultGroupAccessResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
+	}
+
+	var state VaultGroupAccess
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := state.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	// Get group access.
-	id := data.Id()
+	id := state.ID.ValueString()
 	vaultID, groupID, err := unpackVaultGroupAccessID(id)
 	if err != nil {
-		return diag.Errorf("Error getting member ID: " + "Could not get member ID:" + err.Error())
+		resp.Diagnostics.AddError("Error getting group access ID", "Could not get group access ID: "+err.Error())
+		return
 	}
 
-	err = p.repo.DeleteVaultGroupAccess(ctx, vaultID, groupID)
+	err = r.p.repo.DeleteVaultGroupAccess(ctx, vaultID, groupID)
 	if err != nil {
-		return diag.Errorf("Error reading group access:" + fmt.Sprintf("Could not get group access %q, unexpected error: %s", id, err.Error()))
+		resp.Diagnostics.AddError("Error deleting group access", fmt.Sprintf("Could not delete group access %q, unexpected error: %s", id, err.Error()))
+		return
 	}
-
-	return diags
 }
 
-func dataToVaultGroupAccess(data *schema.ResourceData) (*model.VaultGroupAccess, error) {
-	groupID := data.Get("group_id").(string)
-	vaultID := data.Get("vault_id").(string)
-
-	// Check the ID is correct.
-	if data.Id() != "" {
-		vid, gid, err := unpackVaultGroupAccessID(data.Id())
-		if err != nil {
-			return nil, err
-		}
-
-		if gid != groupID {
-			return nil, fmt.Errorf("resource id is wrong based on group ID")
-		}
-
-		if vid != vaultID {
-			return nil, fmt.Errorf("resource id is wrong based on vault ID")
-		}
+func (r *vaultGroupAccessResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	vaultID, groupID, err := unpackVaultGroupAccessID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error importing group access", err.Error())
+		return
 	}
-	permissions := data.Get("permissions").([]interface{})
 
-	return &model.VaultGroupAccess{
-		VaultID:     vaultID,
-		GroupID:     groupID,
-		Permissions: dataToAccessPermissions(permissions[0].(map[string]interface{})),
-	}, nil
+	// The permissions are set by the read after the import.
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vault_id"), vaultID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("group_id"), groupID)...)
 }
 
-func vaultGroupAccessToData(m model.VaultGroupAccess, data *schema.ResourceData) error {
-	id := packVaultGroupAccessID(m.VaultID, m.GroupID)
+func dataToVaultGroupAccess(data VaultGroupAccess) model.VaultGroupAccess {
+	return model.VaultGroupAccess{
+		VaultID:     data.VaultID.ValueString(),
+		GroupID:     data.GroupID.ValueString(),
+		Permissions: dataToAccessPermissions(data.Permissions),
+	}
+}
 
-	data.SetId(id)
-	data.Set("group_id", m.GroupID)
-	data.Set("vault_id", m.VaultID)
-	data.Set("permissions", [1]map[string]interface{}{accessPermissionsToData(m.Permissions)})
-	return nil
+func vaultGroupAccessToData(m model.VaultGroupAccess, priorPermissions []AccessPermissions, t timeouts.Value) VaultGroupAccess {
+	return VaultGroupAccess{
+		ID:          types.StringValue(packVaultGroupAccessID(m.VaultID, m.GroupID)),
+		VaultID:     types.StringValue(m.VaultID),
+		GroupID:     types.StringValue(m.GroupID),
+		Permissions: accessPermissionsToData(m.Permissions, priorPermissions),
+		Timeouts:    t,
+	}
 }
 
 func packVaultGroupAccessID(vaultID, groupID string) string {
@@ -6,9 +6,12 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestAccVaultGroupAccessDelete will check a vault group access is created and deleted.
@@ -295,6 +298,22 @@ resource "onepasswordorg_vault_group_access" "test" {
 			},
 		},
 
+		"Multiple permissions blocks should fail.": {
+			config: `
+resource "onepasswordorg_vault_group_access" "test" {
+  vault_id  = "test-vault-id"
+  group_id = "test-group-id"
+  permissions {
+	allow_viewing = true
+  }
+  permissions {
+	allow_editing = true
+  }
+}
+`,
+			expErr: regexp.MustCompile(`Only one permissions block can be set`),
+		},
+
 		"Permission manage_vault check.": {
 			config: `
 resource "onepasswordorg_vault_group_access" "test" {
@@ -349,9 +368,9 @@ resource "onepasswordorg_vault_group_access" "test" {
 
 			// Execute test.
 			resource.Test(t, resource.TestCase{
-				PreCheck:     func() { testAccPreCheck(t) },
-				Providers:    testAccProviders,
-				CheckDestroy: assertVaultGroupAccessDeletedOnFakeStorage(t, test.expVGA.VaultID, test.expVGA.GroupID),
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy:             assertVaultGroupAccessDeletedOnFakeStorage(t, test.expVGA.VaultID, test.expVGA.GroupID),
 				Steps: []resource.TestStep{
 					{
 						Config:      test.config,
@@ -417,8 +436,8 @@ resource "onepasswordorg_vault_group_access" "test" {
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: configCreate,
@@ -432,6 +451,68 @@ resource "onepasswordorg_vault_group_access" "test" {
 					assertVaultGroupAccessOnFakeStorage(t, &expVGAUpdate),
 				),
 			},
+			{
+				ResourceName:      "onepasswordorg_vault_group_access.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// TestVaultGroupAccessSDKv2State will check the state of the SDKv2 resource, with the permissions as a
+// list block, is used as it is.
+func TestVaultGroupAccessSDKv2State(t *testing.T) {
+	state := upgradeResourceState(t, "onepasswordorg_vault_group_access", 0, `{
+  "id": "test-vault-id/test-group-id",
+  "vault_id": "test-vault-id",
+  "group_id": "test-group-id",
+  "permissions": [{"allow_viewing": true, "allow_editing": true, "allow_managing": false, "view_items": false, "create_items": false, "edit_items": false, "archive_items": false, "delete_items": false, "view_and_copy_passwords": false, "view_item_history": false, "import_items": false, "export_items": false, "copy_and_share_items": false, "print_items": false, "manage_vault": false}],
+  "timeouts": null
+}`)
+
+	assert := assert.New(t)
+	var permissions []tftypes.Value
+	require.NoError(t, getStateAttribute(t, state, "permissions").As(&permissions))
+	require.Len(t, permissions, 1)
+	assert.Equal(tftypes.NewValue(tftypes.String, "test-vault-id/test-group-id"), getStateAttribute(t, state, "id"))
+	assert.Equal(tftypes.NewValue(tftypes.String, "test-group-id"), getStateAttribute(t, state, "group_id"))
+	assert.Equal(tftypes.NewValue(tftypes.Bool, true), getStateAttribute(t, permissions[0], "allow_viewing"))
+	assert.Equal(tftypes.NewValue(tftypes.Bool, true), getStateAttribute(t, permissions[0], "allow_editing"))
+	assert.Equal(tftypes.NewValue(tftypes.Bool, false), getStateAttribute(t, permissions[0], "manage_vault"))
+}
+
+// TestAccVaultGroupAccessWithoutPermissions will check the permissions block is optional, without
+// permissions granted.
+func TestAccVaultGroupAccessWithoutPermissions(t *testing.T) {
+	// Prepare fake storage.
+	path, delete := getFakeRepoTmpFile("TestAccVaultGroupAccessWithoutPermissions")
+	defer delete()
+	_ = os.Setenv(provider.EnvVarOpFakeStoragePath, path)
+
+	config := `
+resource "onepasswordorg_vault_group_access" "test" {
+  vault_id = "test-vault-id"
+  group_id  = "test-group-id"
+}`
+
+	expVGA := model.VaultGroupAccess{
+		VaultID: "test-vault-id",
+		GroupID: "test-group-id",
+	}
+
+	// Execute test.
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					assertVaultGroupAccessOnFakeStorage(t, &expVGA),
+					resource.TestCheckResourceAttr("onepasswordorg_vault_group_access.test", "permissions.#", "0"),
+				),
+			},
 		},
 	})
 }
@@ -26,7 +26,6 @@ resource "onepasswordorg_vault" "test" {
 }
 `,
 			expVault: model.Vault{
-				ID:          "test-vault",
 				Name:        "test-vault",
 				Description: "Test vault",
 			},
@@ -40,7 +39,6 @@ resource "onepasswordorg_vault" "test" {
 }
 		`,
 			expVault: model.Vault{
-				ID:          "test-vault",
 				Name:        "test-vault",
 				Description: "Managed by Terraform",
 			},
@@ -77,8 +75,7 @@ resource "onepasswordorg_vault" "test" {
 			var checks resource.TestCheckFunc
 			if test.expErr == nil {
 				checks = resource.ComposeAggregateTestCheckFunc(
-					assertVaultOnFakeStorage(t, &test.expVault),
-					resource.TestCheckResourceAttr("onepasswordorg_vault.test", "id", test.expVault.ID),
+					assertVaultOnFakeStorage(t, "onepasswordorg_vault.test", &test.expVault),
 					resource.TestCheckResourceAttr("onepasswordorg_vault.test", "name", test.expVault.Name),
 					resource.TestCheckResourceAttr("onepasswordorg_vault.test", "description", test.expVault.Description),
 				)
@@ -86,9 +83,9 @@ resource "onepasswordorg_vault" "test" {
 
 			// Execute test.
 			resource.Test(t, resource.TestCase{
-				PreCheck:     func() { testAccPreCheck(t) },
-				Providers:    testAccProviders,
-				CheckDestroy: assertVaultDeletedOnFakeStorage(t, test.expVault.ID),
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy:             assertVaultDeletedOnFakeStorage(t, test.expVault.Name),
 				Steps: []resource.TestStep{
 					{
 						Config:      test.config,
@@ -123,35 +120,31 @@ resource "onepasswordorg_vault" "test" {
 }
 `
 
-	// Fake repo IDs are based on emails.
 	expVaultCreate := model.Vault{
-		ID:          "test-vault",
 		Name:        "test-vault",
 		Description: "Test vault",
 	}
 
 	expVaultUpdate := model.Vault{
-		ID:          "test-vault",
 		Name:        "test-vault",
 		Description: "Test vault modified",
 	}
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: configCreate,
 				Check: resource.ComposeAggregateTestCheckFunc(
-					assertVaultOnFakeStorage(t, &expVaultCreate),
+					assertVaultOnFakeStorage(t, "onepasswordorg_vault.test", &expVaultCreate),
 				),
 			},
 			{
 				Config: configUpdate,
 				Check: resource.ComposeAggregateTestCheckFunc(
-					assertVaultOnFakeStorage(t, &expVaultUpdate),
-					resource.TestCheckResourceAttr("onepasswordorg_vault.test", "id", "test-vault"), // Fake uses name as IDs.
+					assertVaultOnFakeStorage(t, "onepasswordorg_vault.test", &expVaultUpdate),
 					resource.TestCheckResourceAttr("onepasswordorg_vault.test", "name", "test-vault"),
 					resource.TestCheckResourceAttr("onepasswordorg_vault.test", "description", "Test vault modified"),
 				),
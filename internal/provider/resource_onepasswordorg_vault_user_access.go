@@ -2,175 +2,244 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 )
 
-func resourceVaultUserAccess() *schema.Resource {
-	return &schema.Resource{
+type vaultUserAccessResource struct {
+	p ProviderConfig
+}
+
+var (
+	_ resource.ResourceWithConfigure   = &vaultUserAccessResource{}
+	_ resource.ResourceWithImportState = &vaultUserAccessResource{}
+)
+
+func newVaultUserAccessResource() resource.Resource {
+	return &vaultUserAccessResource{}
+}
+
+func (r *vaultUserAccessResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_vault_user_access"
+}
+
+func (r *vaultUserAccessResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
 		Description: `
 Provides vault access for a user.
     `,
-		CreateContext: resourceVaultUserAccessCreate,
-		ReadContext:   resourceVaultUserAccessRead,
-		UpdateContext: resourceVaultUserAccessUpdate,
-		DeleteContext: resourceVaultUserAccessDelete,
-
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
-		Schema: map[string]*schema.Schema{
-			"id": {
-				Type:     schema.TypeString,
-				Computed: true,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:      true,
+				Description:   "The ID of this resource.",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
-			"vault_id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "The vault ID.",
+			"vault_id": schema.StringAttribute{
+				Required:      true,
+				Description:   "The vault ID.",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
 			},
-			"user_id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "The user ID.",
+			"user_id": schema.StringAttribute{
+				Required:      true,
+				Description:   "The user ID.",
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
 			},
-			"permissions": permissionsAttribute,
+		},
+		Blocks: map[string]schema.Block{
+			"permissions": permissionsBlock(),
+			"timeouts":    timeouts.Block(ctx, timeouts.Opts{Create: true, Read: true, Update: true, Delete: true}),
 		},
 	}
 }
 
-func resourceVaultUserAccessCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	p := meta.(ProviderConfig)
-	var diags diag.Diagnostics
-	if !p.configured {
-		return diag.Errorf("Provider not configured:" + "The provider hasn't been configured before apply.")
+func (r *vaultUserAccessResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Not configured yet (e.g: validation).
+	if req.ProviderData == nil {
+		return
 	}
 
-	m, err := dataToVaultUserAccess(data)
-	if err != nil {
-		return diag.Errorf(err.Error())
+	r.p = req.ProviderData.(ProviderConfig)
+}
+
+func (r *vaultUserAccessResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
 	}
 
-	err = p.repo.EnsureVaultUserAccess(ctx, *m)
-	if err != nil {
-		return diag.Errorf(err.Error())
+	var plan VaultUserAccess
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	vaultUserAccessToData(*m, data)
+	timeout, diags := plan.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	return diags
+	m := dataToVaultUserAccess(plan)
+	err := r.p.repo.EnsureVaultUserAccess(ctx, m)
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating user access", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, vaultUserAccessToData(m, plan.Permissions, plan.Timeouts))...)
 }
 
-func resourceVaultUserAccessRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	p := meta.(ProviderConfig)
-	var diags diag.Diagnostics
-	if !p.configured {
-		return diag.Errorf("Provider not configured:" + "The provider hasn't been configured before apply.")
+func (r *vaultUserAccessResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
+	}
+
+	var state VaultUserAccess
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := state.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	id := data.Id()
+	id := state.ID.ValueString()
 	vaultID, userID, err := unpackVaultUserAccessID(id)
 	if err != nil {
-		return diag.Errorf("Error getting member ID: " + "Could not get member ID:" + err.Error())
+		resp.Diagnostics.AddError("Error getting user access ID", "Could not get user access ID: "+err.Error())
+		return
 	}
 
-	vaultUserAccess, err := p.repo.GetVaultUserAccessByID(ctx, vaultID, userID)
+	vaultUserAccess, err := r.p.repo.GetVaultUserAccessByID(ctx, vaultID, userID)
 	if err != nil {
-		return diag.Errorf("Error reading user access:" + fmt.Sprintf("Could not get user access %q, unexpected error: %s", id, err.Error()))
+		// Missing vault user access means it has been deleted outside Terraform, remove it from the state so it's planned again.
+		if errors.Is(err, storage.ErrNotFound) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError("Error reading user access", fmt.Sprintf("Could not get user access %q, unexpected error: %s", id, err.Error()))
+		return
 	}
 
-	vaultUserAccessToData(*vaultUserAccess, data)
-	return diags
+	resp.Diagnostics.Append(resp.State.Set(ctx, vaultUserAccessToData(*vaultUserAccess, state.Permissions, state.Timeouts))...)
 }
 
-func resourceVaultUserAccessUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	p := meta.(ProviderConfig)
-	var diags diag.Diagnostics
-	if !p.configured {
-		return diag.Errorf("Provider not configured:" + "The provider hasn't been configured before apply.")
+func (r *vaultUserAccessResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
 	}
 
-	m, err := dataToVaultUserAccess(data)
-	if err != nil {
-		return diag.Errorf(err.Error())
+	var plan VaultUserAccess
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	err = p.repo.EnsureVaultUserAccess(ctx, *m)
-	if err != nil {
-		return diag.Errorf(err.Error())
+	timeout, diags := plan.Timeouts.Update(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	vaultUserAccessToData(*m, data)
+	m := dataToVaultUserAccess(plan)
+	err := r.p.repo.EnsureVaultUserAccess(ctx, m)
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating user access", err.Error())
+		return
+	}
 
-	return diags
+	resp.Diagnostics.Append(resp.State.Set(ctx, vaultUserAccessToData(m, plan.Permissions, plan.Timeouts))...)
 }
 
-func resourceVaultUserAccessDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	p := meta.(ProviderConfig)
-	var diags diag.Diagnostics
-	if !p.configured {
-		return diag.Errorf("Provider not configured:" + "The provider hasn't been configured before apply.")
+func (r *vaultUserAccessResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
 	}
 
-	// Get user access.
-	id := data.Id()
+	var state VaultUserAccess
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := state.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	id := state.ID.ValueString()
 	vaultID, userID, err := unpackVaultUserAccessID(id)
 	if err != nil {
-		return diag.Errorf("Error getting member ID: " + "Could not get member ID:" + err.Error())
+		resp.Diagnostics.AddError("Error getting user access ID", "Could not get user access ID: "+err.Error())
+		return
 	}
 
-	err = p.repo.DeleteVaultUserAccess(ctx, vaultID, userID)
+	err = r.p.repo.DeleteVaultUserAccess(ctx, vaultID, userID)
 	if err != nil {
-		return diag.Errorf("Error reading user access:" + fmt.Sprintf("Could not get user access %q, unexpected error: %s", id, err.Error()))
+		resp.Diagnostics.AddError("Error deleting user access", fmt.Sprintf("Could not delete user access %q, unexpected error: %s", id, err.Error()))
+		return
 	}
-
-	return diags
 }
 
-func dataToVaultUserAccess(data *schema.ResourceData) (*model.VaultUserAccess, error) {
-	userID := data.Get("user_id").(string)
-	vaultID := data.Get("vault_id").(string)
-
-	// Check the ID is correct.
-	if data.Id() != "" {
-		vid, gid, err := unpackVaultUserAccessID(data.Id())
-		if err != nil {
-			return nil, err
-		}
-
-		if gid != userID {
-			return nil, fmt.Errorf("resource id is wrong based on user ID")
-		}
-
-		if vid != vaultID {
-			return nil, fmt.Errorf("resource id is wrong based on vault ID")
-		}
+func (r *vaultUserAccessResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	vaultID, userID, err := unpackVaultUserAccessID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error importing user access", err.Error())
+		return
 	}
-	permissions := data.Get("permissions").([]interface{})
 
-	return &model.VaultUserAccess{
-		VaultID:     vaultID,
-		UserID:      userID,
-		Permissions: dataToAccessPermissions(permissions[0].(map[string]interface{})),
-	}, nil
+	// The permissions are set by the read after the import.
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vault_id"), vaultID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user_id"), userID)...)
 }
 
-func vaultUserAccessToData(m model.VaultUserAccess, data *schema.ResourceData) error {
-	id := packVaultUserAccessID(m.VaultID, m.UserID)
+func dataToVaultUserAccess(data VaultUserAccess) model.VaultUserAccess {
+	return model.VaultUserAccess{
+		VaultID:     data.VaultID.ValueString(),
+		UserID:      data.UserID.ValueString(),
+		Permissions: dataToAccessPermissions(data.Permissions),
+	}
+}
 
-	data.SetId(id)
-	data.Set("user_id", m.UserID)
-	data.Set("vault_id", m.VaultID)
-	data.Set("permissions", [1]map[string]interface{}{accessPermissionsToData(m.Permissions)})
-	return nil
+func vaultUserAccessToData(m model.VaultUserAccess, priorPermissions []AccessPermissions, t timeouts.Value) VaultUserAccess {
+	return VaultUserAccess{
+		ID:          types.StringValue(packVaultUserAccessID(m.VaultID, m.UserID)),
+		VaultID:     types.StringValue(m.VaultID),
+		UserID:      types.StringValue(m.UserID),
+		Permissions: accessPermissionsToData(m.Permissions, priorPermissions),
+		Timeouts:    t,
+	}
 }
+
 func packVaultUserAccessID(vaultID, userID string) string {
 	return vaultID + "/" + userID
 }
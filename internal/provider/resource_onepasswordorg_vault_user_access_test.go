@@ -6,9 +6,12 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestAccVaultUserAccessDelete will check a vault user access is created and deleted.
@@ -295,6 +298,22 @@ resource "onepasswordorg_vault_user_access" "test" {
 			},
 		},
 
+		"Multiple permissions blocks should fail.": {
+			config: `
+resource "onepasswordorg_vault_user_access" "test" {
+  vault_id  = "test-vault-id"
+  user_id = "test-user-id"
+  permissions {
+	allow_viewing = true
+  }
+  permissions {
+	allow_editing = true
+  }
+}
+`,
+			expErr: regexp.MustCompile(`Only one permissions block can be set`),
+		},
+
 		"Permission manage_vault check.": {
 			config: `
 resource "onepasswordorg_vault_user_access" "test" {
@@ -349,9 +368,9 @@ resource "onepasswordorg_vault_user_access" "test" {
 
 			// Execute test.
 			resource.Test(t, resource.TestCase{
-				PreCheck:     func() { testAccPreCheck(t) },
-				Providers:    testAccProviders,
-				CheckDestroy: assertVaultUserAccessDeletedOnFakeStorage(t, test.expVGA.VaultID, test.expVGA.UserID),
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy:             assertVaultUserAccessDeletedOnFakeStorage(t, test.expVGA.VaultID, test.expVGA.UserID),
 				Steps: []resource.TestStep{
 					{
 						Config:      test.config,
@@ -417,8 +436,8 @@ resource "onepasswordorg_vault_user_access" "test" {
 
 	// Execute test.
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testAccPreCheck(t) },
-		Providers: testAccProviders,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: configCreate,
@@ -432,6 +451,68 @@ resource "onepasswordorg_vault_user_access" "test" {
 					assertVaultUserAccessOnFakeStorage(t, &expVGAUpdate),
 				),
 			},
+			{
+				ResourceName:      "onepasswordorg_vault_user_access.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// TestVaultUserAccessSDKv2State will check the state of the SDKv2 resource, with the permissions as a
+// list block, is used as it is.
+func TestVaultUserAccessSDKv2State(t *testing.T) {
+	state := upgradeResourceState(t, "onepasswordorg_vault_user_access", 0, `{
+  "id": "test-vault-id/test-user-id",
+  "vault_id": "test-vault-id",
+  "user_id": "test-user-id",
+  "permissions": [{"allow_viewing": true, "allow_editing": true, "allow_managing": false, "view_items": false, "create_items": false, "edit_items": false, "archive_items": false, "delete_items": false, "view_and_copy_passwords": false, "view_item_history": false, "import_items": false, "export_items": false, "copy_and_share_items": false, "print_items": false, "manage_vault": false}],
+  "timeouts": null
+}`)
+
+	assert := assert.New(t)
+	var permissions []tftypes.Value
+	require.NoError(t, getStateAttribute(t, state, "permissions").As(&permissions))
+	require.Len(t, permissions, 1)
+	assert.Equal(tftypes.NewValue(tftypes.String, "test-vault-id/test-user-id"), getStateAttribute(t, state, "id"))
+	assert.Equal(tftypes.NewValue(tftypes.String, "test-user-id"), getStateAttribute(t, state, "user_id"))
+	assert.Equal(tftypes.NewValue(tftypes.Bool, true), getStateAttribute(t, permissions[0], "allow_viewing"))
+	assert.Equal(tftypes.NewValue(tftypes.Bool, true), getStateAttribute(t, permissions[0], "allow_editing"))
+	assert.Equal(tftypes.NewValue(tftypes.Bool, false), getStateAttribute(t, permissions[0], "manage_vault"))
+}
+
+// TestAccVaultUserAccessWithoutPermissions will check the permissions block is optional, without
+// permissions granted.
+func TestAccVaultUserAccessWithoutPermissions(t *testing.T) {
+	// Prepare fake storage.
+	path, delete := getFakeRepoTmpFile("TestAccVaultUserAccessWithoutPermissions")
+	defer delete()
+	_ = os.Setenv(provider.EnvVarOpFakeStoragePath, path)
+
+	config := `
+resource "onepasswordorg_vault_user_access" "test" {
+  vault_id = "test-vault-id"
+  user_id  = "test-user-id"
+}`
+
+	expVUA := model.VaultUserAccess{
+		VaultID: "test-vault-id",
+		UserID:  "test-user-id",
+	}
+
+	// Execute test.
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					assertVaultUserAccessOnFakeStorage(t, &expVUA),
+					resource.TestCheckResourceAttr("onepasswordorg_vault_user_access.test", "permissions.#", "0"),
+				),
+			},
 		},
 	})
 }
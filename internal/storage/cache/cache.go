@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+// NewRepository returns a storage.Repository that memoizes the list style calls of the wrapped
+// repository (group members, vault group/user accesses and user vaults) for its whole lifetime.
+//
+// Concurrent calls for the same list are deduplicated so only one of them reaches the wrapped
+// repository, and any mutation of a group or vault invalidates its cached lists. Errors are not cached.
+//
+// Group members and vault accesses are only cached when the wrapped repository implements
+// `storage.GroupMembershipLister`, `storage.VaultGroupAccessLister` and `storage.VaultUserAccessLister`,
+// otherwise the calls are passed through.
+func NewRepository(repo storage.Repository) (storage.Repository, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("repository is required")
+	}
+
+	return &repository{
+		Repository: repo,
+		cache:      map[string]interface{}{},
+		gens:       map[string]uint64{},
+	}, nil
+}
+
+// Cache key prefixes, the ID of the group, vault or user is appended.
+const (
+	keyPrefixGroupMembers       = "group-members/"
+	keyPrefixVaultGroupAccesses = "vault-group-accesses/"
+	keyPrefixVaultUserAccesses  = "vault-user-accesses/"
+	keyPrefixUserVaults         = "user-vaults/"
+)
+
+type repository struct {
+	storage.Repository
+
+	calls singleflight.Group
+	mu    sync.Mutex
+	cache map[string]interface{}
+	// gens track the invalidations of every key, so in flight calls started before an
+	// invalidation don't store stale data.
+	gens map[string]uint64
+}
+
+// get returns the cached value of the key, if missing it will call fn (only once for all
+// the concurrent calls of the same key) and cache the result.
+//
+// fn is shared by all the callers, so it runs without the cancellation of the caller that
+// started it, and every caller stops waiting when its own context is done.
+func (r *repository) get(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	r.mu.Lock()
+	if v, ok := r.cache[key]; ok {
+		r.mu.Unlock()
+		return v, nil
+	}
+	r.mu.Unlock()
+
+	fnCtx := context.WithoutCancel(ctx)
+	ch := r.calls.DoChan(key, func() (interface{}, error) {
+		r.mu.Lock()
+		gen := r.gens[key]
+		r.gens[key] = gen
+		r.mu.Unlock()
+
+		v, err := fn(fnCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		r.mu.Lock()
+		if r.gens[key] == gen {
+			r.cache[key] = v
+		}
+		r.mu.Unlock()
+
+		return v, nil
+	})
+
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// invalidate removes the cached values of the keys, keys ending with `/` are prefixes and
+// will invalidate all the keys that start with it.
+func (r *repository) invalidate(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k := range r.gens {
+		if !matchesAny(k, keys) {
+			continue
+		}
+
+		delete(r.cache, k)
+		r.gens[k]++
+		// New calls shouldn't wait for an in flight call that could return stale data.
+		r.calls.Forget(k)
+	}
+}
+
+func matchesAny(key string, keys []string) bool {
+	for _, k := range keys {
+		if key == k || (strings.HasSuffix(k, "/") && strings.HasPrefix(key, k)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *repository) DeleteUser(ctx context.Context, id string) error {
+	err := r.Repository.DeleteUser(ctx, id)
+	// Deleting a user removes it from every group and vault.
+	r.invalidate(keyPrefixGroupMembers, keyPrefixVaultUserAccesses, keyPrefixUserVaults)
+	return err
+}
+
+func (r *repository) DeleteGroup(ctx context.Context, id string) error {
+	err := r.Repository.DeleteGroup(ctx, id)
+	// Deleting a group removes it from every vault.
+	r.invalidate(keyPrefixGroupMembers+id, keyPrefixVaultGroupAccesses, keyPrefixUserVaults)
+	return err
+}
+
+func (r *repository) CreateVault(ctx context.Context, vault model.Vault) (*model.Vault, error) {
+	v, err := r.Repository.CreateVault(ctx, vault)
+	// The creator of the vault has access to it.
+	r.invalidate(keyPrefixUserVaults)
+	return v, err
+}
+
+func (r *repository) DeleteVault(ctx context.Context, id string) error {
+	err := r.Repository.DeleteVault(ctx, id)
+	r.invalidate(keyPrefixVaultGroupAccesses+id, keyPrefixVaultUserAccesses+id, keyPrefixUserVaults)
+	return err
+}
+
+func (r *repository) ListVaultsByUser(ctx context.Context, userID string) (*[]model.Vault, error) {
+	v, err := r.get(ctx, keyPrefixUserVaults+userID, func(ctx context.Context) (interface{}, error) {
+		return r.Repository.ListVaultsByUser(ctx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy so the callers can't modify the cached data.
+	vaults := append([]model.Vault{}, *(v.(*[]model.Vault))...)
+	return &vaults, nil
+}
+
+func (r *repository) EnsureMembership(ctx context.Context, membership model.Membership) error {
+	err := r.Repository.EnsureMembership(ctx, membership)
+	// Users can access vaults through their groups.
+	r.invalidate(keyPrefixGroupMembers+membership.GroupID, keyPrefixUserVaults)
+	return err
+}
+
+func (r *repository) DeleteMembership(ctx context.Context, membership model.Membership) error {
+	err := r.Repository.DeleteMembership(ctx, membership)
+	r.invalidate(keyPrefixGroupMembers+membership.GroupID, keyPrefixUserVaults)
+	return err
+}
+
+func (r *repository) GetMembershipByID(ctx context.Context, groupID, userID string) (*model.Membership, error) {
+	lister, ok := r.Repository.(storage.GroupMembershipLister)
+	if !ok {
+		return r.Repository.GetMembershipByID(ctx, groupID, userID)
+	}
+
+	v, err := r.get(ctx, keyPrefixGroupMembers+groupID, func(ctx context.Context) (interface{}, error) {
+		return lister.ListMembershipsByGroup(ctx, groupID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range *(v.(*[]model.Membership)) {
+		if m.UserID == userID {
+			if m.Role == model.MembershipRoleUnknown {
+				return nil, fmt.Errorf("member %q in group %q has an unknown role", userID, groupID)
+			}
+			return &m, nil
+		}
+	}
+
+	return nil, fmt.Errorf("member %q in group %q: %w", userID, groupID, storage.ErrNotFound)
+}
+
+func (r *repository) EnsureVaultGroupAccess(ctx context.Context, groupAccess model.VaultGroupAccess) error {
+	err := r.Repository.EnsureVaultGroupAccess(ctx, groupAccess)
+	r.invalidate(keyPrefixVaultGroupAccesses+groupAccess.VaultID, keyPrefixUserVaults)
+	return err
+}
+
+func (r *repository) DeleteVaultGroupAccess(ctx context.Context, vaultID string, groupID string) error {
+	err := r.Repository.DeleteVaultGroupAccess(ctx, vaultID, groupID)
+	r.invalidate(keyPrefixVaultGroupAccesses+vaultID, keyPrefixUserVaults)
+	return err
+}
+
+func (r *repository) GetVaultGroupAccessByID(ctx context.Context, vaultID string, groupID string) (*model.VaultGroupAccess, error) {
+	lister, ok := r.Repository.(storage.VaultGroupAccessLister)
+	if !ok {
+		return r.Repository.GetVaultGroupAccessByID(ctx, vaultID, groupID)
+	}
+
+	v, err := r.get(ctx, keyPrefixVaultGroupAccesses+vaultID, func(ctx context.Context) (interface{}, error) {
+		return lister.ListVaultGroupAccessesByVault(ctx, vaultID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range *(v.(*[]model.VaultGroupAccess)) {
+		if a.GroupID == groupID {
+			return &a, nil
+		}
+	}
+
+	return nil, fmt.Errorf("group access %q in vault %q: %w", groupID, vaultID, storage.ErrNotFound)
+}
+
+func (r *repository) EnsureVaultUserAccess(ctx context.Context, userAccess model.VaultUserAccess) error {
+	err := r.Repository.EnsureVaultUserAccess(ctx, userAccess)
+	r.invalidate(keyPrefixVaultUserAccesses+userAccess.VaultID, keyPrefixUserVaults+userAccess.UserID)
+	return err
+}
+
+func (r *repository) DeleteVaultUserAccess(ctx context.Context, vaultID string, userID string) error {
+	err := r.Repository.DeleteVaultUserAccess(ctx, vaultID, userID)
+	r.invalidate(keyPrefixVaultUserAccesses+vaultID, keyPrefixUserVaults+userID)
+	return err
+}
+
+func (r *repository) GetVaultUserAccessByID(ctx context.Context, vaultID string, userID string) (*model.VaultUserAccess, error) {
+	lister, ok := r.Repository.(storage.VaultUserAccessLister)
+	if !ok {
+		return r.Repository.GetVaultUserAccessByID(ctx, vaultID, userID)
+	}
+
+	v, err := r.get(ctx, keyPrefixVaultUserAccesses+vaultID, func(ctx context.Context) (interface{}, error) {
+		return lister.ListVaultUserAccessesByVault(ctx, vaultID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range *(v.(*[]model.VaultUserAccess)) {
+		if a.UserID == userID {
+			return &a, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user access %q in vault %q: %w", userID, vaultID, storage.ErrNotFound)
+}
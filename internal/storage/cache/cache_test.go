@@ -0,0 +1,225 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/cache"
+)
+
+// listerRepository is a repository that counts the list calls made to it.
+type listerRepository struct {
+	storage.Repository
+
+	listCalls int32
+	listWait  time.Duration
+	listErr   error
+	members   []model.Membership
+}
+
+func (l *listerRepository) ListMembershipsByGroup(ctx context.Context, groupID string) (*[]model.Membership, error) {
+	atomic.AddInt32(&l.listCalls, 1)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(l.listWait):
+	}
+	if l.listErr != nil {
+		return nil, l.listErr
+	}
+
+	ms := append([]model.Membership{}, l.members...)
+	return &ms, nil
+}
+
+func (l *listerRepository) EnsureMembership(ctx context.Context, membership model.Membership) error {
+	l.members = append(l.members, membership)
+	return nil
+}
+
+func (l *listerRepository) ListVaultsByUser(ctx context.Context, userID string) (*[]model.Vault, error) {
+	atomic.AddInt32(&l.listCalls, 1)
+	return &[]model.Vault{{ID: "vault-0"}}, nil
+}
+
+func (l *listerRepository) EnsureVaultUserAccess(ctx context.Context, userAccess model.VaultUserAccess) error {
+	return nil
+}
+
+func TestRepository(t *testing.T) {
+	tests := map[string]struct {
+		repo         *listerRepository
+		exec         func(r storage.Repository) error
+		expListCalls int32
+		expErr       bool
+		expNotFound  bool
+	}{
+		"Getting multiple members of the same group should list the group only once.": {
+			repo: &listerRepository{members: []model.Membership{{GroupID: "g0", UserID: "u0"}, {GroupID: "g0", UserID: "u1"}}},
+			exec: func(r storage.Repository) error {
+				for _, u := range []string{"u0", "u1", "u0"} {
+					if _, err := r.GetMembershipByID(context.TODO(), "g0", u); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			expListCalls: 1,
+		},
+
+		"Getting members of different groups should list each group.": {
+			repo: &listerRepository{members: []model.Membership{{GroupID: "g0", UserID: "u0"}}},
+			exec: func(r storage.Repository) error {
+				_, _ = r.GetMembershipByID(context.TODO(), "g0", "u0")
+				_, _ = r.GetMembershipByID(context.TODO(), "g1", "u0")
+				return nil
+			},
+			expListCalls: 2,
+		},
+
+		"Getting a missing member should fail with not found without listing again.": {
+			repo: &listerRepository{members: []model.Membership{{GroupID: "g0", UserID: "u0"}}},
+			exec: func(r storage.Repository) error {
+				_, _ = r.GetMembershipByID(context.TODO(), "g0", "u0")
+				_, err := r.GetMembershipByID(context.TODO(), "g0", "u1")
+				return err
+			},
+			expListCalls: 1,
+			expErr:       true,
+			expNotFound:  true,
+		},
+
+		"Concurrent calls for the same group should be deduplicated.": {
+			repo: &listerRepository{listWait: 50 * time.Millisecond, members: []model.Membership{{GroupID: "g0", UserID: "u0"}}},
+			exec: func(r storage.Repository) error {
+				var wg sync.WaitGroup
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, _ = r.GetMembershipByID(context.TODO(), "g0", "u0")
+					}()
+				}
+				wg.Wait()
+				return nil
+			},
+			expListCalls: 1,
+		},
+
+		"Canceling the call that started a shared list should not fail the other concurrent calls.": {
+			repo: &listerRepository{listWait: 50 * time.Millisecond, members: []model.Membership{{GroupID: "g0", UserID: "u0"}}},
+			exec: func(r storage.Repository) error {
+				ctx, cancel := context.WithCancel(context.TODO())
+				canceledErr := make(chan error)
+				go func() {
+					_, err := r.GetMembershipByID(ctx, "g0", "u0")
+					canceledErr <- err
+				}()
+				time.Sleep(10 * time.Millisecond)
+
+				errC := make(chan error)
+				go func() {
+					_, err := r.GetMembershipByID(context.TODO(), "g0", "u0")
+					errC <- err
+				}()
+				time.Sleep(10 * time.Millisecond)
+				cancel()
+
+				if err := <-canceledErr; !errors.Is(err, context.Canceled) {
+					return fmt.Errorf("expected canceled error on the canceled call, got: %w", err)
+				}
+				return <-errC
+			},
+			expListCalls: 1,
+		},
+
+		"Getting a member with an unknown role should fail without failing the other members.": {
+			repo: &listerRepository{members: []model.Membership{{GroupID: "g0", UserID: "u0"}, {GroupID: "g0", UserID: "u1", Role: model.MembershipRoleUnknown}}},
+			exec: func(r storage.Repository) error {
+				// The other members are not affected (returning no error fails the test).
+				if _, err := r.GetMembershipByID(context.TODO(), "g0", "u0"); err != nil {
+					return nil
+				}
+				_, err := r.GetMembershipByID(context.TODO(), "g0", "u1")
+				return err
+			},
+			expListCalls: 1,
+			expErr:       true,
+		},
+
+		"Mutating a group membership should invalidate the group members.": {
+			repo: &listerRepository{members: []model.Membership{{GroupID: "g0", UserID: "u0"}}},
+			exec: func(r storage.Repository) error {
+				_, _ = r.GetMembershipByID(context.TODO(), "g0", "u0")
+				_ = r.EnsureMembership(context.TODO(), model.Membership{GroupID: "g0", UserID: "u1"})
+				_, err := r.GetMembershipByID(context.TODO(), "g0", "u1")
+				return err
+			},
+			expListCalls: 2,
+		},
+
+		"Mutating a different group membership should not invalidate the group members.": {
+			repo: &listerRepository{members: []model.Membership{{GroupID: "g0", UserID: "u0"}}},
+			exec: func(r storage.Repository) error {
+				_, _ = r.GetMembershipByID(context.TODO(), "g0", "u0")
+				_ = r.EnsureMembership(context.TODO(), model.Membership{GroupID: "g1", UserID: "u1"})
+				_, err := r.GetMembershipByID(context.TODO(), "g0", "u0")
+				return err
+			},
+			expListCalls: 1,
+		},
+
+		"Errors should not be cached.": {
+			repo: &listerRepository{listErr: errors.New("whatever")},
+			exec: func(r storage.Repository) error {
+				_, _ = r.GetMembershipByID(context.TODO(), "g0", "u0")
+				_, err := r.GetMembershipByID(context.TODO(), "g0", "u0")
+				return err
+			},
+			expListCalls: 2,
+			expErr:       true,
+		},
+
+		"Listing user vaults should be cached and invalidated by the user vault accesses.": {
+			repo: &listerRepository{},
+			exec: func(r storage.Repository) error {
+				_, _ = r.ListVaultsByUser(context.TODO(), "u0")
+				_, _ = r.ListVaultsByUser(context.TODO(), "u0")
+				_ = r.EnsureVaultUserAccess(context.TODO(), model.VaultUserAccess{VaultID: "vault-1", UserID: "u0"})
+				_, err := r.ListVaultsByUser(context.TODO(), "u0")
+				return err
+			},
+			expListCalls: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			repo, err := cache.NewRepository(test.repo)
+			require.NoError(err)
+
+			err = test.exec(repo)
+
+			if test.expErr {
+				assert.Error(err)
+				assert.Equal(test.expNotFound, errors.Is(err, storage.ErrNotFound))
+			} else {
+				assert.NoError(err)
+			}
+			assert.Equal(test.expListCalls, atomic.LoadInt32(&test.repo.listCalls))
+		})
+	}
+}
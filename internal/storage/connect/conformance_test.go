@@ -0,0 +1,28 @@
+package connect_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/connect"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/storagetest"
+)
+
+func TestRepositoryConformance(t *testing.T) {
+	storagetest.RunRepositoryTests(t, func(t *testing.T) storage.Repository {
+		// Connect can't create vaults, the tests use the existing one.
+		api := newConnectAPI()
+		api.vaults = []map[string]interface{}{{"id": "vault-00", "name": "vault"}}
+		api.items = map[string]map[string]map[string]interface{}{"vault-00": {}}
+		srv := httptest.NewServer(api)
+		t.Cleanup(srv.Close)
+
+		repo, err := connect.NewRepository(connect.RepositoryConfig{URL: srv.URL, Token: testToken})
+		require.NoError(t, err)
+
+		return repo
+	})
+}
@@ -0,0 +1,224 @@
+package connect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+// RepositoryConfig is the configuration of the 1password Connect repository.
+type RepositoryConfig struct {
+	// URL is the 1password Connect server URL (e.g: http://localhost:8080).
+	URL string
+	// Token is the 1password Connect access token.
+	Token string
+	// HTTPClient is the client used to call the Connect API.
+	HTTPClient *http.Client
+}
+
+func (c *RepositoryConfig) defaults() error {
+	if c.URL == "" {
+		return fmt.Errorf("connect url is required")
+	}
+	c.URL = strings.TrimSuffix(c.URL, "/")
+
+	if c.Token == "" {
+		return fmt.Errorf("connect token is required")
+	}
+
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return nil
+}
+
+type repository struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewRepository returns a 1password Connect server based repository.
+//
+// Connect only knows how to manage items and read vaults, the rest of the operations
+// (users, groups, vault management and accesses) will fail with `storage.ErrNotSupported`.
+func NewRepository(config RepositoryConfig) (storage.Repository, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return &repository{
+		url:    config.URL,
+		token:  config.Token,
+		client: config.HTTPClient,
+	}, nil
+}
+
+// connectError is the error returned by the Connect API.
+type connectError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// do makes a request to the Connect API, the body (if any) will be sent as JSON and
+// the response will be unmarshaled into out (if any).
+func (r *repository) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	u := r.url + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("could not marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+r.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("connect api request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("could not read connect api response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		cerr := connectError{}
+		_ = json.Unmarshal(data, &cerr)
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("connect api request failed: %s %s: %w: %s", method, path, storage.ErrNotFound, cerr.Message)
+		}
+
+		return fmt.Errorf("connect api request failed: %s %s: %d: %s", method, path, resp.StatusCode, cerr.Message)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(data, out)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal connect api response: %w", err)
+	}
+
+	return nil
+}
+
+// filterEq returns a Connect API SCIM style equality filter query.
+func filterEq(attribute, value string) url.Values {
+	return url.Values{"filter": []string{fmt.Sprintf(`%s eq "%s"`, attribute, escapeFilterValue(value))}}
+}
+
+var filterValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// escapeFilterValue escapes a value to be used as a SCIM style filter string (a JSON string).
+func escapeFilterValue(value string) string {
+	return filterValueEscaper.Replace(value)
+}
+
+func errNotSupported(resource string) error {
+	return fmt.Errorf("managing %s is unsupported by the 1password Connect backend: %w", resource, storage.ErrNotSupported)
+}
+
+func (r *repository) CreateUser(ctx context.Context, user model.User) (*model.User, error) {
+	return nil, errNotSupported("users")
+}
+
+func (r *repository) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	return nil, errNotSupported("users")
+}
+
+func (r *repository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return nil, errNotSupported("users")
+}
+
+func (r *repository) EnsureUser(ctx context.Context, user model.User) (*model.User, error) {
+	return nil, errNotSupported("users")
+}
+
+func (r *repository) DeleteUser(ctx context.Context, id string) error {
+	return errNotSupported("users")
+}
+
+func (r *repository) CreateGroup(ctx context.Context, group model.Group) (*model.Group, error) {
+	return nil, errNotSupported("groups")
+}
+
+func (r *repository) GetGroupByID(ctx context.Context, id string) (*model.Group, error) {
+	return nil, errNotSupported("groups")
+}
+
+func (r *repository) GetGroupByName(ctx context.Context, name string) (*model.Group, error) {
+	return nil, errNotSupported("groups")
+}
+
+func (r *repository) EnsureGroup(ctx context.Context, group model.Group) (*model.Group, error) {
+	return nil, errNotSupported("groups")
+}
+
+func (r *repository) DeleteGroup(ctx context.Context, id string) error {
+	return errNotSupported("groups")
+}
+
+func (r *repository) EnsureMembership(ctx context.Context, membership model.Membership) error {
+	return errNotSupported("group members")
+}
+
+func (r *repository) DeleteMembership(ctx context.Context, membership model.Membership) error {
+	return errNotSupported("group members")
+}
+
+func (r *repository) GetMembershipByID(ctx context.Context, groupID, userID string) (*model.Membership, error) {
+	return nil, errNotSupported("group members")
+}
+
+func (r *repository) EnsureVaultGroupAccess(ctx context.Context, groupAccess model.VaultGroupAccess) error {
+	return errNotSupported("vault accesses")
+}
+
+func (r *repository) DeleteVaultGroupAccess(ctx context.Context, vaultID string, groupID string) error {
+	return errNotSupported("vault accesses")
+}
+
+func (r *repository) GetVaultGroupAccessByID(ctx context.Context, vaultID string, groupID string) (*model.VaultGroupAccess, error) {
+	return nil, errNotSupported("vault accesses")
+}
+
+func (r *repository) EnsureVaultUserAccess(ctx context.Context, userAccess model.VaultUserAccess) error {
+	return errNotSupported("vault accesses")
+}
+
+func (r *repository) DeleteVaultUserAccess(ctx context.Context, vaultID string, userID string) error {
+	return errNotSupported("vault accesses")
+}
+
+func (r *repository) GetVaultUserAccessByID(ctx context.Context, vaultID string, userID string) (*model.VaultUserAccess, error) {
+	return nil, errNotSupported("vault accesses")
+}
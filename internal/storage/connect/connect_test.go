@@ -0,0 +1,380 @@
+package connect_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/connect"
+)
+
+const testToken = "test-token"
+
+// connectAPI is a minimal in memory stand-in of the 1password Connect API.
+type connectAPI struct {
+	mu     sync.Mutex
+	vaults []map[string]interface{}
+	items  map[string]map[string]map[string]interface{} // Vault ID -> Item ID -> item.
+	nextID int
+}
+
+func newConnectAPI() *connectAPI {
+	return &connectAPI{
+		vaults: []map[string]interface{}{
+			{"id": "vault-00", "name": "Vault00", "description": "Vault 00"},
+			{"id": "vault-01", "name": "Vault01", "description": "Vault 01"},
+			{"id": "vault-02", "name": `Vault "02" \ backslash`, "description": "Vault 02"},
+		},
+		items: map[string]map[string]map[string]interface{}{
+			"vault-00": {},
+			"vault-01": {
+				"item-00": {
+					"id":       "item-00",
+					"title":    "Item00",
+					"category": "LOGIN",
+					"vault":    map[string]interface{}{"id": "vault-01"},
+					"sections": []interface{}{map[string]interface{}{"id": "section-00", "label": "Section00"}},
+					"fields": []interface{}{
+						map[string]interface{}{"id": "username", "type": "STRING", "purpose": "USERNAME", "label": "username", "value": "user00"},
+						map[string]interface{}{"id": "field-00", "section": map[string]interface{}{"id": "section-00"}, "type": "STRING", "label": "Field00", "value": "value00"},
+					},
+					"urls": []interface{}{map[string]interface{}{"href": "https://test.io", "primary": true}},
+				},
+			},
+		},
+	}
+}
+
+var (
+	filterRegexp         = regexp.MustCompile(`^\w+ eq "((?:[^"\\]|\\.)*)"$`)
+	filterValueUnescaper = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+)
+
+func (c *connectAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"status": 401, "message": "Invalid token"})
+		return
+	}
+
+	notFound := map[string]interface{}{"status": 404, "message": "Not found"}
+	filterValue := func() (string, bool) {
+		f := r.URL.Query().Get("filter")
+		if f == "" {
+			return "", true
+		}
+		m := filterRegexp.FindStringSubmatch(f)
+		if m == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"status": 400, "message": "Invalid filter"})
+			return "", false
+		}
+		return filterValueUnescaper.Replace(m[1]), true
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		name, ok := filterValue()
+		if !ok {
+			return
+		}
+		res := []map[string]interface{}{}
+		for _, v := range c.vaults {
+			if name == "" || v["name"] == name {
+				res = append(res, v)
+			}
+		}
+		writeJSON(w, http.StatusOK, res)
+
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		for _, v := range c.vaults {
+			if v["id"] == parts[2] {
+				writeJSON(w, http.StatusOK, v)
+				return
+			}
+		}
+		writeJSON(w, http.StatusNotFound, notFound)
+
+	case len(parts) >= 4 && parts[3] == "items":
+		items, ok := c.items[parts[2]]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, notFound)
+			return
+		}
+
+		switch {
+		case len(parts) == 4 && r.Method == http.MethodGet:
+			title, ok := filterValue()
+			if !ok {
+				return
+			}
+			res := []map[string]interface{}{}
+			for _, i := range items {
+				if title == "" || i["title"] == title {
+					res = append(res, map[string]interface{}{"id": i["id"], "title": i["title"]})
+				}
+			}
+			writeJSON(w, http.StatusOK, res)
+
+		case len(parts) == 4 && r.Method == http.MethodPost:
+			item := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&item)
+			item["id"] = fmt.Sprintf("new-item-%02d", c.nextID)
+			c.nextID++
+			items[item["id"].(string)] = item
+			writeJSON(w, http.StatusOK, item)
+
+		case len(parts) == 5 && r.Method == http.MethodGet:
+			item, ok := items[parts[4]]
+			if !ok {
+				writeJSON(w, http.StatusNotFound, notFound)
+				return
+			}
+			writeJSON(w, http.StatusOK, item)
+
+		case len(parts) == 5 && r.Method == http.MethodPut:
+			if _, ok := items[parts[4]]; !ok {
+				writeJSON(w, http.StatusNotFound, notFound)
+				return
+			}
+			item := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&item)
+			items[parts[4]] = item
+			writeJSON(w, http.StatusOK, item)
+
+		case len(parts) == 5 && r.Method == http.MethodDelete:
+			if _, ok := items[parts[4]]; !ok {
+				writeJSON(w, http.StatusNotFound, notFound)
+				return
+			}
+			delete(items, parts[4])
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			writeJSON(w, http.StatusNotFound, notFound)
+		}
+
+	default:
+		writeJSON(w, http.StatusNotFound, notFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+var testItem = model.Item{
+	ID:       "item-00",
+	Title:    "Item00",
+	Category: "login",
+	Vault:    model.Vault{ID: "vault-01"},
+	Sections: []model.Section{{ID: "section-00", Label: "Section00"}},
+	Fields: []model.Field{
+		{ID: "username", Type: "STRING", Purpose: "USERNAME", Label: "username", Value: "user00"},
+		{ID: "field-00", Section: &model.Section{ID: "section-00", Label: "Section00"}, Type: "STRING", Label: "Field00", Value: "value00"},
+	},
+	URLs: []model.URL{{URL: "https://test.io", Primary: true}},
+}
+
+func TestRepository(t *testing.T) {
+	tests := map[string]struct {
+		token    string
+		exec     func(r storage.Repository) (interface{}, error)
+		expObj   interface{}
+		expErr   bool
+		expErrIs error
+	}{
+		"Getting a vault by ID should return the vault.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetVaultByID(context.TODO(), "vault-00")
+			},
+			expObj: &model.Vault{ID: "vault-00", Name: "Vault00", Description: "Vault 00"},
+		},
+
+		"Getting a missing vault by ID should fail with not found.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetVaultByID(context.TODO(), "vault-99")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotFound,
+		},
+
+		"Getting a vault by name should return the vault.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetVaultByName(context.TODO(), "Vault01")
+			},
+			expObj: &model.Vault{ID: "vault-01", Name: "Vault01", Description: "Vault 01"},
+		},
+
+		"Getting a vault by name with quotes and backslashes should return the vault.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetVaultByName(context.TODO(), `Vault "02" \ backslash`)
+			},
+			expObj: &model.Vault{ID: "vault-02", Name: `Vault "02" \ backslash`, Description: "Vault 02"},
+		},
+
+		"Getting a vault by name ending with a backslash should not match other vaults.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetVaultByName(context.TODO(), `Vault01\`)
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotFound,
+		},
+
+		"Getting a missing vault by name should fail with not found.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetVaultByName(context.TODO(), "Vault99")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotFound,
+		},
+
+		"Getting an item by ID should search it in all the vaults and return the item.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetItemByID(context.TODO(), "item-00")
+			},
+			expObj: &testItem,
+		},
+
+		"Getting a missing item by ID should fail with not found.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetItemByID(context.TODO(), "item-99")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotFound,
+		},
+
+		"Getting an item by title should return the item.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetItemByTitle(context.TODO(), "vault-01", "Item00")
+			},
+			expObj: &testItem,
+		},
+
+		"Getting an item by title using the item ID should return the item.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetItemByTitle(context.TODO(), "vault-01", "item-00")
+			},
+			expObj: &testItem,
+		},
+
+		"Creating an item should return the item with its ID.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.CreateItem(context.TODO(), model.Item{
+					Title:    "Item01",
+					Category: "password",
+					Vault:    model.Vault{ID: "vault-00"},
+					Fields:   []model.Field{{ID: "password", Type: "CONCEALED", Purpose: "PASSWORD", Label: "password", Value: "pass"}},
+					URLs:     []model.URL{{Primary: true}},
+				})
+			},
+			expObj: &model.Item{
+				ID:       "new-item-00",
+				Title:    "Item01",
+				Category: "password",
+				Vault:    model.Vault{ID: "vault-00"},
+				Sections: []model.Section{},
+				Fields:   []model.Field{{ID: "password", Type: "CONCEALED", Purpose: "PASSWORD", Label: "password", Value: "pass"}},
+			},
+		},
+
+		"Updating an item should replace the item.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				item := testItem
+				item.Title = "Item00-modified"
+				item.Sections = nil
+				item.Fields = item.Fields[:1]
+				return r.EnsureItem(context.TODO(), item)
+			},
+			expObj: &model.Item{
+				ID:       "item-00",
+				Title:    "Item00-modified",
+				Category: "login",
+				Vault:    model.Vault{ID: "vault-01"},
+				Sections: []model.Section{},
+				Fields:   []model.Field{{ID: "username", Type: "STRING", Purpose: "USERNAME", Label: "username", Value: "user00"}},
+				URLs:     []model.URL{{URL: "https://test.io", Primary: true}},
+			},
+		},
+
+		"Deleting an item should search it in all the vaults and delete it.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				err := r.DeleteItem(context.TODO(), "item-00")
+				if err != nil {
+					return nil, err
+				}
+				return r.GetItemByID(context.TODO(), "item-00")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotFound,
+		},
+
+		"Managing users should not be supported.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetUserByID(context.TODO(), "user-00")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotSupported,
+		},
+
+		"Creating vaults should not be supported.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.CreateVault(context.TODO(), model.Vault{Name: "Vault02"})
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotSupported,
+		},
+
+		"Using an invalid token should fail.": {
+			token: "wrong",
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetVaultByID(context.TODO(), "vault-00")
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			srv := httptest.NewServer(newConnectAPI())
+			defer srv.Close()
+
+			token := test.token
+			if token == "" {
+				token = testToken
+			}
+			repo, err := connect.NewRepository(connect.RepositoryConfig{URL: srv.URL, Token: token})
+			require.NoError(err)
+
+			gotObj, err := test.exec(repo)
+
+			if test.expErr {
+				assert.Error(err)
+				if test.expErrIs != nil {
+					assert.True(errors.Is(err, test.expErrIs), err)
+				}
+			} else if assert.NoError(err) {
+				assert.Equal(test.expObj, gotObj)
+			}
+		})
+	}
+}
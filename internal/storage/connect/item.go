@@ -0,0 +1,234 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+func (r *repository) CreateItem(ctx context.Context, item model.Item) (*model.Item, error) {
+	ci := mapModelToConnectItem(item)
+	ci.ID = ""
+
+	created := connectItem{}
+	err := r.do(ctx, http.MethodPost, itemsPath(item.Vault.ID), nil, ci, &created)
+	if err != nil {
+		return nil, err
+	}
+
+	gotItem := mapConnectToModelItem(created)
+
+	return &gotItem, nil
+}
+
+func (r *repository) GetItemByID(ctx context.Context, id string) (*model.Item, error) {
+	ci, err := r.findItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	gotItem := mapConnectToModelItem(*ci)
+
+	return &gotItem, nil
+}
+
+func (r *repository) GetItemByTitle(ctx context.Context, vaultID string, title string) (*model.Item, error) {
+	summaries := []connectItem{}
+	err := r.do(ctx, http.MethodGet, itemsPath(vaultID), filterEq("title", title), nil, &summaries)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like op, fallback to the item ID if there is no item with the title.
+	id := title
+	if len(summaries) > 0 {
+		id = summaries[0].ID
+	}
+
+	// The list only returns the item summary, get the item with all the fields.
+	ci := connectItem{}
+	err = r.do(ctx, http.MethodGet, itemPath(vaultID, id), nil, nil, &ci)
+	if err != nil {
+		return nil, err
+	}
+
+	gotItem := mapConnectToModelItem(ci)
+
+	return &gotItem, nil
+}
+
+func (r *repository) EnsureItem(ctx context.Context, item model.Item) (*model.Item, error) {
+	ci := mapModelToConnectItem(item)
+
+	updated := connectItem{}
+	err := r.do(ctx, http.MethodPut, itemPath(item.Vault.ID, item.ID), nil, ci, &updated)
+	if err != nil {
+		return nil, err
+	}
+
+	gotItem := mapConnectToModelItem(updated)
+
+	return &gotItem, nil
+}
+
+func (r *repository) DeleteItem(ctx context.Context, id string) error {
+	ci, err := r.findItem(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return r.do(ctx, http.MethodDelete, itemPath(ci.Vault.ID, ci.ID), nil, nil, nil)
+}
+
+// findItem gets an item without knowing its vault.
+//
+// Connect API requires the vault to get an item, so we search the item in all the vaults
+// that the token has access to.
+func (r *repository) findItem(ctx context.Context, id string) (*connectItem, error) {
+	vaults, err := r.listVaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range vaults {
+		ci := connectItem{}
+		err := r.do(ctx, http.MethodGet, itemPath(v.ID, id), nil, nil, &ci)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &ci, nil
+	}
+
+	return nil, fmt.Errorf("item %q: %w", id, storage.ErrNotFound)
+}
+
+func itemsPath(vaultID string) string {
+	return "/v1/vaults/" + url.PathEscape(vaultID) + "/items"
+}
+
+func itemPath(vaultID, itemID string) string {
+	return itemsPath(vaultID) + "/" + url.PathEscape(itemID)
+}
+
+type connectItem struct {
+	ID       string           `json:"id,omitempty"`
+	Title    string           `json:"title"`
+	Category string           `json:"category"`
+	Vault    connectVault     `json:"vault"`
+	Sections []connectSection `json:"sections,omitempty"`
+	Fields   []connectField   `json:"fields,omitempty"`
+	URLs     []connectURL     `json:"urls,omitempty"`
+	Tags     []string         `json:"tags,omitempty"`
+}
+
+type connectSection struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+type connectField struct {
+	ID       string          `json:"id,omitempty"`
+	Section  *connectSection `json:"section,omitempty"`
+	Type     string          `json:"type,omitempty"`
+	Purpose  string          `json:"purpose,omitempty"`
+	Label    string          `json:"label,omitempty"`
+	Value    string          `json:"value,omitempty"`
+	Generate bool            `json:"generate,omitempty"`
+}
+
+type connectURL struct {
+	Href    string `json:"href"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+func mapModelToConnectItem(item model.Item) connectItem {
+	ci := connectItem{
+		ID:       item.ID,
+		Title:    item.Title,
+		Category: strings.ToUpper(item.Category),
+		Vault:    connectVault{ID: item.Vault.ID},
+		Tags:     item.Tags,
+	}
+
+	for _, s := range item.Sections {
+		ci.Sections = append(ci.Sections, connectSection{ID: s.ID, Label: s.Label})
+	}
+
+	for _, f := range item.Fields {
+		cf := connectField{
+			ID:       f.ID,
+			Type:     f.Type,
+			Purpose:  f.Purpose,
+			Label:    f.Label,
+			Value:    f.Value,
+			Generate: f.Generate,
+		}
+		if f.Section != nil {
+			cf.Section = &connectSection{ID: f.Section.ID}
+		}
+		ci.Fields = append(ci.Fields, cf)
+	}
+
+	for _, u := range item.URLs {
+		if u.URL == "" {
+			continue
+		}
+		ci.URLs = append(ci.URLs, connectURL{Href: u.URL, Primary: u.Primary})
+	}
+
+	return ci
+}
+
+func mapConnectToModelItem(ci connectItem) model.Item {
+	item := model.Item{
+		ID:       ci.ID,
+		Title:    ci.Title,
+		Tags:     ci.Tags,
+		Category: strings.ToLower(ci.Category),
+		Vault:    mapConnectToModelVault(ci.Vault),
+		Fields:   []model.Field{},
+		Sections: []model.Section{},
+	}
+
+	// Connect fields only reference the section ID.
+	sectionsByID := map[string]model.Section{}
+	for _, cs := range ci.Sections {
+		s := model.Section{ID: cs.ID, Label: cs.Label}
+		sectionsByID[s.ID] = s
+		item.Sections = append(item.Sections, s)
+	}
+
+	for _, cf := range ci.Fields {
+		f := model.Field{
+			ID:      cf.ID,
+			Type:    cf.Type,
+			Purpose: cf.Purpose,
+			Label:   cf.Label,
+			Value:   cf.Value,
+		}
+		if cf.Section != nil {
+			s, ok := sectionsByID[cf.Section.ID]
+			if !ok {
+				s = model.Section{ID: cf.Section.ID, Label: cf.Section.Label}
+			}
+			f.Section = &s
+		}
+		item.Fields = append(item.Fields, f)
+	}
+
+	for _, u := range ci.URLs {
+		item.URLs = append(item.URLs, model.URL{URL: u.Href, Primary: u.Primary})
+	}
+
+	return item
+}
@@ -0,0 +1,79 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+func (r *repository) CreateVault(ctx context.Context, vault model.Vault) (*model.Vault, error) {
+	return nil, errNotSupported("vaults")
+}
+
+func (r *repository) GetVaultByID(ctx context.Context, id string) (*model.Vault, error) {
+	cv := connectVault{}
+	err := r.do(ctx, http.MethodGet, "/v1/vaults/"+url.PathEscape(id), nil, nil, &cv)
+	if err != nil {
+		return nil, err
+	}
+
+	gotVault := mapConnectToModelVault(cv)
+
+	return &gotVault, nil
+}
+
+func (r *repository) GetVaultByName(ctx context.Context, name string) (*model.Vault, error) {
+	cvs := []connectVault{}
+	err := r.do(ctx, http.MethodGet, "/v1/vaults", filterEq("name", name), nil, &cvs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cvs) == 0 {
+		return nil, fmt.Errorf("vault %q: %w", name, storage.ErrNotFound)
+	}
+
+	gotVault := mapConnectToModelVault(cvs[0])
+
+	return &gotVault, nil
+}
+
+func (r *repository) EnsureVault(ctx context.Context, vault model.Vault) (*model.Vault, error) {
+	return nil, errNotSupported("vaults")
+}
+
+func (r *repository) DeleteVault(ctx context.Context, id string) error {
+	return errNotSupported("vaults")
+}
+
+func (r *repository) ListVaultsByUser(ctx context.Context, userID string) (*[]model.Vault, error) {
+	return nil, errNotSupported("users")
+}
+
+func (r *repository) listVaults(ctx context.Context) ([]connectVault, error) {
+	cvs := []connectVault{}
+	err := r.do(ctx, http.MethodGet, "/v1/vaults", nil, nil, &cvs)
+	if err != nil {
+		return nil, err
+	}
+
+	return cvs, nil
+}
+
+type connectVault struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func mapConnectToModelVault(v connectVault) model.Vault {
+	return model.Vault{
+		ID:          v.ID,
+		Name:        v.Name,
+		Description: v.Description,
+	}
+}
@@ -0,0 +1,21 @@
+package fake_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/fake"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/storagetest"
+)
+
+func TestRepositoryConformance(t *testing.T) {
+	storagetest.RunRepositoryTests(t, func(t *testing.T) storage.Repository {
+		repo, err := fake.NewRepository(filepath.Join(t.TempDir(), "storage.json"))
+		require.NoError(t, err)
+
+		return repo
+	})
+}
@@ -3,8 +3,11 @@ package fake
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
@@ -20,77 +23,233 @@ type repository struct {
 	vaultsByID           map[string]model.Vault
 	vaultGroupAccessByID map[string]model.VaultGroupAccess
 	vaultUserAccessByID  map[string]model.VaultUserAccess
-	storageMu            sync.RWMutex
+	storageMu            sync.Mutex
+
+	// Name indexes to the object IDs, they are not stored, they are built from the objects.
+	userIDsByEmail map[string]string
+	groupIDsByName map[string]string
+	vaultIDsByName map[string]string
+	itemIDsByTitle map[string]string
+
+	faultsConfig *FaultsConfig
+	faults       *faults
+	// faultsInjections are the number of times the faults of every operation were injected
+	// with InjectFaults.
+	faultsInjections map[string]int
 }
 
 func NewRepository(fakeFilePath string) (storage.Repository, error) {
-	// Try loading state from disk.
-	// Ignore if file doesn't exists, it means its new storage.
-	fks, _ := loadStorage(fakeFilePath)
+	r, err := newRepository(fakeFilePath, true)
+	if err != nil {
+		return nil, err
+	}
 
-	// Initialize storage.
-	users := map[string]model.User{}
-	if fks != nil && fks.Users != nil {
-		users = fks.Users
+	return r, nil
+}
+
+// NewRepositoryWithoutFaults returns a fake repository that doesn't inject the faults on its
+// calls, it's used by the storage clients that inject them once per operation with InjectFaults.
+func NewRepositoryWithoutFaults(fakeFilePath string) (storage.Repository, error) {
+	r, err := newRepository(fakeFilePath, false)
+	if err != nil {
+		return nil, err
 	}
 
-	// Initialize storage.
-	items := map[string]model.Item{}
-	if fks != nil && fks.Items != nil {
-		items = fks.Items
+	return r, nil
+}
+
+func newRepository(fakeFilePath string, withFaults bool) (*repository, error) {
+	r := &repository{
+		fakeFilePath: fakeFilePath,
+		faults:       &faults{},
 	}
 
-	groups := map[string]model.Group{}
-	if fks != nil && fks.Groups != nil {
-		groups = fks.Groups
+	// Load the storage from disk, a missing file means it's a new storage.
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
-	members := map[string]model.Membership{}
-	if fks != nil && fks.Groups != nil {
-		members = fks.Members
+	if withFaults {
+		faults, err := newFaults(r.faultsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fake faults configuration: %w", err)
+		}
+		r.faults = faults
 	}
 
-	vaults := map[string]model.Vault{}
-	if fks != nil && fks.Groups != nil {
-		vaults = fks.Vaults
+	return r, nil
+}
+
+// InjectFaults injects the faults of the fake storage once for an operation of a storage client
+// (e.g: a fakeop command) instead of once per storage call, so the faults don't depend on the
+// number of calls of the operation. The operation is used as the method of the error rates.
+//
+// The random faults of an operation only depend on the seed, the operation and the number of
+// times it was injected before (stored on the fake storage), so with a seed they are the same
+// on every run.
+func InjectFaults(ctx context.Context, fakeFilePath, operation string, ids ...string) error {
+	r := &repository{fakeFilePath: fakeFilePath}
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
 	}
 
-	vaultGroupAccess := map[string]model.VaultGroupAccess{}
-	if fks != nil && fks.VaultGroupAccess != nil {
-		vaultGroupAccess = fks.VaultGroupAccess
+	n := r.faultsInjections[operation]
+	r.faultsInjections[operation] = n + 1
+	err = r.dumpStorage()
+	unlock()
+	if err != nil {
+		return err
 	}
 
-	vaultUserAccess := map[string]model.VaultUserAccess{}
-	if fks != nil && fks.VaultUserAccess != nil {
-		vaultUserAccess = fks.VaultUserAccess
+	faults, err := newFaults(r.faultsConfig)
+	if err != nil {
+		return fmt.Errorf("invalid fake faults configuration: %w", err)
 	}
+	faults.reseed(operation, n)
 
-	return &repository{
-		fakeFilePath:         fakeFilePath,
-		usersByID:            users,
-		itemsByID:            items,
-		groupsByID:           groups,
-		membershipByID:       members,
-		vaultsByID:           vaults,
-		vaultGroupAccessByID: vaultGroupAccess,
-		vaultUserAccessByID:  vaultUserAccess,
-	}, nil
+	return faults.inject(ctx, operation, ids...)
 }
 
-func (r *repository) CreateUser(ctx context.Context, user model.User) (*model.User, error) {
+// lockStorage locks the storage for this and the other processes using it, and reloads it from
+// disk so the changes of the other processes are seen. Reads use a shared lock between processes.
+func (r *repository) lockStorage(shared bool) (unlock func(), err error) {
+	// Every lock reloads the storage, so in the same process the lock is always exclusive.
 	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	unlockMu := r.storageMu.Unlock
+
+	f, err := os.OpenFile(r.fakeFilePath+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		unlockMu()
+		return nil, fmt.Errorf("could not open lock file: %w", err)
+	}
+
+	err = lockFile(f, shared)
+	if err != nil {
+		_ = f.Close()
+		unlockMu()
+		return nil, fmt.Errorf("could not lock file: %w", err)
+	}
+
+	unlock = func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+		unlockMu()
+	}
+
+	fks, err := loadStorage(r.fakeFilePath)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	r.load(*fks)
+
+	return unlock, nil
+}
+
+// load sets the repository objects from the storage.
+func (r *repository) load(fks fakeStorage) {
+	r.usersByID = map[string]model.User{}
+	if fks.Users != nil {
+		r.usersByID = fks.Users
+	}
+
+	r.itemsByID = map[string]model.Item{}
+	if fks.Items != nil {
+		r.itemsByID = fks.Items
+	}
+
+	r.groupsByID = map[string]model.Group{}
+	if fks.Groups != nil {
+		r.groupsByID = fks.Groups
+	}
+
+	r.membershipByID = map[string]model.Membership{}
+	if fks.Members != nil {
+		r.membershipByID = fks.Members
+	}
+
+	r.vaultsByID = map[string]model.Vault{}
+	if fks.Vaults != nil {
+		r.vaultsByID = fks.Vaults
+	}
+
+	r.vaultGroupAccessByID = map[string]model.VaultGroupAccess{}
+	if fks.VaultGroupAccess != nil {
+		r.vaultGroupAccessByID = fks.VaultGroupAccess
+	}
+
+	r.vaultUserAccessByID = map[string]model.VaultUserAccess{}
+	if fks.VaultUserAccess != nil {
+		r.vaultUserAccessByID = fks.VaultUserAccess
+	}
+
+	r.faultsInjections = map[string]int{}
+	if fks.FaultsInjections != nil {
+		r.faultsInjections = fks.FaultsInjections
+	}
 
-	id := user.Email
-	_, ok := r.usersByID[id]
+	r.faultsConfig = fks.Faults
+	r.buildIndexes()
+}
+
+// buildIndexes builds the name indexes from the stored objects.
+func (r *repository) buildIndexes() {
+	r.userIDsByEmail = map[string]string{}
+	for id, u := range r.usersByID {
+		r.userIDsByEmail[u.Email] = id
+	}
+
+	r.groupIDsByName = map[string]string{}
+	for id, g := range r.groupsByID {
+		r.groupIDsByName[g.Name] = id
+	}
+
+	r.vaultIDsByName = map[string]string{}
+	for id, v := range r.vaultsByID {
+		r.vaultIDsByName[v.Name] = id
+	}
+
+	r.itemIDsByTitle = map[string]string{}
+	for id, i := range r.itemsByID {
+		r.itemIDsByTitle[itemTitleKey(i.Vault.ID, i.Title)] = id
+	}
+}
+
+// itemTitleKey returns the item title index key, titles are unique per vault.
+func itemTitleKey(vaultID, title string) string {
+	return vaultID + "/" + title
+}
+
+func (r *repository) CreateUser(ctx context.Context, user model.User) (*model.User, error) {
+	err := r.faults.inject(ctx, "CreateUser", user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	_, ok := r.userIDsByEmail[user.Email]
 	if ok {
 		return nil, fmt.Errorf("user already exists")
 	}
 
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
 	user.ID = id
 	r.usersByID[user.ID] = user
+	r.userIDsByEmail[user.Email] = user.ID
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return nil, err
 	}
@@ -99,43 +258,71 @@ func (r *repository) CreateUser(ctx context.Context, user model.User) (*model.Us
 }
 
 func (r *repository) GetUserByID(ctx context.Context, id string) (*model.User, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetUserByID", id)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	user, ok := r.usersByID[id]
 	if !ok {
-		return nil, fmt.Errorf("user does not exists")
+		return nil, fmt.Errorf("user does not exists: %w", storage.ErrNotFound)
 	}
 
 	return &user, nil
 }
 
 func (r *repository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetUserByEmail", email)
+	if err != nil {
+		return nil, err
+	}
 
-	// Fake storage doesn't need optimization.
-	for _, u := range r.usersByID {
-		if u.Email == email {
-			return &u, nil
-		}
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	user, ok := r.usersByID[r.userIDsByEmail[email]]
+	if !ok {
+		return nil, fmt.Errorf("user does not exists: %w", storage.ErrNotFound)
 	}
 
-	return nil, fmt.Errorf("user does not exists")
+	return &user, nil
 }
 
 func (r *repository) EnsureUser(ctx context.Context, user model.User) (*model.User, error) {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "EnsureUser", user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	_, ok := r.usersByID[user.ID]
+	current, ok := r.usersByID[user.ID]
 	if !ok {
-		return nil, fmt.Errorf("user doesn't exists")
+		return nil, fmt.Errorf("user doesn't exists: %w", storage.ErrNotFound)
+	}
+
+	if id, ok := r.userIDsByEmail[user.Email]; ok && id != user.ID {
+		return nil, fmt.Errorf("user with the same email already exists")
 	}
 
-	r.usersByID[user.Email] = user
+	delete(r.userIDsByEmail, current.Email)
+	r.usersByID[user.ID] = user
+	r.userIDsByEmail[user.Email] = user.ID
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return nil, err
 	}
@@ -144,17 +331,26 @@ func (r *repository) EnsureUser(ctx context.Context, user model.User) (*model.Us
 }
 
 func (r *repository) DeleteUser(ctx context.Context, id string) error {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "DeleteUser", id)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	_, ok := r.usersByID[id]
+	user, ok := r.usersByID[id]
 	if !ok {
-		return fmt.Errorf("user doesn't exists")
+		return fmt.Errorf("user doesn't exists: %w", storage.ErrNotFound)
 	}
 
 	delete(r.usersByID, id)
+	delete(r.userIDsByEmail, user.Email)
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return err
 	}
@@ -163,19 +359,32 @@ func (r *repository) DeleteUser(ctx context.Context, id string) error {
 }
 
 func (r *repository) CreateGroup(ctx context.Context, group model.Group) (*model.Group, error) {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "CreateGroup", group.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	id := group.Name
-	_, ok := r.groupsByID[id]
+	_, ok := r.groupIDsByName[group.Name]
 	if ok {
 		return nil, fmt.Errorf("group already exists")
 	}
 
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
 	group.ID = id
 	r.groupsByID[group.ID] = group
+	r.groupIDsByName[group.Name] = group.ID
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return nil, err
 	}
@@ -184,43 +393,71 @@ func (r *repository) CreateGroup(ctx context.Context, group model.Group) (*model
 }
 
 func (r *repository) GetGroupByID(ctx context.Context, id string) (*model.Group, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetGroupByID", id)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	group, ok := r.groupsByID[id]
 	if !ok {
-		return nil, fmt.Errorf("group does not exists")
+		return nil, fmt.Errorf("group does not exists: %w", storage.ErrNotFound)
 	}
 
 	return &group, nil
 }
 
 func (r *repository) GetGroupByName(ctx context.Context, name string) (*model.Group, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetGroupByName", name)
+	if err != nil {
+		return nil, err
+	}
 
-	// Fake storage doesn't need optimization.
-	for _, u := range r.groupsByID {
-		if u.Name == name {
-			return &u, nil
-		}
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
-	return nil, fmt.Errorf("group does not exists")
+	group, ok := r.groupsByID[r.groupIDsByName[name]]
+	if !ok {
+		return nil, fmt.Errorf("group does not exists: %w", storage.ErrNotFound)
+	}
+
+	return &group, nil
 }
 
 func (r *repository) EnsureGroup(ctx context.Context, group model.Group) (*model.Group, error) {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "EnsureGroup", group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	_, ok := r.groupsByID[group.ID]
+	current, ok := r.groupsByID[group.ID]
 	if !ok {
-		return nil, fmt.Errorf("group doesn't exists")
+		return nil, fmt.Errorf("group doesn't exists: %w", storage.ErrNotFound)
 	}
 
-	r.groupsByID[group.Name] = group
+	if id, ok := r.groupIDsByName[group.Name]; ok && id != group.ID {
+		return nil, fmt.Errorf("group with the same name already exists")
+	}
+
+	delete(r.groupIDsByName, current.Name)
+	r.groupsByID[group.ID] = group
+	r.groupIDsByName[group.Name] = group.ID
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return nil, err
 	}
@@ -229,17 +466,26 @@ func (r *repository) EnsureGroup(ctx context.Context, group model.Group) (*model
 }
 
 func (r *repository) DeleteGroup(ctx context.Context, id string) error {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "DeleteGroup", id)
+	if err != nil {
+		return err
+	}
 
-	_, ok := r.groupsByID[id]
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	group, ok := r.groupsByID[id]
 	if !ok {
-		return fmt.Errorf("group doesn't exists")
+		return fmt.Errorf("group doesn't exists: %w", storage.ErrNotFound)
 	}
 
 	delete(r.groupsByID, id)
+	delete(r.groupIDsByName, group.Name)
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return err
 	}
@@ -252,13 +498,21 @@ func (r *repository) getMembershipID(groupID, userID string) string {
 }
 
 func (r *repository) EnsureMembership(ctx context.Context, membership model.Membership) error {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "EnsureMembership", membership.GroupID, membership.UserID)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	id := r.getMembershipID(membership.GroupID, membership.UserID)
 	r.membershipByID[id] = membership
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return err
 	}
@@ -267,19 +521,27 @@ func (r *repository) EnsureMembership(ctx context.Context, membership model.Memb
 }
 
 func (r *repository) DeleteMembership(ctx context.Context, membership model.Membership) error {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "DeleteMembership", membership.GroupID, membership.UserID)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	id := r.getMembershipID(membership.GroupID, membership.UserID)
 
 	_, ok := r.membershipByID[id]
 	if !ok {
-		return fmt.Errorf("membership doesn't exists")
+		return fmt.Errorf("membership doesn't exists: %w", storage.ErrNotFound)
 	}
 
 	delete(r.membershipByID, id)
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return err
 	}
@@ -288,32 +550,76 @@ func (r *repository) DeleteMembership(ctx context.Context, membership model.Memb
 }
 
 func (r *repository) GetMembershipByID(ctx context.Context, groupID, userID string) (*model.Membership, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetMembershipByID", groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	id := r.getMembershipID(groupID, userID)
 	m, ok := r.membershipByID[id]
 	if !ok {
-		return nil, fmt.Errorf("membership doesn't exists")
+		return nil, fmt.Errorf("membership doesn't exists: %w", storage.ErrNotFound)
 	}
 
 	return &m, nil
 }
 
+func (r *repository) ListMembershipsByGroup(ctx context.Context, groupID string) (*[]model.Membership, error) {
+	err := r.faults.inject(ctx, "ListMembershipsByGroup", groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	ms := []model.Membership{}
+	for _, m := range r.membershipByID {
+		if m.GroupID == groupID {
+			ms = append(ms, m)
+		}
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].UserID < ms[j].UserID })
+
+	return &ms, nil
+}
+
 func (r *repository) CreateVault(ctx context.Context, vault model.Vault) (*model.Vault, error) {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "CreateVault", vault.Name)
+	if err != nil {
+		return nil, err
+	}
 
-	id := vault.Name
-	_, ok := r.groupsByID[id]
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	_, ok := r.vaultIDsByName[vault.Name]
 	if ok {
 		return nil, fmt.Errorf("vault already exists")
 	}
 
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
 	vault.ID = id
 	r.vaultsByID[vault.ID] = vault
+	r.vaultIDsByName[vault.Name] = vault.ID
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return nil, err
 	}
@@ -322,47 +628,114 @@ func (r *repository) CreateVault(ctx context.Context, vault model.Vault) (*model
 }
 
 func (r *repository) GetVaultByID(ctx context.Context, id string) (*model.Vault, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetVaultByID", id)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	vault, ok := r.vaultsByID[id]
 	if !ok {
-		return nil, fmt.Errorf("vault does not exists")
+		return nil, fmt.Errorf("vault does not exists: %w", storage.ErrNotFound)
 	}
 
 	return &vault, nil
 }
 
 func (r *repository) ListVaultsByUser(ctx context.Context, userID string) (*[]model.Vault, error) {
-	return &[]model.Vault{}, nil
+	err := r.faults.inject(ctx, "ListVaultsByUser", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// The user vaults are the ones with a user access or a group access of the user groups.
+	userGroupIDs := map[string]bool{}
+	for _, m := range r.membershipByID {
+		if m.UserID == userID {
+			userGroupIDs[m.GroupID] = true
+		}
+	}
+	vaultIDs := map[string]bool{}
+	for _, a := range r.vaultUserAccessByID {
+		if a.UserID == userID {
+			vaultIDs[a.VaultID] = true
+		}
+	}
+	for _, a := range r.vaultGroupAccessByID {
+		if userGroupIDs[a.GroupID] {
+			vaultIDs[a.VaultID] = true
+		}
+	}
+
+	vs := []model.Vault{}
+	for id := range vaultIDs {
+		v, ok := r.vaultsByID[id]
+		if ok {
+			vs = append(vs, v)
+		}
+	}
+	sort.Slice(vs, func(i, j int) bool { return vs[i].ID < vs[j].ID })
+
+	return &vs, nil
 }
 
 func (r *repository) GetVaultByName(ctx context.Context, name string) (*model.Vault, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetVaultByName", name)
+	if err != nil {
+		return nil, err
+	}
 
-	// Fake storage doesn't need optimization.
-	for _, u := range r.vaultsByID {
-		if u.Name == name {
-			return &u, nil
-		}
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	vault, ok := r.vaultsByID[r.vaultIDsByName[name]]
+	if !ok {
+		return nil, fmt.Errorf("vault does not exists: %w", storage.ErrNotFound)
 	}
 
-	return nil, fmt.Errorf("vault does not exists")
+	return &vault, nil
 }
 
 func (r *repository) EnsureVault(ctx context.Context, vault model.Vault) (*model.Vault, error) {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "EnsureVault", vault.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	_, ok := r.vaultsByID[vault.ID]
+	current, ok := r.vaultsByID[vault.ID]
 	if !ok {
-		return nil, fmt.Errorf("vault doesn't exists")
+		return nil, fmt.Errorf("vault doesn't exists: %w", storage.ErrNotFound)
 	}
 
-	r.vaultsByID[vault.Name] = vault
+	if id, ok := r.vaultIDsByName[vault.Name]; ok && id != vault.ID {
+		return nil, fmt.Errorf("vault with the same name already exists")
+	}
 
-	err := r.dumpStorage()
+	delete(r.vaultIDsByName, current.Name)
+	r.vaultsByID[vault.ID] = vault
+	r.vaultIDsByName[vault.Name] = vault.ID
+
+	err = r.dumpStorage()
 	if err != nil {
 		return nil, err
 	}
@@ -371,17 +744,26 @@ func (r *repository) EnsureVault(ctx context.Context, vault model.Vault) (*model
 }
 
 func (r *repository) DeleteVault(ctx context.Context, id string) error {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "DeleteVault", id)
+	if err != nil {
+		return err
+	}
 
-	_, ok := r.vaultsByID[id]
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	vault, ok := r.vaultsByID[id]
 	if !ok {
-		return fmt.Errorf("vault doesn't exists")
+		return fmt.Errorf("vault doesn't exists: %w", storage.ErrNotFound)
 	}
 
 	delete(r.vaultsByID, id)
+	delete(r.vaultIDsByName, vault.Name)
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return err
 	}
@@ -394,13 +776,21 @@ func (r *repository) getVaultGroupAccessID(vaultID, groupID string) string {
 }
 
 func (r *repository) EnsureVaultGroupAccess(ctx context.Context, groupAccess model.VaultGroupAccess) error {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "EnsureVaultGroupAccess", groupAccess.VaultID, groupAccess.GroupID)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	id := r.getVaultGroupAccessID(groupAccess.VaultID, groupAccess.GroupID)
 	r.vaultGroupAccessByID[id] = groupAccess
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return err
 	}
@@ -409,19 +799,27 @@ func (r *repository) EnsureVaultGroupAccess(ctx context.Context, groupAccess mod
 }
 
 func (r *repository) DeleteVaultGroupAccess(ctx context.Context, vaultID string, groupID string) error {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "DeleteVaultGroupAccess", vaultID, groupID)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	id := r.getVaultGroupAccessID(vaultID, groupID)
 
 	_, ok := r.vaultGroupAccessByID[id]
 	if !ok {
-		return fmt.Errorf("vault access doesn't exists")
+		return fmt.Errorf("vault access doesn't exists: %w", storage.ErrNotFound)
 	}
 
 	delete(r.vaultGroupAccessByID, id)
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return err
 	}
@@ -430,30 +828,69 @@ func (r *repository) DeleteVaultGroupAccess(ctx context.Context, vaultID string,
 }
 
 func (r *repository) GetVaultGroupAccessByID(ctx context.Context, vaultID string, groupID string) (*model.VaultGroupAccess, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetVaultGroupAccessByID", vaultID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	id := r.getVaultGroupAccessID(vaultID, groupID)
 	v, ok := r.vaultGroupAccessByID[id]
 	if !ok {
-		return nil, fmt.Errorf("vault access doesn't exists")
+		return nil, fmt.Errorf("vault access doesn't exists: %w", storage.ErrNotFound)
 	}
 
 	return &v, nil
 }
 
+func (r *repository) ListVaultGroupAccessesByVault(ctx context.Context, vaultID string) (*[]model.VaultGroupAccess, error) {
+	err := r.faults.inject(ctx, "ListVaultGroupAccessesByVault", vaultID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	as := []model.VaultGroupAccess{}
+	for _, a := range r.vaultGroupAccessByID {
+		if a.VaultID == vaultID {
+			as = append(as, a)
+		}
+	}
+	sort.Slice(as, func(i, j int) bool { return as[i].GroupID < as[j].GroupID })
+
+	return &as, nil
+}
+
 func (r *repository) getVaultUserAccessID(vaultID, userID string) string {
 	return vaultID + "/" + userID
 }
 
 func (r *repository) EnsureVaultUserAccess(ctx context.Context, userAccess model.VaultUserAccess) error {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "EnsureVaultUserAccess", userAccess.VaultID, userAccess.UserID)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	id := r.getVaultUserAccessID(userAccess.VaultID, userAccess.UserID)
 	r.vaultUserAccessByID[id] = userAccess
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return err
 	}
@@ -462,19 +899,27 @@ func (r *repository) EnsureVaultUserAccess(ctx context.Context, userAccess model
 }
 
 func (r *repository) DeleteVaultUserAccess(ctx context.Context, vaultID string, userID string) error {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "DeleteVaultUserAccess", vaultID, userID)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	id := r.getVaultUserAccessID(vaultID, userID)
 
 	_, ok := r.vaultUserAccessByID[id]
 	if !ok {
-		return fmt.Errorf("vault access doesn't exists")
+		return fmt.Errorf("vault access doesn't exists: %w", storage.ErrNotFound)
 	}
 
 	delete(r.vaultUserAccessByID, id)
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return err
 	}
@@ -483,32 +928,76 @@ func (r *repository) DeleteVaultUserAccess(ctx context.Context, vaultID string,
 }
 
 func (r *repository) GetVaultUserAccessByID(ctx context.Context, vaultID string, userID string) (*model.VaultUserAccess, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetVaultUserAccessByID", vaultID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	id := r.getVaultUserAccessID(vaultID, userID)
 	v, ok := r.vaultUserAccessByID[id]
 	if !ok {
-		return nil, fmt.Errorf("vault access doesn't exists")
+		return nil, fmt.Errorf("vault access doesn't exists: %w", storage.ErrNotFound)
 	}
 
 	return &v, nil
 }
 
+func (r *repository) ListVaultUserAccessesByVault(ctx context.Context, vaultID string) (*[]model.VaultUserAccess, error) {
+	err := r.faults.inject(ctx, "ListVaultUserAccessesByVault", vaultID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	as := []model.VaultUserAccess{}
+	for _, a := range r.vaultUserAccessByID {
+		if a.VaultID == vaultID {
+			as = append(as, a)
+		}
+	}
+	sort.Slice(as, func(i, j int) bool { return as[i].UserID < as[j].UserID })
+
+	return &as, nil
+}
+
 func (r *repository) CreateItem(ctx context.Context, item model.Item) (*model.Item, error) {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "CreateItem", item.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	id := item.Title
-	_, ok := r.itemsByID[id]
+	_, ok := r.itemIDsByTitle[itemTitleKey(item.Vault.ID, item.Title)]
 	if ok {
 		return nil, fmt.Errorf("item already exists")
 	}
 
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
 	item.ID = id
 	r.itemsByID[item.ID] = item
+	r.itemIDsByTitle[itemTitleKey(item.Vault.ID, item.Title)] = item.ID
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return nil, err
 	}
@@ -517,43 +1006,71 @@ func (r *repository) CreateItem(ctx context.Context, item model.Item) (*model.It
 }
 
 func (r *repository) GetItemByID(ctx context.Context, id string) (*model.Item, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetItemByID", id)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	item, ok := r.itemsByID[id]
 	if !ok {
-		return nil, fmt.Errorf("item does not exists")
+		return nil, fmt.Errorf("item does not exists: %w", storage.ErrNotFound)
 	}
 
 	return &item, nil
 }
 
 func (r *repository) GetItemByTitle(ctx context.Context, vaultID string, title string) (*model.Item, error) {
-	r.storageMu.RLock()
-	defer r.storageMu.RUnlock()
+	err := r.faults.inject(ctx, "GetItemByTitle", vaultID, title)
+	if err != nil {
+		return nil, err
+	}
 
-	// Fake storage doesn't need optimization.
-	for _, i := range r.itemsByID {
-		if i.Title == title && i.Vault.ID == vaultID {
-			return &i, nil
-		}
+	unlock, err := r.lockStorage(true)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
-	return nil, fmt.Errorf("group does not exists")
+	item, ok := r.itemsByID[r.itemIDsByTitle[itemTitleKey(vaultID, title)]]
+	if !ok {
+		return nil, fmt.Errorf("item does not exists: %w", storage.ErrNotFound)
+	}
+
+	return &item, nil
 }
 
 func (r *repository) EnsureItem(ctx context.Context, item model.Item) (*model.Item, error) {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "EnsureItem", item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	_, ok := r.itemsByID[item.ID]
+	current, ok := r.itemsByID[item.ID]
 	if !ok {
-		return nil, fmt.Errorf("item doesn't exists")
+		return nil, fmt.Errorf("item doesn't exists: %w", storage.ErrNotFound)
+	}
+
+	if id, ok := r.itemIDsByTitle[itemTitleKey(item.Vault.ID, item.Title)]; ok && id != item.ID {
+		return nil, fmt.Errorf("item with the same title already exists")
 	}
 
-	r.itemsByID[item.Title] = item
+	delete(r.itemIDsByTitle, itemTitleKey(current.Vault.ID, current.Title))
+	r.itemsByID[item.ID] = item
+	r.itemIDsByTitle[itemTitleKey(item.Vault.ID, item.Title)] = item.ID
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return nil, err
 	}
@@ -562,17 +1079,26 @@ func (r *repository) EnsureItem(ctx context.Context, item model.Item) (*model.It
 }
 
 func (r *repository) DeleteItem(ctx context.Context, id string) error {
-	r.storageMu.Lock()
-	defer r.storageMu.Unlock()
+	err := r.faults.inject(ctx, "DeleteItem", id)
+	if err != nil {
+		return err
+	}
 
-	_, ok := r.itemsByID[id]
+	unlock, err := r.lockStorage(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	item, ok := r.itemsByID[id]
 	if !ok {
-		return fmt.Errorf("item doesn't exists")
+		return fmt.Errorf("item doesn't exists: %w", storage.ErrNotFound)
 	}
 
 	delete(r.itemsByID, id)
+	delete(r.itemIDsByTitle, itemTitleKey(item.Vault.ID, item.Title))
 
-	err := r.dumpStorage()
+	err = r.dumpStorage()
 	if err != nil {
 		return err
 	}
@@ -588,8 +1114,12 @@ type fakeStorage struct {
 	Vaults           map[string]model.Vault
 	VaultGroupAccess map[string]model.VaultGroupAccess
 	VaultUserAccess  map[string]model.VaultUserAccess
+	Faults           *FaultsConfig  `json:",omitempty"`
+	FaultsInjections map[string]int `json:",omitempty"`
 }
 
+// dumpStorage writes the storage to a temporary file and renames it to the storage file, so
+// the readers never see a partially written storage.
 func (r *repository) dumpStorage() error {
 	fks := fakeStorage{
 		Users:            r.usersByID,
@@ -599,6 +1129,8 @@ func (r *repository) dumpStorage() error {
 		Vaults:           r.vaultsByID,
 		VaultGroupAccess: r.vaultGroupAccessByID,
 		VaultUserAccess:  r.vaultUserAccessByID,
+		Faults:           r.faultsConfig,
+		FaultsInjections: r.faultsInjections,
 	}
 
 	data, err := json.MarshalIndent(fks, "", "\t")
@@ -606,22 +1138,52 @@ func (r *repository) dumpStorage() error {
 		return fmt.Errorf("could not marshal storage: %w", err)
 	}
 
-	err = os.WriteFile(r.fakeFilePath, data, 0644)
+	f, err := os.CreateTemp(filepath.Dir(r.fakeFilePath), filepath.Base(r.fakeFilePath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not create temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return fmt.Errorf("could not write file: %w", err)
 	}
 
+	err = os.Chmod(f.Name(), 0644)
+	if err != nil {
+		return fmt.Errorf("could not set file permissions: %w", err)
+	}
+
+	err = os.Rename(f.Name(), r.fakeFilePath)
+	if err != nil {
+		return fmt.Errorf("could not replace file: %w", err)
+	}
+
 	return nil
 }
 
+// loadStorage reads the storage from disk, a missing or empty file is an empty storage.
 func loadStorage(filePath string) (*fakeStorage, error) {
 	fks := &fakeStorage{}
 
 	data, err := os.ReadFile(filePath)
 	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fks, nil
+		}
 		return nil, fmt.Errorf("could not read file: %w", err)
 	}
 
+	if len(data) == 0 {
+		return fks, nil
+	}
+
 	err = json.Unmarshal(data, fks)
 	if err != nil {
 		return nil, fmt.Errorf("could not unmarshal storage: %w", err)
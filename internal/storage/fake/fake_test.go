@@ -0,0 +1,151 @@
+package fake_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/fake"
+)
+
+func TestRepositorySharedStorage(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	// Every repository has its own file descriptors, like different processes.
+	const repos, groupsPerRepo = 4, 10
+	var wg sync.WaitGroup
+	for i := 0; i < repos; i++ {
+		r, err := fake.NewRepository(path)
+		require.NoError(err)
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < groupsPerRepo; j++ {
+				_, err := r.CreateGroup(context.TODO(), model.Group{Name: fmt.Sprintf("group-%d-%d", i, j)})
+				assert.NoError(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// A new repository sees all the writes.
+	r, err := fake.NewRepository(path)
+	require.NoError(err)
+	for i := 0; i < repos; i++ {
+		for j := 0; j < groupsPerRepo; j++ {
+			_, err := r.GetGroupByName(context.TODO(), fmt.Sprintf("group-%d-%d", i, j))
+			assert.NoError(err)
+		}
+	}
+
+	// The changes of other repositories are seen without creating it again.
+	other, err := fake.NewRepository(path)
+	require.NoError(err)
+	g, err := other.CreateGroup(context.TODO(), model.Group{Name: "other-group"})
+	require.NoError(err)
+	got, err := r.GetGroupByID(context.TODO(), g.ID)
+	require.NoError(err)
+	assert.Equal(g, got)
+}
+
+func TestInjectFaults(t *testing.T) {
+	tests := map[string]struct {
+		env     map[string]string
+		ids     []string
+		expErrs func(t *testing.T, errs []error)
+	}{
+		"Without faults it should not fail.": {
+			expErrs: func(t *testing.T, errs []error) {
+				for _, err := range errs {
+					assert.NoError(t, err)
+				}
+			},
+		},
+
+		"The failing IDs should always fail.": {
+			env: map[string]string{fake.EnvVarOpFakeErrorIDs: "failing-id"},
+			ids: []string{"other-id", "failing-id"},
+			expErrs: func(t *testing.T, errs []error) {
+				for _, err := range errs {
+					assert.ErrorIs(t, err, fake.ErrInjected)
+				}
+			},
+		},
+
+		"The rate limits should be injected at the rate.": {
+			env: map[string]string{fake.EnvVarOpFakeRateLimitRate: "0.5", fake.EnvVarOpFakeSeed: "42"},
+			expErrs: func(t *testing.T, errs []error) {
+				failed := 0
+				for _, err := range errs {
+					if err != nil {
+						assert.ErrorIs(t, err, fake.ErrRateLimited)
+						failed++
+					}
+				}
+				assert.InDelta(t, len(errs)/2, failed, float64(len(errs))/5)
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range test.env {
+				t.Setenv(k, v)
+			}
+
+			path := filepath.Join(t.TempDir(), "storage.json")
+			errs := make([]error, 100)
+			for i := range errs {
+				errs[i] = fake.InjectFaults(context.TODO(), path, "group create", test.ids...)
+			}
+			test.expErrs(t, errs)
+		})
+	}
+}
+
+func TestInjectFaultsSeed(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv(fake.EnvVarOpFakeRateLimitRate, "0.5")
+	t.Setenv(fake.EnvVarOpFakeSeed, "42")
+
+	// inject returns if every injection of every operation failed.
+	inject := func(operations ...string) map[string][]bool {
+		path := filepath.Join(t.TempDir(), "storage.json")
+		failed := map[string][]bool{}
+		for _, op := range operations {
+			err := fake.InjectFaults(context.TODO(), path, op)
+			failed[op] = append(failed[op], err != nil)
+		}
+		return failed
+	}
+
+	var ops, itemOps []string
+	for i := 0; i < 20; i++ {
+		ops = append(ops, "group create", "item edit")
+		itemOps = append(itemOps, "item edit")
+	}
+
+	// With the same seed the faults are the same on every run.
+	got := inject(ops...)
+	assert.Equal(got, inject(ops...))
+	assert.Contains(got["item edit"], true)
+	assert.Contains(got["item edit"], false)
+
+	// The faults of an operation don't depend on the other operations.
+	assert.Equal(got["item edit"], inject(itemOps...)["item edit"])
+
+	// A different seed has different faults.
+	t.Setenv(fake.EnvVarOpFakeSeed, "43")
+	assert.NotEqual(got, inject(ops...))
+}
@@ -0,0 +1,183 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Env vars to configure the fault injection, they take precedence over the fake storage
+// `Faults` configuration.
+const (
+	EnvVarOpFakeLatency       = "OP_FAKE_LATENCY"
+	EnvVarOpFakeErrorRate     = "OP_FAKE_ERROR_RATE"
+	EnvVarOpFakeRateLimitRate = "OP_FAKE_RATE_LIMIT_RATE"
+	EnvVarOpFakeErrorIDs      = "OP_FAKE_ERROR_IDS"
+	EnvVarOpFakeSeed          = "OP_FAKE_SEED"
+)
+
+var (
+	// ErrInjected is the error returned by the injected failures.
+	ErrInjected = errors.New("fake injected error")
+	// ErrRateLimited is the error returned by the simulated rate limits, the message is
+	// the one used by op.
+	ErrRateLimited = errors.New("fake rate limited: (429) Too Many Requests")
+)
+
+// FaultsConfig is the fault injection configuration of the fake repository, it's set on the
+// `Faults` section of the fake storage JSON.
+type FaultsConfig struct {
+	// Latency is the time added to every call (e.g: `500ms`).
+	Latency string `json:",omitempty"`
+	// ErrorRates are the probabilities (0-1) of the calls failing by method name (e.g:
+	// `CreateGroup`), `*` applies to all the methods.
+	ErrorRates map[string]float64 `json:",omitempty"`
+	// ErrorIDs are the IDs (or names, emails and titles on creation) whose calls always fail.
+	ErrorIDs []string `json:",omitempty"`
+	// RateLimitRate is the probability (0-1) of a call failing with a rate limit error.
+	RateLimitRate float64 `json:",omitempty"`
+	// Seed is the seed of the random faults, so they are the same on every run, random if
+	// not set.
+	Seed int64 `json:",omitempty"`
+}
+
+type faults struct {
+	latency       time.Duration
+	errorRates    map[string]float64
+	errorIDs      map[string]bool
+	rateLimitRate float64
+	seed          int64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// newFaults returns the faults to inject based on the configuration and the env vars.
+func newFaults(config *FaultsConfig) (*faults, error) {
+	c := FaultsConfig{}
+	if config != nil {
+		c = *config
+	}
+
+	f := &faults{
+		errorRates:    map[string]float64{},
+		errorIDs:      map[string]bool{},
+		rateLimitRate: c.RateLimitRate,
+		seed:          c.Seed,
+	}
+
+	latency := c.Latency
+	if env := os.Getenv(EnvVarOpFakeLatency); env != "" {
+		latency = env
+	}
+	if latency != "" {
+		d, err := time.ParseDuration(latency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fake latency: %w", err)
+		}
+		f.latency = d
+	}
+
+	for method, rate := range c.ErrorRates {
+		f.errorRates[method] = rate
+	}
+	if env := os.Getenv(EnvVarOpFakeErrorRate); env != "" {
+		rate, err := strconv.ParseFloat(env, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fake error rate: %w", err)
+		}
+		f.errorRates["*"] = rate
+	}
+
+	if env := os.Getenv(EnvVarOpFakeRateLimitRate); env != "" {
+		rate, err := strconv.ParseFloat(env, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fake rate limit rate: %w", err)
+		}
+		f.rateLimitRate = rate
+	}
+
+	if env := os.Getenv(EnvVarOpFakeSeed); env != "" {
+		seed, err := strconv.ParseInt(env, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fake seed: %w", err)
+		}
+		f.seed = seed
+	}
+	if f.seed == 0 {
+		f.seed = time.Now().UnixNano()
+	}
+	f.rand = rand.New(rand.NewSource(f.seed))
+
+	ids := c.ErrorIDs
+	if env := os.Getenv(EnvVarOpFakeErrorIDs); env != "" {
+		ids = strings.Split(env, ",")
+	}
+	for _, id := range ids {
+		f.errorIDs[strings.TrimSpace(id)] = true
+	}
+
+	return f, nil
+}
+
+// inject waits the latency and returns an error if the call must fail. The IDs are the
+// objects the call is using.
+func (f *faults) inject(ctx context.Context, method string, ids ...string) error {
+	if f.latency > 0 {
+		t := time.NewTimer(f.latency)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return fmt.Errorf("%s: %w", method, ctx.Err())
+		case <-t.C:
+		}
+	}
+
+	for _, id := range ids {
+		if f.errorIDs[id] {
+			return fmt.Errorf("%s %q: %w", method, id, ErrInjected)
+		}
+	}
+
+	if f.happens(f.rateLimitRate) {
+		return fmt.Errorf("%s: %w", method, ErrRateLimited)
+	}
+
+	rate, ok := f.errorRates[method]
+	if !ok {
+		rate = f.errorRates["*"]
+	}
+	if f.happens(rate) {
+		return fmt.Errorf("%s: %w", method, ErrInjected)
+	}
+
+	return nil
+}
+
+// reseed sets the random faults source to one that only depends on the seed, the operation
+// and the number of times it was injected before.
+func (f *faults) reseed(operation string, n int) {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d/%s/%d", f.seed, operation, n)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rand = rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+func (f *faults) happens(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rand.Float64() < rate
+}
@@ -0,0 +1,28 @@
+package fake
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// idAlphabet are the characters used by 1password on its object IDs.
+const idAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// idLength is the length of 1password object IDs (e.g: `ytrfte14ysp3fz7wtcl7qmy3sy`).
+const idLength = 26
+
+// newID returns a random 1password like object ID.
+func newID() (string, error) {
+	id := make([]byte, idLength)
+	max := big.NewInt(int64(len(idAlphabet)))
+	for i := range id {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("could not generate ID: %w", err)
+		}
+		id[i] = idAlphabet[n.Int64()]
+	}
+
+	return string(id), nil
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package fake
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile blocks until the file lock is acquired, shared locks can be held by multiple
+// processes at the same time.
+func lockFile(f *os.File, shared bool) error {
+	how := syscall.LOCK_EX
+	if shared {
+		how = syscall.LOCK_SH
+	}
+	return syscall.Flock(int(f.Fd()), how)
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,329 @@
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/telemetry"
+)
+
+// RepositoryConfig is the configuration of the instrumented repository.
+type RepositoryConfig struct {
+	// Repository is the instrumented repository.
+	Repository storage.Repository
+	// TracerProvider is the OpenTelemetry tracer provider, by default the global one.
+	TracerProvider trace.TracerProvider
+	// MeterProvider is the OpenTelemetry meter provider, by default the global one.
+	MeterProvider metric.MeterProvider
+}
+
+func (c *RepositoryConfig) defaults() error {
+	if c.Repository == nil {
+		return fmt.Errorf("repository is required")
+	}
+
+	if c.TracerProvider == nil {
+		c.TracerProvider = otel.GetTracerProvider()
+	}
+
+	if c.MeterProvider == nil {
+		c.MeterProvider = otel.GetMeterProvider()
+	}
+
+	return nil
+}
+
+type repository struct {
+	next     storage.Repository
+	tracer   trace.Tracer
+	calls    metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// NewRepository returns a storage.Repository that records an OpenTelemetry span, a call counter
+// and a duration histogram for every call of the wrapped repository, by method and error class.
+func NewRepository(config RepositoryConfig) (storage.Repository, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	meter := config.MeterProvider.Meter(telemetry.InstrumentationName)
+	calls, err := meter.Int64Counter("onepasswordorg.repository.calls",
+		metric.WithDescription("The number of repository calls."),
+		metric.WithUnit("{call}"))
+	if err != nil {
+		return nil, fmt.Errorf("could not create calls counter: %w", err)
+	}
+
+	duration, err := meter.Float64Histogram("onepasswordorg.repository.duration",
+		metric.WithDescription("The duration of repository calls."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("could not create duration histogram: %w", err)
+	}
+
+	return repository{
+		next:     config.Repository,
+		tracer:   config.TracerProvider.Tracer(telemetry.InstrumentationName),
+		calls:    calls,
+		duration: duration,
+	}, nil
+}
+
+// start starts the instrumentation of a repository call, the returned func must be called
+// with the call result error.
+func (r repository) start(ctx context.Context, method string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := r.tracer.Start(ctx, "storage.Repository/"+method, trace.WithAttributes(attribute.String("method", method)))
+
+	return ctx, func(err error) {
+		errClass := errorClass(err)
+		attrs := metric.WithAttributes(attribute.String("method", method), attribute.String("error_class", errClass))
+		r.calls.Add(ctx, 1, attrs)
+		r.duration.Record(ctx, time.Since(start).Seconds(), attrs)
+
+		if err != nil {
+			span.SetAttributes(attribute.String("error_class", errClass))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// errorClass returns the class of the error so it can be used as a low cardinality attribute.
+func errorClass(err error) string {
+	switch {
+	case err == nil:
+		return "none"
+	case errors.Is(err, storage.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, storage.ErrNotSupported):
+		return "not_supported"
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
+
+func (r repository) CreateUser(ctx context.Context, user model.User) (res *model.User, err error) {
+	ctx, end := r.start(ctx, "CreateUser")
+	defer func() { end(err) }()
+
+	return r.next.CreateUser(ctx, user)
+}
+
+func (r repository) GetUserByID(ctx context.Context, id string) (res *model.User, err error) {
+	ctx, end := r.start(ctx, "GetUserByID")
+	defer func() { end(err) }()
+
+	return r.next.GetUserByID(ctx, id)
+}
+
+func (r repository) GetUserByEmail(ctx context.Context, email string) (res *model.User, err error) {
+	ctx, end := r.start(ctx, "GetUserByEmail")
+	defer func() { end(err) }()
+
+	return r.next.GetUserByEmail(ctx, email)
+}
+
+func (r repository) EnsureUser(ctx context.Context, user model.User) (res *model.User, err error) {
+	ctx, end := r.start(ctx, "EnsureUser")
+	defer func() { end(err) }()
+
+	return r.next.EnsureUser(ctx, user)
+}
+
+func (r repository) DeleteUser(ctx context.Context, id string) (err error) {
+	ctx, end := r.start(ctx, "DeleteUser")
+	defer func() { end(err) }()
+
+	return r.next.DeleteUser(ctx, id)
+}
+
+func (r repository) CreateGroup(ctx context.Context, group model.Group) (res *model.Group, err error) {
+	ctx, end := r.start(ctx, "CreateGroup")
+	defer func() { end(err) }()
+
+	return r.next.CreateGroup(ctx, group)
+}
+
+func (r repository) GetGroupByID(ctx context.Context, id string) (res *model.Group, err error) {
+	ctx, end := r.start(ctx, "GetGroupByID")
+	defer func() { end(err) }()
+
+	return r.next.GetGroupByID(ctx, id)
+}
+
+func (r repository) GetGroupByName(ctx context.Context, name string) (res *model.Group, err error) {
+	ctx, end := r.start(ctx, "GetGroupByName")
+	defer func() { end(err) }()
+
+	return r.next.GetGroupByName(ctx, name)
+}
+
+func (r repository) EnsureGroup(ctx context.Context, group model.Group) (res *model.Group, err error) {
+	ctx, end := r.start(ctx, "EnsureGroup")
+	defer func() { end(err) }()
+
+	return r.next.EnsureGroup(ctx, group)
+}
+
+func (r repository) DeleteGroup(ctx context.Context, id string) (err error) {
+	ctx, end := r.start(ctx, "DeleteGroup")
+	defer func() { end(err) }()
+
+	return r.next.DeleteGroup(ctx, id)
+}
+
+func (r repository) CreateVault(ctx context.Context, vault model.Vault) (res *model.Vault, err error) {
+	ctx, end := r.start(ctx, "CreateVault")
+	defer func() { end(err) }()
+
+	return r.next.CreateVault(ctx, vault)
+}
+
+func (r repository) GetVaultByID(ctx context.Context, id string) (res *model.Vault, err error) {
+	ctx, end := r.start(ctx, "GetVaultByID")
+	defer func() { end(err) }()
+
+	return r.next.GetVaultByID(ctx, id)
+}
+
+func (r repository) GetVaultByName(ctx context.Context, name string) (res *model.Vault, err error) {
+	ctx, end := r.start(ctx, "GetVaultByName")
+	defer func() { end(err) }()
+
+	return r.next.GetVaultByName(ctx, name)
+}
+
+func (r repository) EnsureVault(ctx context.Context, vault model.Vault) (res *model.Vault, err error) {
+	ctx, end := r.start(ctx, "EnsureVault")
+	defer func() { end(err) }()
+
+	return r.next.EnsureVault(ctx, vault)
+}
+
+func (r repository) DeleteVault(ctx context.Context, id string) (err error) {
+	ctx, end := r.start(ctx, "DeleteVault")
+	defer func() { end(err) }()
+
+	return r.next.DeleteVault(ctx, id)
+}
+
+func (r repository) ListVaultsByUser(ctx context.Context, userID string) (res *[]model.Vault, err error) {
+	ctx, end := r.start(ctx, "ListVaultsByUser")
+	defer func() { end(err) }()
+
+	return r.next.ListVaultsByUser(ctx, userID)
+}
+
+func (r repository) EnsureMembership(ctx context.Context, membership model.Membership) (err error) {
+	ctx, end := r.start(ctx, "EnsureMembership")
+	defer func() { end(err) }()
+
+	return r.next.EnsureMembership(ctx, membership)
+}
+
+func (r repository) DeleteMembership(ctx context.Context, membership model.Membership) (err error) {
+	ctx, end := r.start(ctx, "DeleteMembership")
+	defer func() { end(err) }()
+
+	return r.next.DeleteMembership(ctx, membership)
+}
+
+func (r repository) GetMembershipByID(ctx context.Context, groupID, userID string) (res *model.Membership, err error) {
+	ctx, end := r.start(ctx, "GetMembershipByID")
+	defer func() { end(err) }()
+
+	return r.next.GetMembershipByID(ctx, groupID, userID)
+}
+
+func (r repository) EnsureVaultGroupAccess(ctx context.Context, groupAccess model.VaultGroupAccess) (err error) {
+	ctx, end := r.start(ctx, "EnsureVaultGroupAccess")
+	defer func() { end(err) }()
+
+	return r.next.EnsureVaultGroupAccess(ctx, groupAccess)
+}
+
+func (r repository) DeleteVaultGroupAccess(ctx context.Context, vaultID string, groupID string) (err error) {
+	ctx, end := r.start(ctx, "DeleteVaultGroupAccess")
+	defer func() { end(err) }()
+
+	return r.next.DeleteVaultGroupAccess(ctx, vaultID, groupID)
+}
+
+func (r repository) GetVaultGroupAccessByID(ctx context.Context, vaultID string, groupID string) (res *model.VaultGroupAccess, err error) {
+	ctx, end := r.start(ctx, "GetVaultGroupAccessByID")
+	defer func() { end(err) }()
+
+	return r.next.GetVaultGroupAccessByID(ctx, vaultID, groupID)
+}
+
+func (r repository) EnsureVaultUserAccess(ctx context.Context, userAccess model.VaultUserAccess) (err error) {
+	ctx, end := r.start(ctx, "EnsureVaultUserAccess")
+	defer func() { end(err) }()
+
+	return r.next.EnsureVaultUserAccess(ctx, userAccess)
+}
+
+func (r repository) DeleteVaultUserAccess(ctx context.Context, vaultID string, userID string) (err error) {
+	ctx, end := r.start(ctx, "DeleteVaultUserAccess")
+	defer func() { end(err) }()
+
+	return r.next.DeleteVaultUserAccess(ctx, vaultID, userID)
+}
+
+func (r repository) GetVaultUserAccessByID(ctx context.Context, vaultID string, userID string) (res *model.VaultUserAccess, err error) {
+	ctx, end := r.start(ctx, "GetVaultUserAccessByID")
+	defer func() { end(err) }()
+
+	return r.next.GetVaultUserAccessByID(ctx, vaultID, userID)
+}
+
+func (r repository) CreateItem(ctx context.Context, item model.Item) (res *model.Item, err error) {
+	ctx, end := r.start(ctx, "CreateItem")
+	defer func() { end(err) }()
+
+	return r.next.CreateItem(ctx, item)
+}
+
+func (r repository) GetItemByID(ctx context.Context, id string) (res *model.Item, err error) {
+	ctx, end := r.start(ctx, "GetItemByID")
+	defer func() { end(err) }()
+
+	return r.next.GetItemByID(ctx, id)
+}
+
+func (r repository) GetItemByTitle(ctx context.Context, vaultID string, title string) (res *model.Item, err error) {
+	ctx, end := r.start(ctx, "GetItemByTitle")
+	defer func() { end(err) }()
+
+	return r.next.GetItemByTitle(ctx, vaultID, title)
+}
+
+func (r repository) EnsureItem(ctx context.Context, item model.Item) (res *model.Item, err error) {
+	ctx, end := r.start(ctx, "EnsureItem")
+	defer func() { end(err) }()
+
+	return r.next.EnsureItem(ctx, item)
+}
+
+func (r repository) DeleteItem(ctx context.Context, id string) (err error) {
+	ctx, end := r.start(ctx, "DeleteItem")
+	defer func() { end(err) }()
+
+	return r.next.DeleteItem(ctx, id)
+}
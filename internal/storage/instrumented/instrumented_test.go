@@ -0,0 +1,95 @@
+package instrumented_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/instrumented"
+)
+
+type testRepository struct {
+	storage.Repository
+}
+
+func (testRepository) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	if id == "missing" {
+		return nil, fmt.Errorf("user %q: %w", id, storage.ErrNotFound)
+	}
+	return &model.User{ID: id}, nil
+}
+
+func TestRepository(t *testing.T) {
+	tests := map[string]struct {
+		userID        string
+		expErr        bool
+		expErrorClass string
+		expStatus     codes.Code
+	}{
+		"A successful call should be recorded.": {
+			userID:        "u0",
+			expErrorClass: "none",
+			expStatus:     codes.Unset,
+		},
+
+		"A failed call should be recorded with its error class.": {
+			userID:        "missing",
+			expErr:        true,
+			expErrorClass: "not_found",
+			expStatus:     codes.Error,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			spans := tracetest.NewSpanRecorder()
+			reader := sdkmetric.NewManualReader()
+			repo, err := instrumented.NewRepository(instrumented.RepositoryConfig{
+				Repository:     testRepository{},
+				TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+				MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+			})
+			require.NoError(err)
+
+			_, err = repo.GetUserByID(context.TODO(), test.userID)
+			assert.Equal(test.expErr, err != nil)
+
+			// Check span.
+			ended := spans.Ended()
+			require.Len(ended, 1)
+			assert.Equal("storage.Repository/GetUserByID", ended[0].Name())
+			assert.Equal(test.expStatus, ended[0].Status().Code)
+
+			// Check metrics.
+			rm := metricdata.ResourceMetrics{}
+			require.NoError(reader.Collect(context.TODO(), &rm))
+			require.Len(rm.ScopeMetrics, 1)
+			gotCalls := map[string]int64{}
+			for _, m := range rm.ScopeMetrics[0].Metrics {
+				if m.Name != "onepasswordorg.repository.calls" {
+					continue
+				}
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					method, _ := dp.Attributes.Value(attribute.Key("method"))
+					errClass, _ := dp.Attributes.Value(attribute.Key("error_class"))
+					gotCalls[method.AsString()+"/"+errClass.AsString()] += dp.Value
+				}
+			}
+			assert.Equal(map[string]int64{"GetUserByID/" + test.expErrorClass: 1}, gotCalls)
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package onepasswordcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrUnmatchedOpCmd is returned by the replaying OpCli when a command is not on the cassette.
+var ErrUnmatchedOpCmd = errors.New("op command not recorded on cassette")
+
+// cassetteTemplateArg replaces the item template paths on the cassettes, they are random
+// temporary files, the template content is recorded instead.
+const cassetteTemplateArg = "<template>"
+
+// cassette is a recording of op CLI commands.
+type cassette struct {
+	OpVersion    string                `json:"opVersion,omitempty"`
+	Interactions []cassetteInteraction `json:"interactions"`
+}
+
+// cassetteInteraction is a recorded op CLI command, without secrets.
+type cassetteInteraction struct {
+	Args []string `json:"args"`
+	// Template is the item template used by the command (`--template`).
+	Template json.RawMessage `json:"template,omitempty"`
+	Stdout   string          `json:"stdout"`
+	Stderr   string          `json:"stderr,omitempty"`
+	// ExitCode is the op exit code, -1 if the command failed without exit code (e.g: killed).
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+}
+
+// RecordingOpCliConfig is the configuration of the recording OpCli.
+type RecordingOpCliConfig struct {
+	// Cli is the OpCli whose commands will be recorded.
+	Cli OpCli
+	// CassettePath is the file where the commands will be recorded, it's rewritten after every command.
+	// If it already exists the commands are appended, so multiple runs (e.g: plan and apply) can be
+	// recorded on the same cassette.
+	CassettePath string
+	// Secrets are the values that will be scrubbed from the cassette (e.g: password, secret key).
+	Secrets []string
+}
+
+func (c *RecordingOpCliConfig) defaults() error {
+	if c.Cli == nil {
+		return fmt.Errorf("op cli is required")
+	}
+
+	if c.CassettePath == "" {
+		return fmt.Errorf("cassette path is required")
+	}
+
+	return nil
+}
+
+type recordingOpCli struct {
+	cli          OpCli
+	cassettePath string
+	secrets      []string
+
+	mu       sync.Mutex
+	cassette cassette
+}
+
+// NewRecordingOpCli returns an OpCli that records the executed commands (args, item templates,
+// stdout, stderr and exit code) into a cassette file, so they can be replayed on tests with
+// the replaying OpCli.
+//
+// The secrets and the item field values are scrubbed from the cassette.
+func NewRecordingOpCli(config RecordingOpCliConfig) (OpCli, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	c := cassette{}
+	data, err := os.ReadFile(config.CassettePath)
+	switch {
+	case err == nil:
+		err = json.Unmarshal(data, &c)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal cassette: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("could not read cassette: %w", err)
+	}
+
+	r := &recordingOpCli{
+		cli:          config.Cli,
+		cassettePath: config.CassettePath,
+		secrets:      config.Secrets,
+		cassette:     c,
+	}
+	if v := opVersionOf(config.Cli); v != (OpVersion{}) {
+		r.cassette.OpVersion = v.String()
+	}
+
+	return r, nil
+}
+
+func (r *recordingOpCli) OpVersion() OpVersion { return opVersionOf(r.cli) }
+
+func (r *recordingOpCli) RunOpCmd(ctx context.Context, args []string) (stdout, stderr string, err error) {
+	// The template is removed after the command by the repository, read it before.
+	template, terr := cassetteTemplate(args, r.secrets)
+
+	stdout, stderr, err = r.cli.RunOpCmd(ctx, args)
+
+	if terr != nil {
+		return stdout, stderr, fmt.Errorf("could not record op command template: %w", terr)
+	}
+
+	interaction := cassetteInteraction{
+		Args:     cassetteArgs(args, r.secrets),
+		Template: template,
+		Stdout:   scrubCassetteOutput(stdout, r.secrets),
+		Stderr:   scrubCassetteSecrets(stderr, r.secrets),
+	}
+	if err != nil {
+		interaction.ExitCode = -1
+		interaction.Error = scrubCassetteSecrets(err.Error(), r.secrets)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			interaction.ExitCode = exitErr.ExitCode()
+			interaction.Error = ""
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	werr := writeCassette(r.cassettePath, r.cassette)
+	if werr != nil {
+		return stdout, stderr, fmt.Errorf("could not record op command: %w", werr)
+	}
+
+	return stdout, stderr, err
+}
+
+// ReplayingOpCliConfig is the configuration of the replaying OpCli.
+type ReplayingOpCliConfig struct {
+	// CassettePath is the file of the recorded commands.
+	CassettePath string
+}
+
+func (c *ReplayingOpCliConfig) defaults() error {
+	if c.CassettePath == "" {
+		return fmt.Errorf("cassette path is required")
+	}
+
+	return nil
+}
+
+type replayingOpCli struct {
+	version OpVersion
+
+	mu           sync.Mutex
+	interactions []cassetteInteraction
+	replayed     []bool
+}
+
+// NewReplayingOpCli returns an OpCli that serves the commands recorded on a cassette by the
+// recording OpCli, without executing op.
+//
+// A command is served with the first recorded interaction with the same args (and item template)
+// that has not been replayed yet, if there isn't one, it fails with `ErrUnmatchedOpCmd`.
+func NewReplayingOpCli(config ReplayingOpCliConfig) (OpCli, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	data, err := os.ReadFile(config.CassettePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read cassette: %w", err)
+	}
+
+	c := cassette{}
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal cassette: %w", err)
+	}
+
+	var version OpVersion
+	if c.OpVersion != "" {
+		version, err = parseOpVersion(c.OpVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &replayingOpCli{
+		version:      version,
+		interactions: c.Interactions,
+		replayed:     make([]bool, len(c.Interactions)),
+	}, nil
+}
+
+func (r *replayingOpCli) OpVersion() OpVersion { return r.version }
+
+func (r *replayingOpCli) RunOpCmd(ctx context.Context, args []string) (stdout, stderr string, err error) {
+	template, err := cassetteTemplate(args, nil)
+	if err != nil {
+		return "", "", err
+	}
+	cargs := cassetteArgs(args, nil)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.replayed[i] || !reflect.DeepEqual(interaction.Args, cargs) || !sameCassetteTemplate(interaction.Template, template) {
+			continue
+		}
+		r.replayed[i] = true
+
+		switch {
+		case interaction.ExitCode > 0:
+			err = fmt.Errorf("exit status %d", interaction.ExitCode)
+		case interaction.ExitCode < 0:
+			err = errors.New(interaction.Error)
+		}
+
+		return interaction.Stdout, interaction.Stderr, err
+	}
+
+	return "", "", fmt.Errorf("%w: %s", ErrUnmatchedOpCmd, strings.Join(cargs, " "))
+}
+
+// cassetteArgs returns the args as they are recorded, without secrets and template paths.
+func cassetteArgs(args []string, secrets []string) []string {
+	cargs := redactOpArgs(args)
+	for i := range cargs {
+		if i > 0 && cargs[i-1] == "--template" {
+			cargs[i] = cassetteTemplateArg
+			continue
+		}
+		cargs[i] = scrubCassetteSecrets(cargs[i], secrets)
+	}
+
+	return cargs
+}
+
+// cassetteTemplate returns the item template of the command as it's recorded, nil if the command
+// doesn't have one.
+func cassetteTemplate(args []string, secrets []string) (json.RawMessage, error) {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] != "--template" {
+			continue
+		}
+
+		data, err := os.ReadFile(args[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("could not read template: %w", err)
+		}
+
+		return json.RawMessage(scrubCassetteOutput(string(data), secrets)), nil
+	}
+
+	return nil, nil
+}
+
+// sameCassetteTemplate returns true if both templates are the same JSON, ignoring the format.
+func sameCassetteTemplate(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
+
+// scrubCassetteOutput returns the op output without secrets, if it's JSON, the item field values
+// are removed too, and it's formatted the same way every time.
+func scrubCassetteOutput(s string, secrets []string) string {
+	s = scrubCassetteSecrets(s, secrets)
+
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if dec.Decode(&v) != nil {
+		return s
+	}
+
+	data, err := marshalCassetteJSON(scrubCassetteItemFieldValues(v, false), "")
+	if err != nil {
+		return s
+	}
+
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// scrubCassetteItemFieldValues replaces the values of the item fields (the objects of `fields` arrays).
+func scrubCassetteItemFieldValues(v interface{}, isField bool) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			switch {
+			case isField && k == "value":
+				t[k] = redactedValue
+			case k == "fields":
+				if fields, ok := e.([]interface{}); ok {
+					for i, f := range fields {
+						fields[i] = scrubCassetteItemFieldValues(f, true)
+					}
+				}
+			default:
+				t[k] = scrubCassetteItemFieldValues(e, false)
+			}
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = scrubCassetteItemFieldValues(e, false)
+		}
+	}
+
+	return v
+}
+
+func scrubCassetteSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redactedValue)
+		}
+	}
+
+	return s
+}
+
+func writeCassette(path string, c cassette) error {
+	data, err := marshalCassetteJSON(c, "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal cassette: %w", err)
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// marshalCassetteJSON marshals without escaping HTML characters, so the cassettes are readable.
+func marshalCassetteJSON(v interface{}, indent string) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
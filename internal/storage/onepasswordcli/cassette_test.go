@@ -0,0 +1,261 @@
+package onepasswordcli_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclimock"
+)
+
+var (
+	updateCassettes = flag.Bool("update-cassettes", false, "Record the testdata cassettes again using the fake op cli.")
+	realOpCassettes = flag.Bool("real-op", false, "Record the cassettes with the real op cli and account set on the OP_CASSETTE_* env vars (used with -update-cassettes).")
+)
+
+// Env vars of the real account used to record the cassettes with `-update-cassettes -real-op`, use a
+// test account, the tests create and delete users, groups, vaults and items.
+const (
+	envVarCassetteOpCliPath   = "OP_CASSETTE_CLI_PATH"
+	envVarCassetteOpAddress   = "OP_CASSETTE_ADDRESS"
+	envVarCassetteOpEmail     = "OP_CASSETTE_EMAIL"
+	envVarCassetteOpSecretKey = "OP_CASSETTE_SECRET_KEY"
+	envVarCassetteOpPassword  = "OP_CASSETTE_PASSWORD"
+)
+
+func TestRecordingOpCliScrubsSecrets(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	cassettePath := filepath.Join(dir, "cassette.json")
+	templatePath := filepath.Join(dir, "template.json")
+	require.NoError(os.WriteFile(templatePath, []byte(`{"title":"test","fields":[{"id":"password","value":"item-secret"}]}`), 0600))
+
+	editArgs := []string{"item", "edit", "i1", "--template", templatePath, "--format", "json"}
+	getArgs := []string{"item", "get", "i1", "--format", "json"}
+	mc := &onepasswordclimock.OpCli{}
+	mc.On("RunOpCmd", mock.Anything, editArgs).Once().Return(`{"id":"i1","fields":[{"id":"password","value":"item-secret"}]}`, "", nil)
+	mc.On("RunOpCmd", mock.Anything, getArgs).Once().Return("", "[ERROR] wrong password test-password", errors.New("op command killed"))
+
+	cli, err := onepasswordcli.NewRecordingOpCli(onepasswordcli.RecordingOpCliConfig{
+		Cli:          mc,
+		CassettePath: cassettePath,
+		Secrets:      []string{"test-password"},
+	})
+	require.NoError(err)
+
+	// The recording OpCli returns the real output.
+	stdout, _, err := cli.RunOpCmd(context.TODO(), editArgs)
+	require.NoError(err)
+	assert.Contains(stdout, "item-secret")
+	_, _, err = cli.RunOpCmd(context.TODO(), getArgs)
+	assert.Error(err)
+	mc.AssertExpectations(t)
+
+	// The cassette doesn't have secrets nor the template path.
+	data, err := os.ReadFile(cassettePath)
+	require.NoError(err)
+	assert.NotContains(string(data), "item-secret")
+	assert.NotContains(string(data), "test-password")
+	assert.NotContains(string(data), templatePath)
+
+	// The replaying OpCli serves the recorded commands once.
+	cli, err = onepasswordcli.NewReplayingOpCli(onepasswordcli.ReplayingOpCliConfig{CassettePath: cassettePath})
+	require.NoError(err)
+
+	stdout, _, err = cli.RunOpCmd(context.TODO(), editArgs)
+	require.NoError(err)
+	assert.Equal(`{"fields":[{"id":"password","value":"***"}],"id":"i1"}`, stdout)
+
+	_, stderr, err := cli.RunOpCmd(context.TODO(), getArgs)
+	assert.EqualError(err, "op command killed")
+	assert.Equal("[ERROR] wrong password ***", stderr)
+
+	_, _, err = cli.RunOpCmd(context.TODO(), getArgs)
+	assert.ErrorIs(err, onepasswordcli.ErrUnmatchedOpCmd)
+
+	// A different template doesn't match.
+	require.NoError(os.WriteFile(templatePath, []byte(`{"title":"other","fields":[]}`), 0600))
+	_, _, err = cli.RunOpCmd(context.TODO(), editArgs)
+	assert.ErrorIs(err, onepasswordcli.ErrUnmatchedOpCmd)
+}
+
+// newRecordingOpCli returns the op cli used to record the cassettes and the secrets to scrub, the fake
+// op cli by default, or the real op cli signed in the account of the `OP_CASSETTE_*` env vars with `-real-op`
+// (skipping the test if not set).
+func newRecordingOpCli(t *testing.T) (cli onepasswordcli.OpCli, secrets []string) {
+	if !*realOpCassettes {
+		return newFakeGoOpCli(t), []string{fakeOpSecretKey, fakeOpPassword}
+	}
+
+	address := os.Getenv(envVarCassetteOpAddress)
+	email := os.Getenv(envVarCassetteOpEmail)
+	secretKey := os.Getenv(envVarCassetteOpSecretKey)
+	password := os.Getenv(envVarCassetteOpPassword)
+	if address == "" || email == "" || secretKey == "" || password == "" {
+		t.Skipf("%s, %s, %s and %s env vars are required to record the cassettes with the real op cli",
+			envVarCassetteOpAddress, envVarCassetteOpEmail, envVarCassetteOpSecretKey, envVarCassetteOpPassword)
+	}
+
+	cli, err := onepasswordcli.NewOpCli(context.TODO(), os.Getenv(envVarCassetteOpCliPath), t.TempDir(), address, email, secretKey, password, "")
+	require.NoError(t, err)
+
+	return cli, []string{secretKey, password, email}
+}
+
+// newCassetteRepository returns a repository that replays the cassette of the test from testdata,
+// with `-update-cassettes` the cassette is recorded again (see `newRecordingOpCli`).
+func newCassetteRepository(t *testing.T) *onepasswordcli.Repository {
+	cassettePath := filepath.Join("testdata", "cassettes", strings.ReplaceAll(t.Name(), "/", "_")+".json")
+
+	var cli onepasswordcli.OpCli
+	var err error
+	if *updateCassettes {
+		recordCli, secrets := newRecordingOpCli(t)
+
+		// Recording appends to the cassette.
+		err = os.Remove(cassettePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			t.Fatal(err)
+		}
+		cli, err = onepasswordcli.NewRecordingOpCli(onepasswordcli.RecordingOpCliConfig{
+			Cli:          recordCli,
+			CassettePath: cassettePath,
+			Secrets:      secrets,
+		})
+	} else {
+		cli, err = onepasswordcli.NewReplayingOpCli(onepasswordcli.ReplayingOpCliConfig{CassettePath: cassettePath})
+	}
+	require.NoError(t, err)
+
+	repo, err := onepasswordcli.NewRepository(cli)
+	require.NoError(t, err)
+
+	return repo
+}
+
+// TestRepositoryCassettes replays the op commands of the repository operations. The checked in cassettes
+// are recorded with the fake op cli, they don't catch drifts from the real op cli output until they are
+// recorded again with `-real-op`.
+func TestRepositoryCassettes(t *testing.T) {
+	tests := map[string]func(t *testing.T, repo *onepasswordcli.Repository){
+		"users": func(t *testing.T, repo *onepasswordcli.Repository) {
+			ctx := context.TODO()
+
+			user, err := repo.CreateUser(ctx, model.User{Email: "tf-cassettes@example.com", Name: "User"})
+			require.NoError(t, err)
+			assert.Equal(t, "User", user.Name)
+
+			user.Name = "User renamed"
+			_, err = repo.EnsureUser(ctx, *user)
+			require.NoError(t, err)
+
+			got, err := repo.GetUserByEmail(ctx, "tf-cassettes@example.com")
+			require.NoError(t, err)
+			assert.Equal(t, user, got)
+
+			require.NoError(t, repo.DeleteUser(ctx, user.ID))
+			_, err = repo.GetUserByID(ctx, user.ID)
+			assert.ErrorIs(t, err, storage.ErrNotFound)
+		},
+
+		"groups": func(t *testing.T, repo *onepasswordcli.Repository) {
+			ctx := context.TODO()
+
+			user, err := repo.CreateUser(ctx, model.User{Email: "tf-cassettes@example.com", Name: "User"})
+			require.NoError(t, err)
+			group, err := repo.CreateGroup(ctx, model.Group{Name: "tf-cassettes-group", Description: "Group"})
+			require.NoError(t, err)
+			_, err = repo.CreateGroup(ctx, model.Group{Name: "tf-cassettes-group"})
+			assert.Error(t, err)
+
+			membership := model.Membership{GroupID: group.ID, UserID: user.ID, Role: model.MembershipRoleManager}
+			require.NoError(t, repo.EnsureMembership(ctx, membership))
+			got, err := repo.GetMembershipByID(ctx, group.ID, user.ID)
+			require.NoError(t, err)
+			assert.Equal(t, &membership, got)
+
+			require.NoError(t, repo.DeleteMembership(ctx, membership))
+			require.NoError(t, repo.DeleteGroup(ctx, group.ID))
+			_, err = repo.GetGroupByName(ctx, "tf-cassettes-group")
+			assert.ErrorIs(t, err, storage.ErrNotFound)
+		},
+
+		"vaults": func(t *testing.T, repo *onepasswordcli.Repository) {
+			ctx := context.TODO()
+
+			group, err := repo.CreateGroup(ctx, model.Group{Name: "tf-cassettes-group"})
+			require.NoError(t, err)
+			vault, err := repo.CreateVault(ctx, model.Vault{Name: "tf-cassettes-vault", Description: "Vault"})
+			require.NoError(t, err)
+
+			access := model.VaultGroupAccess{VaultID: vault.ID, GroupID: group.ID, Permissions: model.AccessPermissions{AllowViewing: true, AllowEditing: true}}
+			require.NoError(t, repo.EnsureVaultGroupAccess(ctx, access))
+			access.Permissions = model.AccessPermissions{AllowViewing: true}
+			require.NoError(t, repo.EnsureVaultGroupAccess(ctx, access))
+			got, err := repo.GetVaultGroupAccessByID(ctx, vault.ID, group.ID)
+			require.NoError(t, err)
+			assert.Equal(t, &access, got)
+
+			require.NoError(t, repo.DeleteVaultGroupAccess(ctx, vault.ID, group.ID))
+			require.NoError(t, repo.DeleteVault(ctx, vault.ID))
+			_, err = repo.GetVaultByID(ctx, vault.ID)
+			assert.ErrorIs(t, err, storage.ErrNotFound)
+		},
+
+		"items": func(t *testing.T, repo *onepasswordcli.Repository) {
+			ctx := context.TODO()
+
+			vault, err := repo.CreateVault(ctx, model.Vault{Name: "tf-cassettes-vault"})
+			require.NoError(t, err)
+
+			section := model.Section{ID: "s1", Label: "Section"}
+			item, err := repo.CreateItem(ctx, model.Item{
+				Vault:    *vault,
+				Title:    "tf-cassettes-item",
+				Category: "login",
+				Sections: []model.Section{section},
+				Fields: []model.Field{
+					{ID: "password", Type: "CONCEALED", Purpose: "PASSWORD", Label: "password", Value: "item-secret"},
+					{ID: "f1", Type: "STRING", Label: "field", Value: "value", Section: &section},
+				},
+			})
+			require.NoError(t, err)
+
+			// Remove the section field.
+			item.Fields = item.Fields[:1]
+			item.Sections = []model.Section{}
+			_, err = repo.EnsureItem(ctx, *item)
+			require.NoError(t, err)
+
+			// Field values are not on the cassettes.
+			got, err := repo.GetItemByTitle(ctx, vault.ID, "tf-cassettes-item")
+			require.NoError(t, err)
+			require.Len(t, got.Fields, 1)
+			assert.Equal(t, "password", got.Fields[0].Label)
+			assert.Empty(t, got.Sections)
+
+			require.NoError(t, repo.DeleteItem(ctx, item.ID))
+			_, err = repo.GetItemByID(ctx, item.ID)
+			assert.ErrorIs(t, err, storage.ErrNotFound)
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newCassetteRepository(t))
+		})
+	}
+}
@@ -132,6 +132,11 @@ func (o *onePasswordCliCmd) VaultFlag(id string) *onePasswordCliCmd {
 	return o
 }
 
+func (o *onePasswordCliCmd) TemplateFlag(path string) *onePasswordCliCmd {
+	o.args = append(o.args, "--template", path)
+	return o
+}
+
 func (o *onePasswordCliCmd) NoInputFlag() *onePasswordCliCmd {
 	o.args = append(o.args, "--no-input")
 	return o
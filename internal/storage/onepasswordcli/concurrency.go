@@ -0,0 +1,159 @@
+package onepasswordcli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ConcurrencyLimitOpCliConfig is the configuration of the concurrency limited OpCli.
+type ConcurrencyLimitOpCliConfig struct {
+	// Cli is the OpCli that will execute the commands.
+	Cli OpCli
+	// MaxConcurrentOperations is the maximum number of op commands that can run at the same time.
+	MaxConcurrentOperations int
+}
+
+func (c *ConcurrencyLimitOpCliConfig) defaults() error {
+	if c.Cli == nil {
+		return fmt.Errorf("op cli is required")
+	}
+
+	if c.MaxConcurrentOperations < 0 {
+		return fmt.Errorf("max concurrent operations can't be negative")
+	}
+
+	if c.MaxConcurrentOperations == 0 {
+		c.MaxConcurrentOperations = 4
+	}
+
+	return nil
+}
+
+type concurrencyLimitOpCli struct {
+	cli       OpCli
+	semaphore chan struct{}
+
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+// NewConcurrencyLimitOpCli returns an OpCli that limits the number of op commands running at
+// the same time, op processes share the session and config dir and fail randomly when too many
+// of them run concurrently.
+//
+// Apart from that, the mutating commands (create, edit, grant...) on the same vault or group are
+// serialized, so grants and revokes on the same vault never interleave.
+func NewConcurrencyLimitOpCli(config ConcurrencyLimitOpCliConfig) (OpCli, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return &concurrencyLimitOpCli{
+		cli:       config.Cli,
+		semaphore: make(chan struct{}, config.MaxConcurrentOperations),
+		locks:     map[string]chan struct{}{},
+	}, nil
+}
+
+// OpVersion returns the op CLI version of the wrapped OpCli.
+func (c *concurrencyLimitOpCli) OpVersion() OpVersion { return opVersionOf(c.cli) }
+
+func (c *concurrencyLimitOpCli) RunOpCmd(ctx context.Context, args []string) (stdout, stderr string, err error) {
+	// Lock the mutated resources before taking a slot, so we don't block other
+	// commands while waiting for them.
+	for _, key := range mutatedResourceKeys(args) {
+		unlock, err := c.lock(ctx, key)
+		if err != nil {
+			return "", "", err
+		}
+		defer unlock()
+	}
+
+	select {
+	case c.semaphore <- struct{}{}:
+	case <-ctx.Done():
+		return "", "", fmt.Errorf("waiting for an op cli slot: %w", ctx.Err())
+	}
+	defer func() { <-c.semaphore }()
+
+	return c.cli.RunOpCmd(ctx, args)
+}
+
+func (c *concurrencyLimitOpCli) lock(ctx context.Context, key string) (unlock func(), err error) {
+	c.mu.Lock()
+	l, ok := c.locks[key]
+	if !ok {
+		l = make(chan struct{}, 1)
+		c.locks[key] = l
+	}
+	c.mu.Unlock()
+
+	select {
+	case l <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for %s lock: %w", key, ctx.Err())
+	}
+
+	return func() { <-l }, nil
+}
+
+var mutatingOpCmds = map[string]bool{
+	"create":    true,
+	"edit":      true,
+	"delete":    true,
+	"grant":     true,
+	"revoke":    true,
+	"provision": true,
+}
+
+// mutatedResourceKeys returns the sorted keys of the vaults and groups mutated by the op command
+// (e.g: `vault/xxxxxx`), sorted so the locks are always acquired in the same order.
+// Not mutating commands don't return keys.
+func mutatedResourceKeys(args []string) []string {
+	// Get the command (e.g: [vault group grant] and the first positional argument).
+	cmd := []string{}
+	i := 0
+	for ; i < len(args) && len(cmd) < 3; i++ {
+		if len(args[i]) > 0 && args[i][0] == '-' {
+			break
+		}
+		cmd = append(cmd, args[i])
+	}
+
+	mutating := false
+	for _, c := range cmd {
+		if mutatingOpCmds[c] {
+			mutating = true
+		}
+	}
+	if !mutating {
+		return nil
+	}
+
+	keys := map[string]bool{}
+
+	// Vault and group commands with the ID as an argument (e.g: `vault delete xxxxxx`).
+	if len(cmd) == 3 && (cmd[0] == "vault" || cmd[0] == "group") && mutatingOpCmds[cmd[1]] {
+		keys[cmd[0]+"/"+cmd[2]] = true
+	}
+
+	for ; i < len(args)-1; i++ {
+		switch args[i] {
+		case "--vault":
+			keys["vault/"+args[i+1]] = true
+		case "--group":
+			keys["group/"+args[i+1]] = true
+		}
+	}
+
+	res := make([]string, 0, len(keys))
+	for k := range keys {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+
+	return res
+}
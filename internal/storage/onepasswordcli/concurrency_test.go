@@ -0,0 +1,147 @@
+package onepasswordcli_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclimock"
+)
+
+func TestConcurrencyLimitOpCliRunOpCmd(t *testing.T) {
+	tests := map[string]struct {
+		maxConcurrent int
+		cmds          []string
+		expMaxRunning int
+	}{
+		"Commands should be limited to the max concurrent operations.": {
+			maxConcurrent: 2,
+			cmds: []string{
+				"user get u0 --format json",
+				"user get u1 --format json",
+				"user get u2 --format json",
+				"user get u3 --format json",
+				"user get u4 --format json",
+			},
+			expMaxRunning: 2,
+		},
+
+		"Reading commands on the same vault should run concurrently.": {
+			maxConcurrent: 5,
+			cmds: []string{
+				"vault group list v0 --format json",
+				"vault user list v0 --format json",
+				"vault get v0 --format json",
+			},
+			expMaxRunning: 3,
+		},
+
+		"Mutating commands on the same vault should be serialized.": {
+			maxConcurrent: 5,
+			cmds: []string{
+				"vault group grant --vault v0 --group g0 --no-input --permissions allow_viewing",
+				"vault group revoke --vault v0 --group g1",
+				"vault user grant --vault v0 --user u0 --no-input --permissions allow_viewing",
+				"vault edit v0 --name test",
+			},
+			expMaxRunning: 1,
+		},
+
+		"Mutating commands on the same group should be serialized.": {
+			maxConcurrent: 5,
+			cmds: []string{
+				"group user grant --user u0 --group g0 --role member",
+				"group user revoke --user u1 --group g0",
+				"group delete g0",
+			},
+			expMaxRunning: 1,
+		},
+
+		"Mutating commands on different vaults should run concurrently.": {
+			maxConcurrent: 5,
+			cmds: []string{
+				"vault group grant --vault v0 --group g0 --no-input --permissions allow_viewing",
+				"vault group grant --vault v1 --group g1 --no-input --permissions allow_viewing",
+				"vault group grant --vault v2 --group g2 --no-input --permissions allow_viewing",
+			},
+			expMaxRunning: 3,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			var mu sync.Mutex
+			running, maxRunning := 0, 0
+
+			m := &onepasswordclimock.OpCli{}
+			m.On("RunOpCmd", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+
+				time.Sleep(50 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}).Return("", "", nil)
+
+			cli, err := onepasswordcli.NewConcurrencyLimitOpCli(onepasswordcli.ConcurrencyLimitOpCliConfig{
+				Cli:                     m,
+				MaxConcurrentOperations: test.maxConcurrent,
+			})
+			require.NoError(err)
+
+			var wg sync.WaitGroup
+			for _, cmd := range test.cmds {
+				wg.Add(1)
+				go func(cmd string) {
+					defer wg.Done()
+					_, _, err := cli.RunOpCmd(context.TODO(), strings.Fields(cmd))
+					assert.NoError(err)
+				}(cmd)
+			}
+			wg.Wait()
+
+			assert.Equal(test.expMaxRunning, maxRunning)
+		})
+	}
+}
+
+func TestConcurrencyLimitOpCliRunOpCmdContextCancel(t *testing.T) {
+	require := require.New(t)
+
+	block := make(chan struct{})
+	m := &onepasswordclimock.OpCli{}
+	m.On("RunOpCmd", mock.Anything, mock.Anything).Run(func(mock.Arguments) { <-block }).Return("", "", nil)
+
+	cli, err := onepasswordcli.NewConcurrencyLimitOpCli(onepasswordcli.ConcurrencyLimitOpCliConfig{
+		Cli:                     m,
+		MaxConcurrentOperations: 1,
+	})
+	require.NoError(err)
+
+	go func() { _, _, _ = cli.RunOpCmd(context.TODO(), []string{"user", "list"}) }()
+	time.Sleep(10 * time.Millisecond)
+
+	// The slot is taken, so waiting for it should end with the context.
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = cli.RunOpCmd(ctx, []string{"user", "list"})
+	require.Error(err)
+
+	close(block)
+}
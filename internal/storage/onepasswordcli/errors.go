@@ -0,0 +1,54 @@
+package onepasswordcli
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+// notFoundStderrRegexp matches the op CLI error messages that are returned when the
+// requested object doesn't exist, e.g:
+//
+//	[ERROR] 2022/03/17 10:00:00 "test" isn't a group in this account. Specify the group with its UUID or name.
+//	[ERROR] 2022/03/17 10:00:00 "test" isn't an item in the "test" vault. Specify the item with its UUID, name, or domain.
+//	[ERROR] 2022/03/17 10:00:00 "test" isn't a member of the "test" group
+//	[ERROR] 2022/03/17 10:00:00 "test" doesn't have access to the "test" vault
+//
+// Only the object messages are matched, other not found errors (e.g: accounts, config dir)
+// are not about the object and must not be handled as a missing object.
+var notFoundStderrRegexp = regexp.MustCompile(`(?i)"[^"]*" (isn't (a (user|group|vault)|an item) in (this account|the "[^"]*" vault)|isn't a member of the "[^"]*" group|doesn't have access to the "[^"]*" vault)`)
+
+// opCliCmdError returns the error for a failed op CLI command based on its stderr,
+// if the command failed because the object doesn't exist the error will wrap
+// `storage.ErrNotFound`.
+func opCliCmdError(err error, stderr string) error {
+	if notFoundStderrRegexp.MatchString(stderr) {
+		return fmt.Errorf("op cli command failed: %w: %s: %s", storage.ErrNotFound, err, stderr)
+	}
+
+	return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+}
+
+var (
+	// retryableStderrRegexp matches the op CLI error messages of transient errors.
+	retryableStderrRegexp = regexp.MustCompile(`(?i)(too many requests|rate limit|\b(429|502|503|504)\b|service unavailable|bad gateway|gateway timeout|timed? ?out|connection reset|connection refused|broken pipe|no such host|temporary failure|unexpected EOF|network is unreachable)`)
+	// rateLimitedStderrRegexp matches the op CLI error messages of rate limited requests.
+	rateLimitedStderrRegexp = regexp.MustCompile(`(?i)(too many requests|rate limit|\b429\b)`)
+	// notAppliedStderrRegexp matches the op CLI error messages of transient errors where the request
+	// never reached the server or was rejected before being applied.
+	notAppliedStderrRegexp = regexp.MustCompile(`(?i)(too many requests|rate limit|\b429\b|connection refused|no such host|temporary failure in name resolution|network is unreachable)`)
+)
+
+// classifyOpCliError classifies a failed op CLI command based on its stderr.
+func classifyOpCliError(stderr string) (retryable, rateLimited bool) {
+	rateLimited = rateLimitedStderrRegexp.MatchString(stderr)
+	retryable = rateLimited || retryableStderrRegexp.MatchString(stderr)
+	return retryable, rateLimited
+}
+
+// sessionExpiredStderrRegexp matches the op CLI error messages returned when the session
+// is not valid anymore (op sessions expire after 30m of inactivity), e.g:
+//
+//	[ERROR] 2022/03/17 10:00:00 You are not currently signed in. Please run `op signin --help` for instructions
+var sessionExpiredStderrRegexp = regexp.MustCompile(`(?i)(not currently signed in|session (has )?expired|invalid session|authentication required|\(401\) unauthorized)`)
@@ -0,0 +1,170 @@
+package onepasswordcli_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclitest"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/storagetest"
+)
+
+// newFakeGoOpCli returns an OpCli using the fake op CLI, signed in with a new account.
+func newFakeGoOpCli(t *testing.T) onepasswordcli.OpCli {
+	t.Setenv("TFC_RUN_ID", "")
+	t.Setenv(onepasswordclitest.EnvVarFakeOpStoragePath, filepath.Join(t.TempDir(), "storage.json"))
+	binPath := onepasswordclitest.BuildFakeOp(t)
+
+	cli, err := onepasswordcli.NewOpCli(context.TODO(), binPath, t.TempDir(), "test.1password.com", "test@example.com", fakeOpSecretKey, fakeOpPassword, "")
+	require.NoError(t, err)
+
+	return cli
+}
+
+const (
+	fakeOpSecretKey = "test-secret-key"
+	fakeOpPassword  = "test-password"
+)
+
+// newFakeOpRepository returns a repository using the fake op CLI, signed in with a new account.
+func newFakeOpRepository(t *testing.T) *onepasswordcli.Repository {
+	repo, err := onepasswordcli.NewRepository(newFakeGoOpCli(t))
+	require.NoError(t, err)
+
+	return repo
+}
+
+func TestRepositoryFakeOp(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+	ctx := context.TODO()
+
+	repo := newFakeOpRepository(t)
+	assert.Equal(onepasswordcli.OpVersion{Major: 2, Minor: 24}, repo.OpVersion())
+
+	// Users.
+	user, err := repo.CreateUser(ctx, model.User{Email: "user@example.com", Name: "User"})
+	require.NoError(err)
+	user.Name = "User renamed"
+	_, err = repo.EnsureUser(ctx, *user)
+	require.NoError(err)
+	gotUser, err := repo.GetUserByEmail(ctx, "user@example.com")
+	require.NoError(err)
+	assert.Equal(user, gotUser)
+
+	// Groups and members.
+	group, err := repo.CreateGroup(ctx, model.Group{Name: "group", Description: "Group"})
+	require.NoError(err)
+	_, err = repo.CreateGroup(ctx, model.Group{Name: "group"})
+	assert.Error(err)
+	group.Description = "Group modified"
+	_, err = repo.EnsureGroup(ctx, *group)
+	require.NoError(err)
+	gotGroup, err := repo.GetGroupByID(ctx, group.ID)
+	require.NoError(err)
+	assert.Equal(group, gotGroup)
+
+	membership := model.Membership{GroupID: group.ID, UserID: user.ID, Role: model.MembershipRoleManager}
+	require.NoError(repo.EnsureMembership(ctx, membership))
+	gotMembership, err := repo.GetMembershipByID(ctx, group.ID, user.ID)
+	require.NoError(err)
+	assert.Equal(&membership, gotMembership)
+
+	// Vaults and accesses.
+	vault, err := repo.CreateVault(ctx, model.Vault{Name: "vault", Description: "Vault"})
+	require.NoError(err)
+	vault.Name = "vault-renamed"
+	_, err = repo.EnsureVault(ctx, *vault)
+	require.NoError(err)
+	gotVault, err := repo.GetVaultByName(ctx, "vault-renamed")
+	require.NoError(err)
+	assert.Equal(vault, gotVault)
+
+	groupAccess := model.VaultGroupAccess{VaultID: vault.ID, GroupID: group.ID, Permissions: model.AccessPermissions{AllowViewing: true, AllowEditing: true}}
+	require.NoError(repo.EnsureVaultGroupAccess(ctx, groupAccess))
+	groupAccess.Permissions = model.AccessPermissions{AllowViewing: true}
+	require.NoError(repo.EnsureVaultGroupAccess(ctx, groupAccess))
+	gotGroupAccess, err := repo.GetVaultGroupAccessByID(ctx, vault.ID, group.ID)
+	require.NoError(err)
+	assert.Equal(&groupAccess, gotGroupAccess)
+
+	userAccess := model.VaultUserAccess{VaultID: vault.ID, UserID: user.ID, Permissions: model.AccessPermissions{ViewItems: true, ManageVault: true}}
+	require.NoError(repo.EnsureVaultUserAccess(ctx, userAccess))
+	gotUserAccess, err := repo.GetVaultUserAccessByID(ctx, vault.ID, user.ID)
+	require.NoError(err)
+	assert.Equal(&userAccess, gotUserAccess)
+
+	// Items.
+	section := model.Section{ID: "s1", Label: "Section"}
+	item, err := repo.CreateItem(ctx, model.Item{
+		Vault:    *vault,
+		Title:    "item",
+		Category: "login",
+		Tags:     []string{"tag"},
+		URLs:     []model.URL{{URL: "https://example.com", Primary: true}},
+		Sections: []model.Section{section},
+		Fields: []model.Field{
+			{ID: "password", Type: "CONCEALED", Purpose: "PASSWORD", Label: "password", Value: "secret"},
+			{ID: "f1", Type: "STRING", Label: "field", Value: "value", Section: &section},
+		},
+	})
+	require.NoError(err)
+	item.Fields = item.Fields[:1]
+	item.Sections = []model.Section{}
+	_, err = repo.EnsureItem(ctx, *item)
+	require.NoError(err)
+	gotItem, err := repo.GetItemByTitle(ctx, vault.ID, "item")
+	require.NoError(err)
+	assert.Equal(item, gotItem)
+
+	// Deletions.
+	require.NoError(repo.DeleteItem(ctx, item.ID))
+	require.NoError(repo.DeleteVaultUserAccess(ctx, vault.ID, user.ID))
+	require.NoError(repo.DeleteVaultGroupAccess(ctx, vault.ID, group.ID))
+	require.NoError(repo.DeleteVault(ctx, vault.ID))
+	require.NoError(repo.DeleteMembership(ctx, membership))
+	require.NoError(repo.DeleteGroup(ctx, group.ID))
+	require.NoError(repo.DeleteUser(ctx, user.ID))
+
+	_, err = repo.GetItemByID(ctx, item.ID)
+	assert.ErrorIs(err, storage.ErrNotFound)
+	_, err = repo.GetVaultGroupAccessByID(ctx, vault.ID, group.ID)
+	assert.ErrorIs(err, storage.ErrNotFound)
+	_, err = repo.GetVaultByID(ctx, vault.ID)
+	assert.ErrorIs(err, storage.ErrNotFound)
+	_, err = repo.GetMembershipByID(ctx, group.ID, user.ID)
+	assert.ErrorIs(err, storage.ErrNotFound)
+	_, err = repo.GetGroupByID(ctx, group.ID)
+	assert.ErrorIs(err, storage.ErrNotFound)
+	_, err = repo.GetUserByID(ctx, user.ID)
+	assert.ErrorIs(err, storage.ErrNotFound)
+}
+
+func TestRepositoryFakeOpConformance(t *testing.T) {
+	storagetest.RunRepositoryTests(t, func(t *testing.T) storage.Repository {
+		return newFakeOpRepository(t)
+	})
+}
+
+func TestOpCliFakeOpSignin(t *testing.T) {
+	t.Setenv("TFC_RUN_ID", "")
+	t.Setenv(onepasswordclitest.EnvVarFakeOpStoragePath, filepath.Join(t.TempDir(), "storage.json"))
+	binPath := onepasswordclitest.BuildFakeOp(t)
+	configDir := t.TempDir()
+
+	newOpCli := func(password string) error {
+		_, err := onepasswordcli.NewOpCli(context.TODO(), binPath, configDir, "test.1password.com", "test@example.com", "test-secret-key", password, "")
+		return err
+	}
+
+	// The account is added on the first signin, the next ones need the same password.
+	require.NoError(t, newOpCli("test-password"))
+	require.NoError(t, newOpCli("test-password"))
+	assert.ErrorContains(t, newOpCli("wrong-password"), "invalid password")
+}
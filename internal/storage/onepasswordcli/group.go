@@ -21,7 +21,7 @@ func (r Repository) CreateGroup(ctx context.Context, group model.Group) (*model.
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	og := opGroup{}
@@ -40,7 +40,7 @@ func (r Repository) GetGroupByID(ctx context.Context, id string) (*model.Group,
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	og := opGroup{}
@@ -60,7 +60,7 @@ func (r Repository) GetGroupByName(ctx context.Context, name string) (*model.Gro
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	og := opGroup{}
@@ -80,7 +80,7 @@ func (r Repository) EnsureGroup(ctx context.Context, group model.Group) (*model.
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	return &group, nil
@@ -91,7 +91,7 @@ func (r Repository) DeleteGroup(ctx context.Context, id string) error {
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return opCliCmdError(err, stderr)
 	}
 
 	return nil
@@ -2,6 +2,7 @@ package onepasswordcli_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclimock"
 )
@@ -88,10 +90,11 @@ func TestRepositoryCreateGroup(t *testing.T) {
 
 func TestRepositoryGetGroupByID(t *testing.T) {
 	tests := map[string]struct {
-		id       string
-		mock     func(m *onepasswordclimock.OpCli)
-		expGroup *model.Group
-		expErr   bool
+		id          string
+		mock        func(m *onepasswordclimock.OpCli)
+		expGroup    *model.Group
+		expErr      bool
+		expNotFound bool
 	}{
 		"Getting a group correctly, should return the group data.": {
 			id: "test-id",
@@ -115,6 +118,47 @@ func TestRepositoryGetGroupByID(t *testing.T) {
 			},
 			expErr: true,
 		},
+
+		"Getting a missing group, should fail with a not found error.": {
+			id: "test-id",
+			mock: func(m *onepasswordclimock.OpCli) {
+				expCmd := `group get test-id --format json`
+				stderr := `[ERROR] 2022/03/17 10:00:00 "test-id" isn't a group in this account. Specify the group with its UUID or name.`
+				m.On("RunOpCmd", mock.Anything, strings.Fields(expCmd)).Once().Return("", stderr, fmt.Errorf("exit status 1"))
+			},
+			expErr:      true,
+			expNotFound: true,
+		},
+
+		"Having a missing account error, should fail without a not found error.": {
+			id: "test-id",
+			mock: func(m *onepasswordclimock.OpCli) {
+				expCmd := `group get test-id --format json`
+				stderr := `[ERROR] 2022/03/17 10:00:00 account "test" not found, add it with "op account add"`
+				m.On("RunOpCmd", mock.Anything, strings.Fields(expCmd)).Once().Return("", stderr, fmt.Errorf("exit status 1"))
+			},
+			expErr: true,
+		},
+
+		"Having a missing config dir error, should fail without a not found error.": {
+			id: "test-id",
+			mock: func(m *onepasswordclimock.OpCli) {
+				expCmd := `group get test-id --format json`
+				stderr := `[ERROR] 2022/03/17 10:00:00 config directory "/tmp/op" does not exist`
+				m.On("RunOpCmd", mock.Anything, strings.Fields(expCmd)).Once().Return("", stderr, fmt.Errorf("exit status 1"))
+			},
+			expErr: true,
+		},
+
+		"Having a missing session file error, should fail without a not found error.": {
+			id: "test-id",
+			mock: func(m *onepasswordclimock.OpCli) {
+				expCmd := `group get test-id --format json`
+				stderr := `[ERROR] 2022/03/17 10:00:00 session file doesn't exist`
+				m.On("RunOpCmd", mock.Anything, strings.Fields(expCmd)).Once().Return("", stderr, fmt.Errorf("exit status 1"))
+			},
+			expErr: true,
+		},
 	}
 
 	for name, test := range tests {
@@ -132,6 +176,7 @@ func TestRepositoryGetGroupByID(t *testing.T) {
 
 			if test.expErr {
 				assert.Error(err)
+				assert.Equal(test.expNotFound, errors.Is(err, storage.ErrNotFound))
 			} else if assert.NoError(err) {
 				assert.Equal(test.expGroup, gotGroup)
 			}
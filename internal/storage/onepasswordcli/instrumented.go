@@ -0,0 +1,165 @@
+package onepasswordcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/telemetry"
+)
+
+// InstrumentedOpCliConfig is the configuration of the instrumented OpCli.
+type InstrumentedOpCliConfig struct {
+	// Cli is the OpCli that will execute the commands.
+	Cli OpCli
+	// TracerProvider is the OpenTelemetry tracer provider, by default the global one.
+	TracerProvider trace.TracerProvider
+	// MeterProvider is the OpenTelemetry meter provider, by default the global one.
+	MeterProvider metric.MeterProvider
+}
+
+func (c *InstrumentedOpCliConfig) defaults() error {
+	if c.Cli == nil {
+		return fmt.Errorf("op cli is required")
+	}
+
+	if c.TracerProvider == nil {
+		c.TracerProvider = otel.GetTracerProvider()
+	}
+
+	if c.MeterProvider == nil {
+		c.MeterProvider = otel.GetMeterProvider()
+	}
+
+	return nil
+}
+
+type instrumentedOpCli struct {
+	cli      OpCli
+	tracer   trace.Tracer
+	commands metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// NewInstrumentedOpCli returns an OpCli that records an OpenTelemetry span, a command counter
+// and a duration histogram for every op command, by subcommand and error class.
+func NewInstrumentedOpCli(config InstrumentedOpCliConfig) (OpCli, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	meter := config.MeterProvider.Meter(telemetry.InstrumentationName)
+	commands, err := meter.Int64Counter("onepasswordorg.op.commands",
+		metric.WithDescription("The number of executed op cli commands."),
+		metric.WithUnit("{command}"))
+	if err != nil {
+		return nil, fmt.Errorf("could not create commands counter: %w", err)
+	}
+
+	duration, err := meter.Float64Histogram("onepasswordorg.op.duration",
+		metric.WithDescription("The duration of op cli commands."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("could not create duration histogram: %w", err)
+	}
+
+	return instrumentedOpCli{
+		cli:      config.Cli,
+		tracer:   config.TracerProvider.Tracer(telemetry.InstrumentationName),
+		commands: commands,
+		duration: duration,
+	}, nil
+}
+
+// OpVersion returns the op CLI version of the wrapped OpCli.
+func (i instrumentedOpCli) OpVersion() OpVersion { return opVersionOf(i.cli) }
+
+func (i instrumentedOpCli) RunOpCmd(ctx context.Context, args []string) (stdout, stderr string, err error) {
+	subcommand := opCommandName(args)
+	start := time.Now()
+	ctx, span := i.tracer.Start(ctx, "op "+subcommand,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("op.subcommand", subcommand)))
+	defer span.End()
+
+	stdout, stderr, err = i.cli.RunOpCmd(ctx, args)
+
+	errClass := opErrorClass(ctx, stderr, err)
+	attrs := metric.WithAttributes(attribute.String("op.subcommand", subcommand), attribute.String("error_class", errClass))
+	i.commands.Add(ctx, 1, attrs)
+	i.duration.Record(ctx, time.Since(start).Seconds(), attrs)
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			span.SetAttributes(attribute.Int("op.exit_code", exitErr.ExitCode()))
+		}
+		span.SetAttributes(attribute.String("error_class", errClass))
+		// Stderr is not recorded, it could have sensitive data.
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return stdout, stderr, err
+}
+
+// opCommandWords are the words of the op commands, used to get the command without its
+// arguments (e.g: IDs).
+var opCommandWords = map[string]bool{
+	"account": true, "signin": true, "user": true, "group": true, "vault": true, "item": true,
+	"add": true, "create": true, "edit": true, "get": true, "list": true, "delete": true,
+	"grant": true, "revoke": true, "provision": true, "confirm": true, "suspend": true, "reactivate": true,
+}
+
+// opCommandName returns the op command without arguments (e.g: `user get` instead of
+// `user get xxxxxx`), so it can be used as a low cardinality attribute.
+func opCommandName(args []string) string {
+	words := []string{}
+	for _, w := range strings.Fields(opSubcommand(args)) {
+		if !opCommandWords[w] {
+			break
+		}
+		words = append(words, w)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// opErrorClass returns the class of a failed op command so it can be used as a low cardinality attribute.
+func opErrorClass(ctx context.Context, stderr string, err error) string {
+	if err == nil {
+		return "none"
+	}
+
+	if ctx.Err() != nil {
+		return "canceled"
+	}
+
+	if notFoundStderrRegexp.MatchString(stderr) {
+		return "not_found"
+	}
+
+	if sessionExpiredStderrRegexp.MatchString(stderr) {
+		return "session_expired"
+	}
+
+	retryable, rateLimited := classifyOpCliError(stderr)
+	switch {
+	case rateLimited:
+		return "rate_limited"
+	case retryable:
+		return "transient"
+	}
+
+	return "error"
+}
@@ -0,0 +1,98 @@
+package onepasswordcli_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclimock"
+)
+
+func TestInstrumentedOpCliRunOpCmd(t *testing.T) {
+	tests := map[string]struct {
+		cmd           string
+		mock          func(m *onepasswordclimock.OpCli)
+		expSpan       string
+		expErrorClass string
+	}{
+		"A successful command should be recorded.": {
+			cmd: "--session test --account test user get u0 --format json",
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, mock.Anything).Once().Return(`{}`, "", nil)
+			},
+			expSpan:       "op user get",
+			expErrorClass: "none",
+		},
+
+		"A rate limited command should be recorded with its error class.": {
+			cmd: "vault group grant --vault v0 --group g0",
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, mock.Anything).Once().Return("", "[ERROR] Too many requests", fmt.Errorf("exit status 1"))
+			},
+			expSpan:       "op vault group grant",
+			expErrorClass: "rate_limited",
+		},
+
+		"A not found command should be recorded with its error class.": {
+			cmd: "group get g0",
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, mock.Anything).Once().Return("", `[ERROR] "g0" isn't a group in this account`, fmt.Errorf("exit status 1"))
+			},
+			expSpan:       "op group get",
+			expErrorClass: "not_found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			m := &onepasswordclimock.OpCli{}
+			test.mock(m)
+
+			spans := tracetest.NewSpanRecorder()
+			reader := sdkmetric.NewManualReader()
+			cli, err := onepasswordcli.NewInstrumentedOpCli(onepasswordcli.InstrumentedOpCliConfig{
+				Cli:            m,
+				TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+				MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+			})
+			require.NoError(err)
+
+			_, _, _ = cli.RunOpCmd(context.TODO(), strings.Fields(test.cmd))
+
+			ended := spans.Ended()
+			require.Len(ended, 1)
+			assert.Equal(test.expSpan, ended[0].Name())
+
+			rm := metricdata.ResourceMetrics{}
+			require.NoError(reader.Collect(context.TODO(), &rm))
+			require.Len(rm.ScopeMetrics, 1)
+			gotErrClasses := []string{}
+			for _, m := range rm.ScopeMetrics[0].Metrics {
+				if m.Name != "onepasswordorg.op.commands" {
+					continue
+				}
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					errClass, _ := dp.Attributes.Value(attribute.Key("error_class"))
+					gotErrClasses = append(gotErrClasses, errClass.AsString())
+				}
+			}
+			assert.Equal([]string{test.expErrorClass}, gotErrClasses)
+
+			m.AssertExpectations(t)
+		})
+	}
+}
@@ -4,34 +4,32 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
 )
 
 func (r Repository) CreateItem(ctx context.Context, item model.Item) (*model.Item, error) {
-	cmdArgs := &onePasswordCliCmd{}
-	// Create Fields
-	cmdArgs.ItemArg().CreateArg()
-
-	cmdArgs.EditFieldFlag("title", item.Title)
-	cmdArgs.EditFieldFlag("url", item.URLs[0].URL)
-	cmdArgs.CategoryFlag(item.Category)
-	cmdArgs.VaultFlag(item.Vault.ID)
+	// The item content goes in a template file, so the field values are not on the op
+	// process arguments (visible to every user of the host).
+	doc, err := mergeOpItemDocument(opItemDocument{}, item)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, field := range item.Fields {
-		if field.Section == nil {
-			cmdArgs.RawStrArg(field.Label + "[" + field.Type + "]" + "=" + field.Value)
-		} else {
-			cmdArgs.RawStrArg(field.Section.Label + "." + field.Label + "[" + field.Type + "]" + "=" + field.Value)
-		}
+	templatePath, err := writeOpItemTemplate(doc)
+	if err != nil {
+		return nil, err
 	}
+	defer os.Remove(templatePath)
 
-	cmdArgs.FormatJSONFlag()
+	cmdArgs := &onePasswordCliCmd{}
+	cmdArgs.ItemArg().CreateArg().VaultFlag(item.Vault.ID).TemplateFlag(templatePath).FormatJSONFlag()
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	ou := opItem{}
@@ -51,7 +49,7 @@ func (r Repository) GetItemByID(ctx context.Context, id string) (*model.Item, er
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	ou := opItem{}
@@ -70,7 +68,7 @@ func (r Repository) GetItemByTitle(ctx context.Context, vaultID string, title st
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	ou := opItem{}
@@ -84,40 +82,53 @@ func (r Repository) GetItemByTitle(ctx context.Context, vaultID string, title st
 	return &gotItem, nil
 }
 
+// EnsureItem replaces the item content with the model one. The current item document is
+// modified and used as the template of the edit, so the item metadata the model doesn't know
+// (e.g: MENU field options) is kept, and the fields and sections that are not on the model
+// are removed.
 func (r Repository) EnsureItem(ctx context.Context, item model.Item) (*model.Item, error) {
 	cmdArgs := &onePasswordCliCmd{}
-	cmdArgs.ItemArg().EditArg().RawStrArg(item.ID)
+	cmdArgs.ItemArg().GetArg().RawStrArg(item.ID).VaultFlag(item.Vault.ID).FormatJSONFlag()
 
-	cmdArgs.EditFieldFlag("title", item.Title)
-	cmdArgs.EditFieldFlag("url", item.URLs[0].URL)
-	cmdArgs.VaultFlag(item.Vault.ID)
+	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
+	if err != nil {
+		return nil, opCliCmdError(err, stderr)
+	}
 
-	for _, field := range item.Fields {
-		if field.Section == nil {
-			cmdArgs.RawStrArg(field.Label + "[" + field.Type + "]" + "=" + field.Value)
-		} else {
-			cmdArgs.RawStrArg(field.Section.Label + "." + field.Label + "[" + field.Type + "]" + "=" + field.Value)
-		}
+	current := opItemDocument{}
+	err = json.Unmarshal([]byte(stdout), &current)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal op cli stdout: %w", err)
 	}
 
-	cmdArgs.FormatJSONFlag()
+	doc, err := mergeOpItemDocument(current, item)
+	if err != nil {
+		return nil, err
+	}
 
-	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
+	templatePath, err := writeOpItemTemplate(doc)
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, err
 	}
+	defer os.Remove(templatePath)
 
-	return &item, nil
-}
+	cmdArgs = &onePasswordCliCmd{}
+	cmdArgs.ItemArg().EditArg().RawStrArg(item.ID).VaultFlag(item.Vault.ID).TemplateFlag(templatePath).FormatJSONFlag()
 
-func vaultAndItemUUID(tfID string) (vaultUUID, itemUUID string) {
-	elements := strings.Split(tfID, "/")
+	stdout, stderr, err = r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
+	if err != nil {
+		return nil, opCliCmdError(err, stderr)
+	}
 
-	if len(elements) != 4 {
-		return "", ""
+	ou := opItem{}
+	err = json.Unmarshal([]byte(stdout), &ou)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal op cli stdout: %w", err)
 	}
 
-	return elements[1], elements[3]
+	gotItem := mapOpToModelItem(ou)
+
+	return &gotItem, nil
 }
 
 func (r Repository) DeleteItem(ctx context.Context, id string) error {
@@ -126,12 +137,150 @@ func (r Repository) DeleteItem(ctx context.Context, id string) error {
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return opCliCmdError(err, stderr)
 	}
 
 	return nil
 }
 
+// writeOpItemTemplate writes the item document as an op item template into a temporary file
+// only readable by the current user, and returns its path. The caller must remove it.
+func writeOpItemTemplate(doc opItemDocument) (path string, err error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal op item template: %w", err)
+	}
+
+	// Temp files are created with 0600 permissions.
+	f, err := os.CreateTemp("", "terraform-provider-onepasswordorg-item-*.json")
+	if err != nil {
+		return "", fmt.Errorf("could not create op item template file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(data)
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("could not write op item template file: %w", err)
+	}
+
+	return f.Name(), nil
+}
+
+// opItemDocument is an op item JSON document (`op item get --format json`), the properties
+// the provider doesn't know about are kept as they are.
+type opItemDocument map[string]json.RawMessage
+
+// mergeOpItemDocument returns the item document with the model item content. Fields and
+// sections are matched by ID, so their unknown properties are kept, and the ones that are
+// not on the model are removed.
+func mergeOpItemDocument(doc opItemDocument, item model.Item) (opItemDocument, error) {
+	merged := copyOpItemDocument(doc)
+
+	currentSections, err := opItemDocumentObjectsByID(doc, "sections")
+	if err != nil {
+		return nil, err
+	}
+	sections := []opItemDocument{}
+	for _, s := range item.Sections {
+		section := copyOpItemDocument(currentSections[s.ID])
+		setOpItemDocumentProperty(section, "id", s.ID)
+		setOpItemDocumentProperty(section, "label", s.Label)
+		sections = append(sections, section)
+	}
+
+	currentFields, err := opItemDocumentObjectsByID(doc, "fields")
+	if err != nil {
+		return nil, err
+	}
+	fields := []opItemDocument{}
+	for _, f := range item.Fields {
+		field := copyOpItemDocument(currentFields[f.ID])
+		setOpItemDocumentProperty(field, "id", f.ID)
+		setOpItemDocumentProperty(field, "type", f.Type)
+		setOpItemDocumentProperty(field, "label", f.Label)
+		setOpItemDocumentProperty(field, "value", f.Value)
+		if f.Purpose != "" {
+			setOpItemDocumentProperty(field, "purpose", f.Purpose)
+		} else {
+			delete(field, "purpose")
+		}
+		if f.Section != nil {
+			setOpItemDocumentProperty(field, "section", opSection{ID: f.Section.ID, Label: f.Section.Label})
+		} else {
+			delete(field, "section")
+		}
+		fields = append(fields, field)
+	}
+
+	urls := []opURL{}
+	for _, u := range item.URLs {
+		if u.URL != "" {
+			urls = append(urls, opURL{Label: u.Label, Primary: u.Primary, Href: u.URL})
+		}
+	}
+
+	setOpItemDocumentProperty(merged, "title", item.Title)
+	setOpItemDocumentProperty(merged, "category", strings.ToUpper(item.Category))
+	tags := item.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	setOpItemDocumentProperty(merged, "tags", tags)
+	setOpItemDocumentProperty(merged, "urls", urls)
+	setOpItemDocumentProperty(merged, "sections", sections)
+	setOpItemDocumentProperty(merged, "fields", fields)
+
+	return merged, nil
+}
+
+// opItemDocumentObjectsByID returns the objects of a document array property (e.g: fields)
+// indexed by their ID.
+func opItemDocumentObjectsByID(doc opItemDocument, property string) (map[string]opItemDocument, error) {
+	objs := map[string]opItemDocument{}
+	raw, ok := doc[property]
+	if !ok {
+		return objs, nil
+	}
+
+	list := []opItemDocument{}
+	err := json.Unmarshal(raw, &list)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal op item %s: %w", property, err)
+	}
+
+	for _, obj := range list {
+		var id string
+		_ = json.Unmarshal(obj["id"], &id)
+		if id != "" {
+			objs[id] = obj
+		}
+	}
+
+	return objs, nil
+}
+
+func copyOpItemDocument(doc opItemDocument) opItemDocument {
+	c := opItemDocument{}
+	for k, v := range doc {
+		c[k] = v
+	}
+
+	return c
+}
+
+func setOpItemDocumentProperty(doc opItemDocument, property string, v interface{}) {
+	// Models only have strings, slices and structs, they can always be marshaled.
+	data, _ := json.Marshal(v)
+	doc[property] = data
+}
+
+type opURL struct {
+	Label   string `json:"label,omitempty"`
+	Primary bool   `json:"primary"`
+	Href    string `json:"href"`
+}
+
 type opItemField struct {
 	ID      string     `json:"id"`
 	Type    string     `json:"type"`
@@ -154,6 +303,7 @@ type opItem struct {
 	Fields   []opItemField `json:"fields"`
 	Sections []opSection   `json:"sections"`
 	Tags     []string      `json:"tags"`
+	URLs     []opURL       `json:"urls"`
 }
 
 func mapOpToModelItem(u opItem) model.Item {
@@ -161,12 +311,22 @@ func mapOpToModelItem(u opItem) model.Item {
 		ID:       u.ID,
 		Title:    u.Title,
 		Tags:     u.Tags,
-		Category: u.Category,
+		Category: strings.ToLower(u.Category),
 		Vault:    mapOpToModeVault(u.Vault),
 		Fields:   mapOpToModelItemFields(u.Fields),
 		Sections: mapOpToModelItemSections(u.Sections),
+		URLs:     mapOpToModelItemURLs(u.URLs),
 	}
 }
+
+func mapOpToModelItemURLs(opURLs []opURL) []model.URL {
+	urls := []model.URL{}
+	for _, u := range opURLs {
+		urls = append(urls, model.URL{URL: u.Href, Label: u.Label, Primary: u.Primary})
+	}
+
+	return urls
+}
 func mapOpToModelSection(u *opSection) model.Section {
 	return model.Section{
 		ID:    u.ID,
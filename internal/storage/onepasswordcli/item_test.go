@@ -0,0 +1,189 @@
+package onepasswordcli_test
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclimock"
+)
+
+// testOpItem is an op item document with metadata the provider doesn't know about (e.g: MENU
+// options, password details) and labels that can't be used on op assignment statements.
+const testOpItem = `{
+  "id": "item-id",
+  "title": "test-00",
+  "version": 3,
+  "favorite": true,
+  "vault": {"id": "vault-id", "name": "test"},
+  "category": "DATABASE",
+  "tags": ["t1"],
+  "urls": [{"label": "website", "primary": true, "href": "https://test.io"}],
+  "sections": [
+    {"id": "section-id", "label": "db.config"},
+    {"id": "old-section-id", "label": "old"}
+  ],
+  "fields": [
+    {"id": "password", "type": "CONCEALED", "purpose": "PASSWORD", "label": "password", "value": "s3cr3t", "password_details": {"strength": "FANTASTIC"}},
+    {"id": "database_type", "type": "MENU", "label": "type", "value": "postgresql", "options": ["mysql", "postgresql"]},
+    {"id": "field-id", "type": "STRING", "label": "a.b=c", "value": "v", "section": {"id": "section-id", "label": "db.config"}},
+    {"id": "old-field-id", "type": "STRING", "label": "old", "value": "old", "section": {"id": "old-section-id", "label": "old"}}
+  ]
+}`
+
+// mockOpItemTemplateCmd mocks an op item command that uses a template, the template will be
+// checked and returned in tpl.
+func mockOpItemTemplateCmd(t *testing.T, m *onepasswordclimock.OpCli, expCmd string, stdout string, tpl *string) {
+	m.On("RunOpCmd", mock.Anything, mock.MatchedBy(func(args []string) bool {
+		return strings.HasPrefix(strings.Join(args, " "), strings.SplitN(expCmd, " {template}", 2)[0])
+	})).Once().Run(func(args mock.Arguments) {
+		cmdArgs := args.Get(1).([]string)
+		templatePath := ""
+		for i, arg := range cmdArgs {
+			if arg == "--template" && i+1 < len(cmdArgs) {
+				templatePath = cmdArgs[i+1]
+			}
+		}
+		require.NotEmpty(t, templatePath)
+		t.Cleanup(func() {
+			// The template file should be removed after the command.
+			_, err := os.Stat(templatePath)
+			assert.True(t, os.IsNotExist(err))
+		})
+
+		// The secrets should only be on the template file, readable only by the user.
+		assert.Equal(t, strings.Fields(strings.Replace(expCmd, "{template}", templatePath, 1)), cmdArgs)
+		for _, arg := range cmdArgs {
+			assert.NotContains(t, arg, "s3cr3t")
+		}
+
+		info, err := os.Stat(templatePath)
+		require.NoError(t, err)
+		if runtime.GOOS != "windows" {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+
+		data, err := os.ReadFile(templatePath)
+		require.NoError(t, err)
+		*tpl = string(data)
+	}).Return(stdout, "", nil)
+}
+
+func TestRepositoryCreateItem(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	section := &model.Section{ID: "section-id", Label: "db"}
+	item := model.Item{
+		Vault:    model.Vault{ID: "vault-id"},
+		Title:    "test-00",
+		Category: "login",
+		URLs:     []model.URL{{URL: "https://test.io", Primary: true}},
+		Sections: []model.Section{*section},
+		Fields: []model.Field{
+			{ID: "password", Label: "password", Purpose: "PASSWORD", Type: "CONCEALED", Value: "s3cr3t"},
+			{ID: "field-id", Label: "token", Type: "CONCEALED", Value: "t0k3n", Section: section},
+		},
+	}
+	expTemplate := `{
+  "title": "test-00",
+  "category": "LOGIN",
+  "tags": [],
+  "urls": [{"primary": true, "href": "https://test.io"}],
+  "sections": [{"id": "section-id", "label": "db"}],
+  "fields": [
+    {"id": "password", "type": "CONCEALED", "purpose": "PASSWORD", "label": "password", "value": "s3cr3t"},
+    {"id": "field-id", "type": "CONCEALED", "label": "token", "value": "t0k3n", "section": {"id": "section-id", "label": "db"}}
+  ]
+}`
+
+	mc := &onepasswordclimock.OpCli{}
+	var gotTemplate string
+	mockOpItemTemplateCmd(t, mc, `item create --vault vault-id --template {template} --format json`, `{"id":"item-id","vault":{"id":"vault-id"}}`, &gotTemplate)
+
+	repo, err := onepasswordcli.NewRepository(mc)
+	require.NoError(err)
+
+	gotItem, err := repo.CreateItem(context.TODO(), item)
+	require.NoError(err)
+	mc.AssertExpectations(t)
+
+	assert.JSONEq(expTemplate, gotTemplate)
+	assert.Equal("item-id", gotItem.ID)
+	assert.Equal("vault-id", gotItem.Vault.ID)
+}
+
+func TestRepositoryEnsureItem(t *testing.T) {
+	tests := map[string]struct {
+		modify      func(item *model.Item)
+		expTemplate string
+	}{
+		"An item without changes should be edited with the same document.": {
+			modify:      func(item *model.Item) {},
+			expTemplate: testOpItem,
+		},
+
+		"Removed fields and sections should be removed, and the unknown metadata kept.": {
+			modify: func(item *model.Item) {
+				item.Title = "test-01"
+				item.Sections = item.Sections[:1]
+				item.Fields = item.Fields[:3]
+				item.Fields[0].Value = "n3w-s3cr3t"
+			},
+			expTemplate: `{
+  "id": "item-id",
+  "title": "test-01",
+  "version": 3,
+  "favorite": true,
+  "vault": {"id": "vault-id", "name": "test"},
+  "category": "DATABASE",
+  "tags": ["t1"],
+  "urls": [{"label": "website", "primary": true, "href": "https://test.io"}],
+  "sections": [
+    {"id": "section-id", "label": "db.config"}
+  ],
+  "fields": [
+    {"id": "password", "type": "CONCEALED", "purpose": "PASSWORD", "label": "password", "value": "n3w-s3cr3t", "password_details": {"strength": "FANTASTIC"}},
+    {"id": "database_type", "type": "MENU", "label": "type", "value": "postgresql", "options": ["mysql", "postgresql"]},
+    {"id": "field-id", "type": "STRING", "label": "a.b=c", "value": "v", "section": {"id": "section-id", "label": "db.config"}}
+  ]
+}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			mc := &onepasswordclimock.OpCli{}
+			mc.On("RunOpCmd", mock.Anything, strings.Fields(`item get item-id --format json`)).Once().Return(testOpItem, "", nil)
+			mc.On("RunOpCmd", mock.Anything, strings.Fields(`item get item-id --vault vault-id --format json`)).Once().Return(testOpItem, "", nil)
+			var gotTemplate string
+			mockOpItemTemplateCmd(t, mc, `item edit item-id --vault vault-id --template {template} --format json`, testOpItem, &gotTemplate)
+
+			repo, err := onepasswordcli.NewRepository(mc)
+			require.NoError(err)
+
+			// The model of the item should be enough to edit the item without losing data.
+			item, err := repo.GetItemByID(context.TODO(), "item-id")
+			require.NoError(err)
+			test.modify(item)
+
+			gotItem, err := repo.EnsureItem(context.TODO(), *item)
+			require.NoError(err)
+			mc.AssertExpectations(t)
+
+			assert.JSONEq(test.expTemplate, gotTemplate)
+			assert.Equal("item-id", gotItem.ID)
+		})
+	}
+}
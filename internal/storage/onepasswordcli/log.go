@@ -0,0 +1,102 @@
+package onepasswordcli
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// redactedValue is the value logged instead of the secrets.
+const redactedValue = "***"
+
+// secretOpFlags are the op flags whose values are secrets.
+var secretOpFlags = map[string]bool{
+	"--session":    true,
+	"--secret-key": true,
+	"--password":   true,
+}
+
+// redactOpArgs returns a copy of the op command args without secrets, so they can be logged:
+// session tokens, secret keys and the item field values (`label[type]=value` assignments
+// of `item create` and `item edit`).
+func redactOpArgs(args []string) []string {
+	subcommand := opSubcommand(args)
+	isItemMutation := strings.HasPrefix(subcommand, "item create") || strings.HasPrefix(subcommand, "item edit")
+
+	redacted := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case secretOpFlags[arg] && i+1 < len(args):
+			redacted = append(redacted, arg, redactedValue)
+			i++
+		case isItemMutation && !strings.HasPrefix(arg, "-") && strings.Contains(arg, "="):
+			redacted = append(redacted, arg[:strings.Index(arg, "=")+1]+redactedValue)
+		default:
+			redacted = append(redacted, arg)
+		}
+	}
+
+	return redacted
+}
+
+// opSubcommand returns the op subcommand of the args (e.g: `vault group grant`), ignoring the
+// global flags that are set before it (e.g: `--session`).
+func opSubcommand(args []string) string {
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") {
+		i += 2
+	}
+
+	subcommand := []string{}
+	for ; i < len(args) && len(subcommand) < 3 && !strings.HasPrefix(args[i], "-"); i++ {
+		subcommand = append(subcommand, args[i])
+	}
+
+	return strings.Join(subcommand, " ")
+}
+
+// logOpCmd logs an executed op command, args must be the unredacted ones.
+func logOpCmd(ctx context.Context, args []string, start time.Time, stderr string, err error) {
+	fields := map[string]interface{}{
+		"op_subcommand": opSubcommand(args),
+		"op_args":       strings.Join(redactOpArgs(args), " "),
+		"duration":      time.Since(start).String(),
+		"exit_code":     0,
+	}
+
+	if err != nil {
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		fields["exit_code"] = exitCode
+		fields["stderr"] = stderr
+		fields["error"] = err.Error()
+		tflog.Debug(ctx, "op command failed", fields)
+		return
+	}
+
+	tflog.Trace(ctx, "op command executed", fields)
+}
+
+// maskOpSecrets returns a context whose logger masks the secrets in case they appear
+// anywhere (e.g: op stderr).
+func maskOpSecrets(ctx context.Context, secrets ...string) context.Context {
+	nonEmpty := []string{}
+	for _, s := range secrets {
+		if s != "" {
+			nonEmpty = append(nonEmpty, s)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return ctx
+	}
+
+	return tflog.MaskLogStrings(ctx, nonEmpty...)
+}
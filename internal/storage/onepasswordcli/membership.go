@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 )
 
 func (r Repository) EnsureMembership(ctx context.Context, membership model.Membership) error {
@@ -20,7 +23,7 @@ func (r Repository) EnsureMembership(ctx context.Context, membership model.Membe
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return opCliCmdError(err, stderr)
 	}
 
 	// 1password doesn't know to add a member to a group with a specific role, so we would need to:
@@ -32,7 +35,7 @@ func (r Repository) EnsureMembership(ctx context.Context, membership model.Membe
 	if membership.Role != model.MembershipRoleMember {
 		_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 		if err != nil {
-			return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+			return opCliCmdError(err, stderr)
 		}
 	}
 
@@ -40,42 +43,56 @@ func (r Repository) EnsureMembership(ctx context.Context, membership model.Membe
 }
 
 func (r Repository) GetMembershipByID(ctx context.Context, groupID, userID string) (*model.Membership, error) {
+	members, err := r.ListMembershipsByGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range *members {
+		if m.UserID == userID {
+			if m.Role == model.MembershipRoleUnknown {
+				return nil, fmt.Errorf("member %q in group %q has an unknown role", userID, groupID)
+			}
+			return &m, nil
+		}
+	}
+
+	return nil, fmt.Errorf("member %q in group %q: %w", userID, groupID, storage.ErrNotFound)
+}
+
+func (r Repository) ListMembershipsByGroup(ctx context.Context, groupID string) (*[]model.Membership, error) {
 	cmdArgs := &onePasswordCliCmd{}
 	cmdArgs.UserArg().ListArg().GroupFlag(groupID).FormatJSONFlag()
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
-	// List group members and get the user.
 	members := []opGroupMember{}
 	err = json.Unmarshal([]byte(stdout), &members)
 	if err != nil {
 		return nil, fmt.Errorf("could not unmarshal op cli stdout: %w", err)
 	}
-	var member *opGroupMember
+
+	memberships := []model.Membership{}
 	for _, m := range members {
-		if m.ID == userID {
-			member = &m
-			break
+		// A member with an unknown role (e.g: a new op role) must not fail the other members,
+		// only the ones getting this membership.
+		role, err := mapOpToModelRole(m.Role)
+		if err != nil {
+			tflog.Warn(ctx, "Unknown op group member role", map[string]interface{}{"group_id": groupID, "user_id": m.ID, "role": m.Role})
+			role = model.MembershipRoleUnknown
 		}
-	}
 
-	if member == nil {
-		return nil, fmt.Errorf("member %q in group %q not found", userID, groupID)
-	}
-
-	role, err := mapOpToModelRole(member.Role)
-	if err != nil {
-		return nil, fmt.Errorf("invalid role: %w", err)
+		memberships = append(memberships, model.Membership{
+			UserID:  m.ID,
+			GroupID: groupID,
+			Role:    role,
+		})
 	}
 
-	return &model.Membership{
-		UserID:  userID,
-		GroupID: groupID,
-		Role:    role,
-	}, nil
+	return &memberships, nil
 }
 
 func (r Repository) DeleteMembership(ctx context.Context, membership model.Membership) error {
@@ -84,7 +101,7 @@ func (r Repository) DeleteMembership(ctx context.Context, membership model.Membe
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return opCliCmdError(err, stderr)
 	}
 
 	return nil
@@ -113,6 +130,6 @@ func mapOpToModelRole(role string) (model.MembershipRole, error) {
 	case "manager":
 		return model.MembershipRoleManager, nil
 	default:
-		return model.MembershipRoleMember, fmt.Errorf("invalid role")
+		return model.MembershipRoleUnknown, fmt.Errorf("invalid role %q", role)
 	}
 }
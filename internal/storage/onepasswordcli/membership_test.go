@@ -2,6 +2,7 @@ package onepasswordcli_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclimock"
 )
@@ -78,6 +80,7 @@ func TestRepositoryGetMembershipByID(t *testing.T) {
 		mock          func(m *onepasswordclimock.OpCli)
 		expMembership *model.Membership
 		expErr        bool
+		expNotFound   bool
 	}{
 		"Getting a member correctly, should return the group data.": {
 			userID:  "test-user-00",
@@ -94,6 +97,32 @@ func TestRepositoryGetMembershipByID(t *testing.T) {
 			},
 		},
 
+		"Getting a member when other members have an unknown role, should return the member.": {
+			userID:  "test-user-01",
+			groupID: "group-id",
+			mock: func(m *onepasswordclimock.OpCli) {
+				expCmd := `user list --group group-id --format json`
+				stdout := `[{"id":"test-user-00","name":"Test00","email":"test0@slok.dev","role":"OWNER"},{"id":"test-user-01","name":"Tst01","email":"test01@slok.dev","role":"MEMBER"}]`
+				m.On("RunOpCmd", mock.Anything, strings.Fields(expCmd)).Once().Return(stdout, "", nil)
+			},
+			expMembership: &model.Membership{
+				UserID:  "test-user-01",
+				GroupID: "group-id",
+				Role:    model.MembershipRoleMember,
+			},
+		},
+
+		"Getting a member with an unknown role, should fail.": {
+			userID:  "test-user-00",
+			groupID: "group-id",
+			mock: func(m *onepasswordclimock.OpCli) {
+				expCmd := `user list --group group-id --format json`
+				stdout := `[{"id":"test-user-00","name":"Test00","email":"test0@slok.dev","role":"OWNER"},{"id":"test-user-01","name":"Tst01","email":"test01@slok.dev","role":"MEMBER"}]`
+				m.On("RunOpCmd", mock.Anything, strings.Fields(expCmd)).Once().Return(stdout, "", nil)
+			},
+			expErr: true,
+		},
+
 		"Getting a missing member should fail.": {
 			userID:  "test-user-02",
 			groupID: "group-id",
@@ -102,7 +131,8 @@ func TestRepositoryGetMembershipByID(t *testing.T) {
 				stdout := `[{"id":"test-user-00","name":"Test00","email":"test0@slok.dev","role":"MANAGER"},{"id":"test-user-01","name":"Tst01","email":"test01@slok.dev","role":"MEMBER"}]`
 				m.On("RunOpCmd", mock.Anything, strings.Fields(expCmd)).Once().Return(stdout, "", nil)
 			},
-			expErr: true,
+			expErr:      true,
+			expNotFound: true,
 		},
 
 		"Having an error while calling the op CLI, should fail.": {
@@ -114,6 +144,18 @@ func TestRepositoryGetMembershipByID(t *testing.T) {
 			},
 			expErr: true,
 		},
+
+		"Getting a member of a missing group, should fail with a not found error.": {
+			userID:  "test-id",
+			groupID: "group-id",
+			mock: func(m *onepasswordclimock.OpCli) {
+				expCmd := `user list --group group-id --format json`
+				stderr := `[ERROR] 2022/03/17 10:00:00 "group-id" isn't a group in this account. Specify the group with its UUID or name.`
+				m.On("RunOpCmd", mock.Anything, strings.Fields(expCmd)).Once().Return("", stderr, fmt.Errorf("exit status 1"))
+			},
+			expErr:      true,
+			expNotFound: true,
+		},
 	}
 
 	for name, test := range tests {
@@ -131,6 +173,7 @@ func TestRepositoryGetMembershipByID(t *testing.T) {
 
 			if test.expErr {
 				assert.Error(err)
+				assert.Equal(test.expNotFound, errors.Is(err, storage.ErrNotFound))
 			} else if assert.NoError(err) {
 				assert.Equal(test.expMembership, gotMembership)
 			}
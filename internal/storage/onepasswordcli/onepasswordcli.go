@@ -3,11 +3,14 @@ package onepasswordcli
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"strings"
+	"sync"
+	"time"
 )
 
 // OpCli knows how to execute Op CLI commands.
@@ -15,28 +18,95 @@ type OpCli interface {
 	RunOpCmd(ctx context.Context, args []string) (stdout, stderr string, err error)
 }
 
+const (
+	// envVarOpServiceAccountToken is the env var used by op to authenticate with a service account.
+	envVarOpServiceAccountToken = "OP_SERVICE_ACCOUNT_TOKEN"
+	// envVarOpConfigDir is the env var used by op to set its config directory (accounts, sessions...).
+	envVarOpConfigDir = "OP_CONFIG_DIR"
+	// envVarOpSessionPrefix is the prefix of the env var used by op to get the session of an account
+	// (`OP_SESSION_<account shorthand>`).
+	envVarOpSessionPrefix = "OP_SESSION_"
+)
+
 //go:generate mockery --case underscore --output onepasswordclimock --outpkg onepasswordclimock --name OpCli
 
 type opCli struct {
-	binPath      string
+	binPath             string
+	version             OpVersion
+	configDir           string
+	accountShorthand    string
+	serviceAccountToken string
+	// signin returns a new session token, used to signin again when the session expires.
+	signin func(ctx context.Context) (string, error)
+
+	mu           sync.RWMutex
 	sessionToken string
 }
 
 // NewOpCLI creates a new signed OpCLI command executor.
-func NewOpCli(customCliPath, address, email, secretKey, password string, shorthand string) (OpCli, error) {
+//
+// The credentials are kept so the executor can signin again when the session expires.
+//
+// If configDir is set, op will use it as its config directory instead of the user's one.
+//
+// If shorthand is set, the account must already be added on op config with that shorthand,
+// otherwise the account will be added with a shorthand derived from the address and email,
+// so multiple accounts (e.g: aliased providers) can be used at the same time.
+//
+// The context bounds the signin, if done before signing in (e.g: op waiting on a prompt),
+// op will be killed.
+func NewOpCli(ctx context.Context, customCliPath, configDir, address, email, secretKey, password string, shorthand string) (OpCli, error) {
 	binPath, err := prepareOpCliBinary(customCliPath)
 	if err != nil {
 		return nil, fmt.Errorf("could not prepare op cli: %w", err)
 	}
 
-	cmd := exec.Command(binPath, "account", "add", "--address", address, "--email", email, "--secret-key", secretKey, "--shorthand", "terraform", "--signin", "--raw")
-	if shorthand != "" {
-		cmd = exec.Command(binPath, "signin", "--account", shorthand, "--raw")
+	env := opEnv(configDir)
+	version, err := detectOpVersion(ctx, binPath, env)
+	if err != nil {
+		return nil, err
+	}
+
+	// First signin adds the account (unless it was already added by the user, using a shorthand),
+	// once added the next signins only need the password.
+	args := []string{"signin", "--account", shorthand, "--raw"}
+	if shorthand == "" {
+		shorthand = accountShorthand(address, email)
+		args = []string{"account", "add", "--address", address, "--email", email, "--secret-key", secretKey, "--shorthand", shorthand, "--signin", "--raw"}
 	}
-	stdin, err := cmd.StdinPipe()
+	token, err := opSignin(ctx, binPath, env, args, password)
 	if err != nil {
 		return nil, err
 	}
+
+	return &opCli{
+		binPath:          binPath,
+		version:          version,
+		configDir:        configDir,
+		accountShorthand: shorthand,
+		sessionToken:     token,
+		signin: func(ctx context.Context) (string, error) {
+			return opSignin(ctx, binPath, env, []string{"signin", "--account", shorthand, "--raw"}, password)
+		},
+	}, nil
+}
+
+// accountShorthand returns the shorthand of an account added by the provider, it's a valid
+// env var name suffix so the session can be set with `OP_SESSION_<shorthand>`.
+func accountShorthand(address, email string) string {
+	h := sha256.Sum256([]byte(strings.ToLower(address + "/" + email)))
+	return "terraform_" + hex.EncodeToString(h[:])[:12]
+}
+
+// opSignin executes an op signin command writing the password on its stdin, and returns the session token.
+func opSignin(ctx context.Context, binPath string, env, args []string, password string) (string, error) {
+	ctx = maskOpSecrets(ctx, password)
+	start := time.Now()
+	cmd := newOpCmd(ctx, binPath, env, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return "", err
+	}
 	go func() {
 		defer stdin.Close()
 		_, err := io.WriteString(stdin, fmt.Sprintf("%s\n", password))
@@ -47,12 +117,43 @@ func NewOpCli(customCliPath, address, email, secretKey, password string, shortha
 
 	result, err := cmd.CombinedOutput()
 	if err != nil {
-		return nil, fmt.Errorf("cannot signin: %w: %s", err, string(result))
+		err = opCmdContextError(ctx, err)
+		logOpCmd(ctx, args, start, string(result), err)
+		return "", fmt.Errorf("cannot signin: %w: %s", err, string(result))
+	}
+	// The output is the session token, don't log it.
+	logOpCmd(ctx, args, start, "", nil)
+
+	return strings.TrimSpace(string(result)), nil
+}
+
+// NewServiceAccountOpCli creates a new OpCLI command executor authenticated with a 1password
+// service account token.
+//
+// Service accounts don't signin, op authenticates every command using the token, so no session
+// is used.
+//
+// If configDir is set, op will use it as its config directory instead of the user's one.
+func NewServiceAccountOpCli(ctx context.Context, customCliPath, configDir, serviceAccountToken string) (OpCli, error) {
+	if serviceAccountToken == "" {
+		return nil, fmt.Errorf("service account token is required")
+	}
+
+	binPath, err := prepareOpCliBinary(customCliPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare op cli: %w", err)
 	}
 
-	return opCli{
-		binPath:      binPath,
-		sessionToken: strings.TrimSpace(string(result)),
+	version, err := detectOpVersion(ctx, binPath, opEnv(configDir))
+	if err != nil {
+		return nil, err
+	}
+
+	return &opCli{
+		binPath:             binPath,
+		version:             version,
+		configDir:           configDir,
+		serviceAccountToken: serviceAccountToken,
 	}, nil
 }
 
@@ -95,20 +196,91 @@ func prepareOpCliBinary(customBinPath string) (binPath string, err error) {
 	return tfeBinPath, nil
 }
 
-func (o opCli) RunOpCmd(ctx context.Context, args []string) (stdout, stderr string, err error) {
-	if o.sessionToken == "" {
+// OpVersion returns the detected op CLI version.
+func (o *opCli) OpVersion() OpVersion { return o.version }
+
+func (o *opCli) RunOpCmd(ctx context.Context, args []string) (stdout, stderr string, err error) {
+	if o.serviceAccountToken != "" {
+		// Service accounts authenticate every command with the token.
+		env := append(opEnv(o.configDir), envVarOpServiceAccountToken+"="+o.serviceAccountToken)
+		return o.run(ctx, args, env)
+	}
+
+	o.mu.RLock()
+	sessionToken := o.sessionToken
+	o.mu.RUnlock()
+	if sessionToken == "" {
 		return "", "", fmt.Errorf("unauthenticated, op cli must singin first")
 	}
 
-	// Set session token and account before executing the command.
-	args = append([]string{"--session", o.sessionToken, "--account", "terraform"}, args...)
+	stdout, stderr, err = o.run(ctx, o.sessionArgs(args), o.sessionEnv(sessionToken))
+	if err == nil || o.signin == nil || !sessionExpiredStderrRegexp.MatchString(stderr) {
+		return stdout, stderr, err
+	}
+
+	// The session expired, signin again and retry the command once.
+	sessionToken, err = o.resignin(ctx, sessionToken)
+	if err != nil {
+		return stdout, stderr, fmt.Errorf("session expired and could not signin again: %w", err)
+	}
+
+	return o.run(ctx, o.sessionArgs(args), o.sessionEnv(sessionToken))
+}
+
+// resignin gets a new session replacing the expired one. Concurrent commands that got
+// the same expired session will only signin once, the rest will reuse the new session.
+func (o *opCli) resignin(ctx context.Context, expiredSessionToken string) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Already renewed by other command.
+	if o.sessionToken != expiredSessionToken {
+		return o.sessionToken, nil
+	}
+
+	token, err := o.signin(ctx)
+	if err != nil {
+		return "", err
+	}
+	o.sessionToken = token
+
+	return token, nil
+}
+
+func (o *opCli) sessionArgs(args []string) []string {
+	// Set the account before executing the command, op gets its session from the env.
+	return append([]string{"--account", o.accountShorthand}, args...)
+}
+
+// sessionEnv returns the env of the op commands with the session token, the session is not set
+// on the command args so it's not visible to every user of the host.
+func (o *opCli) sessionEnv(sessionToken string) []string {
+	return append(opEnv(o.configDir), envVarOpSessionPrefix+o.accountShorthand+"="+sessionToken)
+}
+
+// opEnv returns the env of the op commands, if the config dir is empty op will use the default one.
+func opEnv(configDir string) []string {
+	env := os.Environ()
+	if configDir != "" {
+		env = append(env, envVarOpConfigDir+"="+configDir)
+	}
+
+	return env
+}
+
+func (o *opCli) run(ctx context.Context, args []string, env []string) (stdout, stderr string, err error) {
+	o.mu.RLock()
+	ctx = maskOpSecrets(ctx, o.sessionToken, o.serviceAccountToken)
+	o.mu.RUnlock()
 
 	// Prepare command and execute.
-	cmd := exec.CommandContext(ctx, o.binPath, args...)
+	start := time.Now()
+	cmd := newOpCmd(ctx, o.binPath, env, args...)
 	var sout, serr bytes.Buffer
 	cmd.Stdout = &sout
 	cmd.Stderr = &serr
-	err = cmd.Run()
+	err = opCmdContextError(ctx, cmd.Run())
+	logOpCmd(ctx, args, start, serr.String(), err)
 
 	return sout.String(), serr.String(), err
 }
@@ -116,11 +288,17 @@ func (o opCli) RunOpCmd(ctx context.Context, args []string) (stdout, stderr stri
 // NewRepository returns a 1password CLI (op) based respoitory.
 func NewRepository(cli OpCli) (*Repository, error) {
 	return &Repository{
-		cli: cli,
+		cli:       cli,
+		opVersion: opVersionOf(cli),
 	}, nil
 }
 
 // Repository knows how to execute 1password operations using 1password CLI.
 type Repository struct {
 	cli OpCli
+	// opVersion is the op CLI version, zero if unknown.
+	opVersion OpVersion
 }
+
+// OpVersion returns the op CLI version used by the repository, zero if unknown.
+func (r Repository) OpVersion() OpVersion { return r.opVersion }
@@ -0,0 +1,267 @@
+package onepasswordcli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
+)
+
+// fakeOpScript is an op cli stand-in where the session returned by the first signin
+// (account add) is expired and the next signins return a valid one. Every signin is
+// logged in the signins file and the added account shorthand in the shorthand file.
+// The version is 2.24.0 unless `FAKE_OP_VERSION` env var is set.
+// If `FAKE_OP_HANG` env var is set, adding the account hangs (like waiting on a 2FA prompt).
+// Commands fail if op config dir is not the script dir.
+const fakeOpScript = `#!/bin/sh
+dir=$(dirname "$0")
+[ "$OP_CONFIG_DIR" = "$dir" ] || { echo "[ERROR] wrong config dir" >&2; exit 1; }
+case "$*" in
+	"--version")
+		echo "${FAKE_OP_VERSION:-2.24.0}"
+		exit 0 ;;
+	"account add"*)
+		read -r password
+		[ -n "$FAKE_OP_HANG" ] && sleep 60
+		prev=""
+		for arg in "$@"; do
+			[ "$prev" = "--shorthand" ] && echo "$arg" > "$dir/shorthand"
+			prev="$arg"
+		done
+		echo "account-add" >> "$dir/signins"
+		echo "expired-token"
+		exit 0 ;;
+esac
+shorthand=$(cat "$dir/shorthand")
+case "$*" in
+	"signin --account $shorthand --raw")
+		read -r password
+		[ "$password" = "test-password" ] || { echo "[ERROR] wrong password" >&2; exit 1; }
+		echo "signin" >> "$dir/signins"
+		echo "valid-token" ;;
+	"--account $shorthand "*)
+		env | grep -qx "OP_SESSION_$shorthand=valid-token" || { echo "[ERROR] You are not currently signed in. Please run 'op signin --help' for instructions" >&2; exit 1; }
+		case "$*" in *valid-token*) echo "[ERROR] session on args" >&2; exit 1 ;; esac
+		echo "ok" ;;
+	*)
+		echo "[ERROR] 2022/03/17 10:00:00 You are not currently signed in. Please run 'op signin --help' for instructions" >&2
+		exit 1 ;;
+esac
+`
+
+func newFakeOpCli(t *testing.T, email, password string) (cli onepasswordcli.OpCli, dir string) {
+	binPath := writeFakeOpScript(t)
+	dir = filepath.Dir(binPath)
+
+	cli, err := onepasswordcli.NewOpCli(context.TODO(), binPath, dir, "test.1password.com", email, "test-secret-key", password, "")
+	require.NoError(t, err)
+
+	return cli, dir
+}
+
+func writeFakeOpScript(t *testing.T) (binPath string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake op cli is a shell script")
+	}
+	t.Setenv("TFC_RUN_ID", "")
+
+	binPath = filepath.Join(t.TempDir(), "op")
+	err := os.WriteFile(binPath, []byte(fakeOpScript), 0755)
+	require.NoError(t, err)
+
+	return binPath
+}
+
+func readFakeOpFile(dir, name string) []string {
+	data, _ := os.ReadFile(filepath.Join(dir, name))
+	return strings.Fields(string(data))
+}
+
+func TestOpCliRunOpCmdSessionExpired(t *testing.T) {
+	t.Run("An expired session should signin again and retry the command.", func(t *testing.T) {
+		assert := assert.New(t)
+		cli, dir := newFakeOpCli(t, "test@test.io", "test-password")
+
+		stdout, _, err := cli.RunOpCmd(context.TODO(), []string{"user", "list"})
+		assert.NoError(err)
+		assert.Equal("ok\n", stdout)
+
+		// The new session should be reused.
+		_, _, err = cli.RunOpCmd(context.TODO(), []string{"user", "list"})
+		assert.NoError(err)
+		assert.Equal([]string{"account-add", "signin"}, readFakeOpFile(dir, "signins"))
+	})
+
+	t.Run("Concurrent commands with an expired session should signin only once.", func(t *testing.T) {
+		assert := assert.New(t)
+		cli, dir := newFakeOpCli(t, "test@test.io", "test-password")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _, err := cli.RunOpCmd(context.TODO(), []string{"user", "list"})
+				assert.NoError(err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal([]string{"account-add", "signin"}, readFakeOpFile(dir, "signins"))
+	})
+
+	t.Run("An expired session that can't signin again should fail.", func(t *testing.T) {
+		assert := assert.New(t)
+		cli, dir := newFakeOpCli(t, "test@test.io", "wrong-password")
+
+		_, _, err := cli.RunOpCmd(context.TODO(), []string{"user", "list"})
+		assert.Error(err)
+		assert.Equal([]string{"account-add"}, readFakeOpFile(dir, "signins"))
+	})
+}
+
+func TestOpCliAccountShorthand(t *testing.T) {
+	assert := assert.New(t)
+
+	cli0, dir0 := newFakeOpCli(t, "test0@test.io", "test-password")
+	_, dir1 := newFakeOpCli(t, "test1@test.io", "test-password")
+	_, dir2 := newFakeOpCli(t, "test0@test.io", "test-password")
+
+	// Different accounts should have different shorthands, the same account the same one.
+	sh0, sh1, sh2 := readFakeOpFile(dir0, "shorthand"), readFakeOpFile(dir1, "shorthand"), readFakeOpFile(dir2, "shorthand")
+	assert.NotEqual(sh0, sh1)
+	assert.Equal(sh0, sh2)
+
+	// Commands should use the account shorthand.
+	_, _, err := cli0.RunOpCmd(context.TODO(), []string{"user", "list"})
+	assert.NoError(err)
+}
+
+func TestOpCliRunOpCmdLogsRedacted(t *testing.T) {
+	assert := assert.New(t)
+	cli, _ := newFakeOpCli(t, "test@test.io", "test-password")
+
+	var out bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &out)
+
+	// The first command fails with the expired session so the failure, the signin and the retry are logged.
+	_, _, err := cli.RunOpCmd(ctx, []string{"item", "create", "--title", "test", "--vault", "vault-id", "password[password]=s3cr3t", "--format", "json"})
+	assert.NoError(err)
+
+	logs := out.String()
+	entries, err := tflogtest.MultilineJSONDecode(&out)
+	assert.NoError(err)
+	assert.Len(entries, 3)
+	for _, e := range entries {
+		assert.NotEmpty(e["op_subcommand"])
+		assert.Contains(e, "duration")
+		assert.Contains(e, "exit_code")
+	}
+	assert.Equal("item create", entries[0]["op_subcommand"])
+	assert.Equal(float64(1), entries[0]["exit_code"])
+	assert.Contains(entries[0]["stderr"], "not currently signed in")
+	assert.Equal("signin", entries[1]["op_subcommand"])
+
+	for _, secret := range []string{"s3cr3t", "expired-token", "valid-token", "test-password"} {
+		assert.NotContains(logs, secret)
+	}
+	assert.Contains(logs, "password[password]=***")
+}
+
+func TestOpCliVersion(t *testing.T) {
+	tests := map[string]struct {
+		version    string
+		expVersion onepasswordcli.OpVersion
+		expErr     bool
+	}{
+		"A supported version should be detected.": {
+			version:    "2.24.0",
+			expVersion: onepasswordcli.OpVersion{Major: 2, Minor: 24, Patch: 0},
+		},
+
+		"A supported pre-release version should be detected.": {
+			version:    "2.25.1-beta.01",
+			expVersion: onepasswordcli.OpVersion{Major: 2, Minor: 25, Patch: 1},
+		},
+
+		"A version without template item edits should fail.": {
+			version: "2.22.1",
+			expErr:  true,
+		},
+
+		"An old version should fail.": {
+			version: "1.12.4",
+			expErr:  true,
+		},
+
+		"A newer major version should fail.": {
+			version: "3.0.0",
+			expErr:  true,
+		},
+
+		"An invalid version should fail.": {
+			version: "whatever",
+			expErr:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			binPath := writeFakeOpScript(t)
+			t.Setenv("FAKE_OP_VERSION", test.version)
+
+			for _, newCli := range []func() (onepasswordcli.OpCli, error){
+				func() (onepasswordcli.OpCli, error) {
+					return onepasswordcli.NewOpCli(context.TODO(), binPath, filepath.Dir(binPath), "test.1password.com", "test@test.io", "test-secret-key", "test-password", "")
+				},
+				func() (onepasswordcli.OpCli, error) {
+					return onepasswordcli.NewServiceAccountOpCli(context.TODO(), binPath, filepath.Dir(binPath), "test-token")
+				},
+			} {
+				cli, err := newCli()
+				if test.expErr {
+					assert.Error(err)
+					continue
+				}
+
+				if assert.NoError(err) {
+					// The version should be available through the decorators and the repository.
+					cli, err = onepasswordcli.NewRetryOpCli(onepasswordcli.RetryOpCliConfig{Cli: cli})
+					assert.NoError(err)
+					repo, err := onepasswordcli.NewRepository(cli)
+					assert.NoError(err)
+					assert.Equal(test.expVersion, repo.OpVersion())
+				}
+			}
+		})
+	}
+}
+
+func TestOpCliSigninCancel(t *testing.T) {
+	assert := assert.New(t)
+
+	binPath := writeFakeOpScript(t)
+	t.Setenv("FAKE_OP_HANG", "true")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The processes started by op (sleep) hold its output, op should be killed with all of them.
+	start := time.Now()
+	_, err := onepasswordcli.NewOpCli(ctx, binPath, filepath.Dir(binPath), "test.1password.com", "test@test.io", "test-secret-key", "test-password", "")
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Less(time.Since(start), 3*time.Second)
+}
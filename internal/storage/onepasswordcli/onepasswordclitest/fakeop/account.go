@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	envVarOpConfigDir           = "OP_CONFIG_DIR"
+	envVarOpSessionPrefix       = "OP_SESSION_"
+	envVarOpServiceAccountToken = "OP_SERVICE_ACCOUNT_TOKEN"
+)
+
+// account is an account added with `op account add`.
+type account struct {
+	Shorthand string
+	Address   string
+	Email     string
+	SecretKey string
+	Password  string
+}
+
+// sessionToken returns the session token of the account, it's the same for every signin, so
+// the sessions don't need to be stored.
+func (a account) sessionToken() string {
+	h := sha256.Sum256([]byte(a.Shorthand + "/" + a.Address + "/" + a.Email + "/" + a.SecretKey + "/" + a.Password))
+	return hex.EncodeToString(h[:])
+}
+
+func configDir() (string, error) {
+	if dir := os.Getenv(envVarOpConfigDir); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", "op"), nil
+}
+
+func accountPath(shorthand string) (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "fakeop-accounts", shorthand+".json"), nil
+}
+
+func loadAccount(shorthand string) (*account, error) {
+	path, err := accountPath(shorthand)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no account found for filter %q", shorthand)
+	}
+
+	a := &account{}
+	err = json.Unmarshal(data, a)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account %q: %w", shorthand, err)
+	}
+
+	return a, nil
+}
+
+// readPassword reads the password from stdin, like op does when it's not a terminal.
+func readPassword() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("could not read password: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// accountAdd adds an account (`op account add --signin --raw`), the password is read from stdin.
+func accountAdd(args cmdArgs) error {
+	a := account{
+		Shorthand: args.flag("--shorthand"),
+		Address:   args.flag("--address"),
+		Email:     args.flag("--email"),
+		SecretKey: args.flag("--secret-key"),
+	}
+	if a.Address == "" || a.Email == "" || a.SecretKey == "" {
+		return fmt.Errorf("--address, --email and --secret-key are required")
+	}
+	if a.Shorthand == "" {
+		a.Shorthand = strings.SplitN(a.Address, ".", 2)[0]
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+	if password == "" {
+		return fmt.Errorf("password is required")
+	}
+	a.Password = password
+
+	path, err := accountPath(a.Shorthand)
+	if err != nil {
+		return err
+	}
+
+	// Adding an existing account signs in.
+	if current, err := loadAccount(a.Shorthand); err == nil && current.Password != a.Password {
+		return fmt.Errorf("Authentication: (401) Unauthorized, invalid password")
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, data, 0600)
+	if err != nil {
+		return err
+	}
+
+	if args.has("--signin") {
+		fmt.Println(a.sessionToken())
+	}
+
+	return nil
+}
+
+// signin signs in an added account (`op signin --account <shorthand> --raw`), the password is
+// read from stdin.
+func signin(args cmdArgs) error {
+	a, err := loadAccount(args.flag("--account"))
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+	if password != a.Password {
+		return fmt.Errorf("Authentication: (401) Unauthorized, invalid password")
+	}
+
+	fmt.Println(a.sessionToken())
+
+	return nil
+}
+
+// authenticate checks the command is executed with a service account or a valid account session.
+func authenticate(args cmdArgs) error {
+	if os.Getenv(envVarOpServiceAccountToken) != "" {
+		return nil
+	}
+
+	const notSignedIn = "You are not currently signed in. Please run `op signin --help` for instructions"
+
+	shorthand := args.flag("--account")
+	if shorthand == "" {
+		return errors.New(notSignedIn)
+	}
+
+	a, err := loadAccount(shorthand)
+	if err != nil {
+		return err
+	}
+
+	if os.Getenv(envVarOpSessionPrefix+shorthand) != a.sessionToken() {
+		return errors.New(notSignedIn)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+type opGroup struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+}
+
+func mapModelToOpGroup(g model.Group) opGroup {
+	return opGroup{ID: g.ID, Name: g.Name, Description: g.Description, State: "ACTIVE"}
+}
+
+// getGroup gets a group by ID or name, like op does.
+func getGroup(ctx context.Context, repo storage.Repository, ref string) (*model.Group, error) {
+	g, err := repo.GetGroupByID(ctx, ref)
+	if err == nil {
+		return g, nil
+	}
+	// Other errors (e.g: rate limits) are returned as they are, not as missing objects.
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	g, err = repo.GetGroupByName(ctx, ref)
+	if err != nil {
+		return nil, notFoundError(err, "group", ref)
+	}
+
+	return g, nil
+}
+
+func groupCreate(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	name, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	g, err := repo.CreateGroup(ctx, model.Group{
+		Name:        name,
+		Description: args.flag("--description"),
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(mapModelToOpGroup(*g))
+}
+
+func groupGet(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	g, err := getGroup(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(mapModelToOpGroup(*g))
+}
+
+func groupEdit(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	g, err := getGroup(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	if args.has("--name") {
+		g.Name = args.flag("--name")
+	}
+	if args.has("--description") {
+		g.Description = args.flag("--description")
+	}
+
+	_, err = repo.EnsureGroup(ctx, *g)
+	return err
+}
+
+func groupDelete(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	g, err := getGroup(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	return repo.DeleteGroup(ctx, g.ID)
+}
+
+func groupUserGrant(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	g, err := getGroup(ctx, repo, args.flag("--group"))
+	if err != nil {
+		return err
+	}
+
+	u, err := getUser(ctx, repo, args.flag("--user"))
+	if err != nil {
+		return err
+	}
+
+	role := model.MembershipRoleMember
+	switch strings.ToLower(args.flag("--role")) {
+	case "", "member":
+	case "manager":
+		role = model.MembershipRoleManager
+	default:
+		return fmt.Errorf("invalid role %q", args.flag("--role"))
+	}
+
+	return repo.EnsureMembership(ctx, model.Membership{GroupID: g.ID, UserID: u.ID, Role: role})
+}
+
+func groupUserRevoke(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	g, err := getGroup(ctx, repo, args.flag("--group"))
+	if err != nil {
+		return err
+	}
+
+	u, err := getUser(ctx, repo, args.flag("--user"))
+	if err != nil {
+		return err
+	}
+
+	err = repo.DeleteMembership(ctx, model.Membership{GroupID: g.ID, UserID: u.ID})
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("%q isn't a member of the %q group", args.flag("--user"), args.flag("--group"))
+	}
+
+	return err
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+type opItemField struct {
+	ID      string     `json:"id"`
+	Type    string     `json:"type"`
+	Purpose string     `json:"purpose,omitempty"`
+	Label   string     `json:"label"`
+	Value   string     `json:"value,omitempty"`
+	Section *opSection `json:"section,omitempty"`
+}
+
+type opSection struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+type opURL struct {
+	Label   string `json:"label,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Href    string `json:"href"`
+}
+
+// opItem is the op item JSON document, used for the templates and the output.
+type opItem struct {
+	ID       string        `json:"id,omitempty"`
+	Title    string        `json:"title"`
+	Version  int           `json:"version,omitempty"`
+	Vault    *opVault      `json:"vault,omitempty"`
+	Category string        `json:"category"`
+	Tags     []string      `json:"tags,omitempty"`
+	URLs     []opURL       `json:"urls,omitempty"`
+	Sections []opSection   `json:"sections,omitempty"`
+	Fields   []opItemField `json:"fields,omitempty"`
+}
+
+func mapModelToOpItem(i model.Item) opItem {
+	oi := opItem{
+		ID:       i.ID,
+		Title:    i.Title,
+		Version:  1,
+		Vault:    &opVault{ID: i.Vault.ID, Name: i.Vault.Name},
+		Category: strings.ToUpper(i.Category),
+		Tags:     i.Tags,
+	}
+
+	for _, u := range i.URLs {
+		oi.URLs = append(oi.URLs, opURL{Label: u.Label, Primary: u.Primary, Href: u.URL})
+	}
+	for _, s := range i.Sections {
+		oi.Sections = append(oi.Sections, opSection{ID: s.ID, Label: s.Label})
+	}
+	for _, f := range i.Fields {
+		of := opItemField{ID: f.ID, Type: f.Type, Purpose: f.Purpose, Label: f.Label, Value: f.Value}
+		if f.Section != nil {
+			of.Section = &opSection{ID: f.Section.ID, Label: f.Section.Label}
+		}
+		oi.Fields = append(oi.Fields, of)
+	}
+
+	return oi
+}
+
+// setItemFromTemplate sets the item content with the op item template file.
+func setItemFromTemplate(item *model.Item, templatePath string) error {
+	data, err := os.ReadFile(templatePath)
+	if err != nil {
+		return fmt.Errorf("could not read template: %w", err)
+	}
+
+	t := opItem{}
+	err = json.Unmarshal(data, &t)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	item.Title = t.Title
+	item.Category = strings.ToUpper(t.Category)
+	item.Tags = t.Tags
+	item.URLs = nil
+	for _, u := range t.URLs {
+		item.URLs = append(item.URLs, model.URL{URL: u.Href, Label: u.Label, Primary: u.Primary})
+	}
+	item.Sections = nil
+	for _, s := range t.Sections {
+		item.Sections = append(item.Sections, model.Section{ID: s.ID, Label: s.Label})
+	}
+	item.Fields = nil
+	for _, f := range t.Fields {
+		mf := model.Field{ID: f.ID, Type: f.Type, Purpose: f.Purpose, Label: f.Label, Value: f.Value}
+		if f.Section != nil {
+			mf.Section = &model.Section{ID: f.Section.ID, Label: f.Section.Label}
+		}
+		item.Fields = append(item.Fields, mf)
+	}
+
+	return nil
+}
+
+// getItem gets an item by ID, or by title if the vault is set, like op does.
+func getItem(ctx context.Context, repo storage.Repository, ref, vaultRef string) (*model.Item, error) {
+	var vault *model.Vault
+	if vaultRef != "" {
+		v, err := getVault(ctx, repo, vaultRef)
+		if err != nil {
+			return nil, err
+		}
+		vault = v
+	}
+
+	i, err := repo.GetItemByID(ctx, ref)
+	if err == nil && (vault == nil || i.Vault.ID == vault.ID) {
+		return i, nil
+	}
+	// Other errors (e.g: rate limits) are returned as they are, not as missing objects.
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	if vault == nil {
+		return nil, notFoundError(err, "item", ref)
+	}
+
+	i, err = repo.GetItemByTitle(ctx, vault.ID, ref)
+	if err != nil {
+		return nil, notFoundError(err, "item", ref)
+	}
+
+	return i, nil
+}
+
+func itemCreate(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	v, err := getVault(ctx, repo, args.flag("--vault"))
+	if err != nil {
+		return err
+	}
+
+	item := model.Item{Vault: *v}
+	err = setItemFromTemplate(&item, args.flag("--template"))
+	if err != nil {
+		return err
+	}
+
+	i, err := repo.CreateItem(ctx, item)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(mapModelToOpItem(*i))
+}
+
+func itemGet(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	i, err := getItem(ctx, repo, ref, args.flag("--vault"))
+	if err != nil {
+		return err
+	}
+
+	return printJSON(mapModelToOpItem(*i))
+}
+
+func itemEdit(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	i, err := getItem(ctx, repo, ref, args.flag("--vault"))
+	if err != nil {
+		return err
+	}
+
+	if args.has("--template") {
+		err = setItemFromTemplate(i, args.flag("--template"))
+		if err != nil {
+			return err
+		}
+	}
+
+	i, err = repo.EnsureItem(ctx, *i)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(mapModelToOpItem(*i))
+}
+
+func itemDelete(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	i, err := getItem(ctx, repo, ref, args.flag("--vault"))
+	if err != nil {
+		return err
+	}
+
+	return repo.DeleteItem(ctx, i.ID)
+}
@@ -0,0 +1,241 @@
+// Command fakeop is a stand-in of the op CLI for tests. It implements the subset of op v2 commands
+// used by the op CLI repository, storing the data on the fake repository storage
+// (`OP_FAKE_CLI_STORAGE_PATH` env var).
+//
+// Accounts are added with `op account add` and stored on the op config dir (`OP_CONFIG_DIR`),
+// the rest of the commands need the session of the account (`--account <shorthand>` and
+// `OP_SESSION_<shorthand>` env var) or a service account token (`OP_SERVICE_ACCOUNT_TOKEN` env var).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/fake"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclitest"
+)
+
+func main() {
+	err := run(context.Background(), os.Args[1:])
+	if err != nil {
+		// Same format as op errors.
+		fmt.Fprintf(os.Stderr, "[ERROR] %s %s\n", time.Now().Format("2006/01/02 15:04:05"), err)
+		os.Exit(1)
+	}
+}
+
+// command runs an op command with the parsed arguments.
+type command func(ctx context.Context, repo storage.Repository, args cmdArgs) error
+
+// commands are the supported op commands by their subcommand path.
+var commands = map[string]command{
+	"user provision": userProvision,
+	"user get":       userGet,
+	"user edit":      userEdit,
+	"user delete":    userDelete,
+	"user list":      userList,
+
+	"group create":      groupCreate,
+	"group get":         groupGet,
+	"group edit":        groupEdit,
+	"group delete":      groupDelete,
+	"group user grant":  groupUserGrant,
+	"group user revoke": groupUserRevoke,
+
+	"vault create":       vaultCreate,
+	"vault get":          vaultGet,
+	"vault edit":         vaultEdit,
+	"vault delete":       vaultDelete,
+	"vault list":         vaultList,
+	"vault group grant":  vaultGroupGrant,
+	"vault group revoke": vaultGroupRevoke,
+	"vault group list":   vaultGroupList,
+	"vault user grant":   vaultUserGrant,
+	"vault user revoke":  vaultUserRevoke,
+	"vault user list":    vaultUserList,
+
+	"item create": itemCreate,
+	"item get":    itemGet,
+	"item edit":   itemEdit,
+	"item delete": itemDelete,
+}
+
+func run(ctx context.Context, rawArgs []string) error {
+	args, err := parseArgs(rawArgs)
+	if err != nil {
+		return err
+	}
+
+	if args.has("--version") {
+		version := os.Getenv(onepasswordclitest.EnvVarFakeOpVersion)
+		if version == "" {
+			version = onepasswordclitest.FakeOpVersion
+		}
+		fmt.Println(version)
+		return nil
+	}
+
+	// Account commands don't need to be authenticated.
+	switch {
+	case args.isCmd("account", "add"):
+		return accountAdd(args)
+	case args.isCmd("signin"):
+		return signin(args)
+	}
+
+	cmd, name, err := args.command()
+	if err != nil {
+		return err
+	}
+
+	err = authenticate(args)
+	if err != nil {
+		return err
+	}
+
+	storagePath := os.Getenv(onepasswordclitest.EnvVarFakeOpStoragePath)
+	if storagePath == "" {
+		return fmt.Errorf("%s env var is required", onepasswordclitest.EnvVarFakeOpStoragePath)
+	}
+
+	// Inject the faults once per command before running it, like op failing a request, instead of
+	// once per storage call of the command.
+	err = fake.InjectFaults(ctx, storagePath, name, args.objectIDs(name)...)
+	if err != nil {
+		return err
+	}
+
+	repo, err := fake.NewRepositoryWithoutFaults(storagePath)
+	if err != nil {
+		return err
+	}
+
+	return cmd(ctx, repo, args)
+}
+
+// cmdArgs are the parsed op command arguments.
+type cmdArgs struct {
+	positional []string
+	flags      map[string]string
+}
+
+// boolFlags are the flags without value.
+var boolFlags = map[string]bool{
+	"--version":  true,
+	"--signin":   true,
+	"--raw":      true,
+	"--no-input": true,
+}
+
+func parseArgs(rawArgs []string) (cmdArgs, error) {
+	args := cmdArgs{flags: map[string]string{}}
+	for i := 0; i < len(rawArgs); i++ {
+		arg := rawArgs[i]
+		switch {
+		case !strings.HasPrefix(arg, "--"):
+			args.positional = append(args.positional, arg)
+		case boolFlags[arg]:
+			args.flags[arg] = "true"
+		case strings.Contains(arg, "="):
+			kv := strings.SplitN(arg, "=", 2)
+			args.flags[kv[0]] = kv[1]
+		default:
+			if i+1 >= len(rawArgs) {
+				return args, fmt.Errorf("flag needs an argument: %s", arg)
+			}
+			args.flags[arg] = rawArgs[i+1]
+			i++
+		}
+	}
+
+	return args, nil
+}
+
+func (a cmdArgs) has(flag string) bool {
+	_, ok := a.flags[flag]
+	return ok
+}
+
+func (a cmdArgs) flag(flag string) string { return a.flags[flag] }
+
+func (a cmdArgs) isCmd(subcommands ...string) bool {
+	if len(a.positional) < len(subcommands) {
+		return false
+	}
+
+	for i, s := range subcommands {
+		if a.positional[i] != s {
+			return false
+		}
+	}
+
+	return true
+}
+
+// command returns the command of the arguments and its subcommand path, the longest subcommand
+// path wins.
+func (a cmdArgs) command() (command, string, error) {
+	for n := 3; n > 0; n-- {
+		if len(a.positional) < n {
+			continue
+		}
+		name := strings.Join(a.positional[:n], " ")
+		if cmd, ok := commands[name]; ok {
+			return cmd, name, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("unknown command %q", strings.Join(a.positional, " "))
+}
+
+// objectIDs returns the arguments that can identify the objects used by a command (IDs, names,
+// emails...), the positional arguments after the subcommand path and the flag values.
+func (a cmdArgs) objectIDs(name string) []string {
+	ids := append([]string{}, a.positional[len(strings.Fields(name)):]...)
+	for _, v := range a.flags {
+		ids = append(ids, v)
+	}
+
+	return ids
+}
+
+// arg returns the positional argument after the subcommand path (e.g: the ID on `user get <id>`).
+func (a cmdArgs) arg(subcommands int) (string, error) {
+	if len(a.positional) <= subcommands {
+		return "", fmt.Errorf("expected an argument after %q", strings.Join(a.positional, " "))
+	}
+
+	return a.positional[subcommands], nil
+}
+
+// printJSON writes the object as op does with `--format json`.
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+
+	return nil
+}
+
+// notFoundError returns an op like not found error if the storage error is a not found one.
+func notFoundError(err error, kind, ref string) error {
+	if !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	// op uses "an" only for items (e.g: "isn't a user", "isn't an item").
+	article := "a"
+	if kind == "item" {
+		article = "an"
+	}
+
+	return fmt.Errorf("%q isn't %s %s in this account. Specify the %s with its UUID or name.", ref, article, kind, kind)
+}
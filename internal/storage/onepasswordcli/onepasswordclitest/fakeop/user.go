@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+type opUser struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	State string `json:"state"`
+	Type  string `json:"type"`
+}
+
+func mapModelToOpUser(u model.User) opUser {
+	return opUser{ID: u.ID, Name: u.Name, Email: u.Email, State: "ACTIVE", Type: "MEMBER"}
+}
+
+// getUser gets a user by ID or email, like op does.
+func getUser(ctx context.Context, repo storage.Repository, ref string) (*model.User, error) {
+	u, err := repo.GetUserByID(ctx, ref)
+	if err == nil {
+		return u, nil
+	}
+	// Other errors (e.g: rate limits) are returned as they are, not as missing objects.
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	u, err = repo.GetUserByEmail(ctx, ref)
+	if err != nil {
+		return nil, notFoundError(err, "user", ref)
+	}
+
+	return u, nil
+}
+
+func userProvision(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	u, err := repo.CreateUser(ctx, model.User{
+		Email: args.flag("--email"),
+		Name:  args.flag("--name"),
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(mapModelToOpUser(*u))
+}
+
+func userGet(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	u, err := getUser(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(mapModelToOpUser(*u))
+}
+
+func userEdit(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	u, err := getUser(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	if args.has("--name") {
+		u.Name = args.flag("--name")
+	}
+
+	_, err = repo.EnsureUser(ctx, *u)
+	return err
+}
+
+func userDelete(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	u, err := getUser(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	return repo.DeleteUser(ctx, u.ID)
+}
+
+type opGroupMember struct {
+	opUser
+	Role string `json:"role"`
+}
+
+// userList lists the members of a group (`op user list --group <group>`).
+func userList(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	if !args.has("--group") {
+		return fmt.Errorf("only listing the users of a group (--group) is supported")
+	}
+
+	g, err := getGroup(ctx, repo, args.flag("--group"))
+	if err != nil {
+		return err
+	}
+
+	lister, ok := repo.(storage.GroupMembershipLister)
+	if !ok {
+		return fmt.Errorf("repository can't list group members")
+	}
+
+	ms, err := lister.ListMembershipsByGroup(ctx, g.ID)
+	if err != nil {
+		return err
+	}
+
+	members := []opGroupMember{}
+	for _, m := range *ms {
+		u, err := repo.GetUserByID(ctx, m.UserID)
+		if err != nil {
+			return err
+		}
+
+		members = append(members, opGroupMember{opUser: mapModelToOpUser(*u), Role: mapModelToOpRole(m.Role)})
+	}
+
+	return printJSON(members)
+}
+
+func mapModelToOpRole(r model.MembershipRole) string {
+	if r == model.MembershipRoleManager {
+		return "MANAGER"
+	}
+
+	return "MEMBER"
+}
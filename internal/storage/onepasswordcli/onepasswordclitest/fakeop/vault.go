@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+type opVault struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+func mapModelToOpVault(v model.Vault) opVault {
+	return opVault{ID: v.ID, Name: v.Name, Description: v.Description}
+}
+
+// getVault gets a vault by ID or name, like op does.
+func getVault(ctx context.Context, repo storage.Repository, ref string) (*model.Vault, error) {
+	v, err := repo.GetVaultByID(ctx, ref)
+	if err == nil {
+		return v, nil
+	}
+	// Other errors (e.g: rate limits) are returned as they are, not as missing objects.
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	v, err = repo.GetVaultByName(ctx, ref)
+	if err != nil {
+		return nil, notFoundError(err, "vault", ref)
+	}
+
+	return v, nil
+}
+
+func vaultCreate(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	name, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	v, err := repo.CreateVault(ctx, model.Vault{
+		Name:        name,
+		Description: args.flag("--description"),
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(mapModelToOpVault(*v))
+}
+
+func vaultGet(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	v, err := getVault(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(mapModelToOpVault(*v))
+}
+
+func vaultEdit(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	v, err := getVault(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	if args.has("--name") {
+		v.Name = args.flag("--name")
+	}
+	if args.has("--description") {
+		v.Description = args.flag("--description")
+	}
+
+	_, err = repo.EnsureVault(ctx, *v)
+	return err
+}
+
+func vaultDelete(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(2)
+	if err != nil {
+		return err
+	}
+
+	v, err := getVault(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	return repo.DeleteVault(ctx, v.ID)
+}
+
+// vaultList lists the vaults of a user (`op vault list --user <user>`).
+func vaultList(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	if !args.has("--user") {
+		return fmt.Errorf("only listing the vaults of a user (--user) is supported")
+	}
+
+	u, err := getUser(ctx, repo, args.flag("--user"))
+	if err != nil {
+		return err
+	}
+
+	vs, err := repo.ListVaultsByUser(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	vaults := []opVault{}
+	for _, v := range *vs {
+		vaults = append(vaults, mapModelToOpVault(v))
+	}
+
+	return printJSON(vaults)
+}
+
+type opVaultAccess struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+// vaultGroupGrant adds permissions to the group access of a vault, like op, the current
+// permissions are kept.
+func vaultGroupGrant(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	v, g, err := getVaultAndGroup(ctx, repo, args)
+	if err != nil {
+		return err
+	}
+
+	permissions := model.AccessPermissions{}
+	current, err := repo.GetVaultGroupAccessByID(ctx, v.ID, g.ID)
+	switch {
+	case err == nil:
+		permissions = current.Permissions
+	case !errors.Is(err, storage.ErrNotFound):
+		return err
+	}
+
+	err = grantPermissions(&permissions, args.flag("--permissions"))
+	if err != nil {
+		return err
+	}
+
+	return repo.EnsureVaultGroupAccess(ctx, model.VaultGroupAccess{VaultID: v.ID, GroupID: g.ID, Permissions: permissions})
+}
+
+func vaultGroupRevoke(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	v, g, err := getVaultAndGroup(ctx, repo, args)
+	if err != nil {
+		return err
+	}
+
+	if args.has("--permissions") {
+		return fmt.Errorf("revoking specific permissions is not supported")
+	}
+
+	err = repo.DeleteVaultGroupAccess(ctx, v.ID, g.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("%q doesn't have access to the %q vault", args.flag("--group"), args.flag("--vault"))
+	}
+
+	return err
+}
+
+// vaultGroupList lists the group accesses of a vault (`op vault group list <vault>`).
+func vaultGroupList(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(3)
+	if err != nil {
+		return err
+	}
+
+	v, err := getVault(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	lister, ok := repo.(storage.VaultGroupAccessLister)
+	if !ok {
+		return fmt.Errorf("repository can't list vault group accesses")
+	}
+
+	as, err := lister.ListVaultGroupAccessesByVault(ctx, v.ID)
+	if err != nil {
+		return err
+	}
+
+	accesses := []opVaultAccess{}
+	for _, a := range *as {
+		g, err := repo.GetGroupByID(ctx, a.GroupID)
+		if err != nil {
+			return err
+		}
+
+		accesses = append(accesses, opVaultAccess{ID: g.ID, Name: g.Name, Permissions: mapModelToOpPermissions(a.Permissions)})
+	}
+
+	return printJSON(accesses)
+}
+
+func getVaultAndGroup(ctx context.Context, repo storage.Repository, args cmdArgs) (*model.Vault, *model.Group, error) {
+	v, err := getVault(ctx, repo, args.flag("--vault"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g, err := getGroup(ctx, repo, args.flag("--group"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return v, g, nil
+}
+
+// vaultUserGrant adds permissions to the user access of a vault, like op, the current
+// permissions are kept.
+func vaultUserGrant(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	v, u, err := getVaultAndUser(ctx, repo, args)
+	if err != nil {
+		return err
+	}
+
+	permissions := model.AccessPermissions{}
+	current, err := repo.GetVaultUserAccessByID(ctx, v.ID, u.ID)
+	switch {
+	case err == nil:
+		permissions = current.Permissions
+	case !errors.Is(err, storage.ErrNotFound):
+		return err
+	}
+
+	err = grantPermissions(&permissions, args.flag("--permissions"))
+	if err != nil {
+		return err
+	}
+
+	return repo.EnsureVaultUserAccess(ctx, model.VaultUserAccess{VaultID: v.ID, UserID: u.ID, Permissions: permissions})
+}
+
+func vaultUserRevoke(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	v, u, err := getVaultAndUser(ctx, repo, args)
+	if err != nil {
+		return err
+	}
+
+	if args.has("--permissions") {
+		return fmt.Errorf("revoking specific permissions is not supported")
+	}
+
+	err = repo.DeleteVaultUserAccess(ctx, v.ID, u.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("%q doesn't have access to the %q vault", args.flag("--user"), args.flag("--vault"))
+	}
+
+	return err
+}
+
+// vaultUserList lists the user accesses of a vault (`op vault user list <vault>`).
+func vaultUserList(ctx context.Context, repo storage.Repository, args cmdArgs) error {
+	ref, err := args.arg(3)
+	if err != nil {
+		return err
+	}
+
+	v, err := getVault(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+
+	lister, ok := repo.(storage.VaultUserAccessLister)
+	if !ok {
+		return fmt.Errorf("repository can't list vault user accesses")
+	}
+
+	as, err := lister.ListVaultUserAccessesByVault(ctx, v.ID)
+	if err != nil {
+		return err
+	}
+
+	accesses := []opVaultAccess{}
+	for _, a := range *as {
+		u, err := repo.GetUserByID(ctx, a.UserID)
+		if err != nil {
+			return err
+		}
+
+		accesses = append(accesses, opVaultAccess{ID: u.ID, Name: u.Name, Permissions: mapModelToOpPermissions(a.Permissions)})
+	}
+
+	return printJSON(accesses)
+}
+
+func getVaultAndUser(ctx context.Context, repo storage.Repository, args cmdArgs) (*model.Vault, *model.User, error) {
+	v, err := getVault(ctx, repo, args.flag("--vault"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := getUser(ctx, repo, args.flag("--user"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return v, u, nil
+}
+
+// opPermissions returns the access permissions by their op name, in the order op lists them.
+func opPermissions(p *model.AccessPermissions) []struct {
+	name  string
+	value *bool
+} {
+	return []struct {
+		name  string
+		value *bool
+	}{
+		{"allow_viewing", &p.AllowViewing},
+		{"allow_editing", &p.AllowEditing},
+		{"allow_managing", &p.AllowManaging},
+		{"view_items", &p.ViewItems},
+		{"create_items", &p.CreateItems},
+		{"edit_items", &p.EditItems},
+		{"archive_items", &p.ArchiveItems},
+		{"delete_items", &p.DeleteItems},
+		{"view_and_copy_passwords", &p.ViewAndCopyPasswords},
+		{"view_item_history", &p.ViewItemHistory},
+		{"import_items", &p.ImportItems},
+		{"export_items", &p.ExportItems},
+		{"copy_and_share_items", &p.CopyAndShareItems},
+		{"print_items", &p.PrintItems},
+		{"manage_vault", &p.ManageVault},
+	}
+}
+
+// grantPermissions sets the comma separated op permissions.
+func grantPermissions(p *model.AccessPermissions, permissions string) error {
+	if permissions == "" {
+		return nil
+	}
+
+	perms := opPermissions(p)
+	for _, name := range strings.Split(permissions, ",") {
+		found := false
+		for _, perm := range perms {
+			if perm.name == strings.TrimSpace(name) {
+				*perm.value = true
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid permission %q", name)
+		}
+	}
+
+	return nil
+}
+
+func mapModelToOpPermissions(p model.AccessPermissions) []string {
+	names := []string{}
+	for _, perm := range opPermissions(&p) {
+		if *perm.value {
+			names = append(names, perm.name)
+		}
+	}
+
+	return names
+}
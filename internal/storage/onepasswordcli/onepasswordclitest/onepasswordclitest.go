@@ -0,0 +1,44 @@
+// Package onepasswordclitest has helpers to test the op CLI based repository end to end
+// without a 1password account, using a stand-in op CLI (fakeop) on top of the fake storage.
+package onepasswordclitest
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// Env vars used by the fake op CLI.
+const (
+	// EnvVarFakeOpStoragePath is the path of the fake storage file used by the fake op CLI (required).
+	EnvVarFakeOpStoragePath = "OP_FAKE_CLI_STORAGE_PATH"
+	// EnvVarFakeOpVersion is the version returned by the fake op CLI on `op --version`.
+	EnvVarFakeOpVersion = "OP_FAKE_CLI_VERSION"
+)
+
+// FakeOpVersion is the default version returned by the fake op CLI.
+const FakeOpVersion = "2.24.0"
+
+const fakeOpPkg = "github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclitest/fakeop"
+
+// BuildFakeOp builds the fake op CLI on a temporary directory of the test and returns its path.
+//
+// The fake op CLI stores the data on the fake storage file set with `OP_FAKE_CLI_STORAGE_PATH`
+// env var, the fake storage faults (`OP_FAKE_*` env vars) are applied to its commands.
+func BuildFakeOp(t testing.TB) (binPath string) {
+	t.Helper()
+
+	binPath = filepath.Join(t.TempDir(), "op")
+	if runtime.GOOS == "windows" {
+		binPath += ".exe"
+	}
+
+	goBin := filepath.Join(runtime.GOROOT(), "bin", "go")
+	out, err := exec.Command(goBin, "build", "-o", binPath, fakeOpPkg).CombinedOutput()
+	if err != nil {
+		t.Fatalf("could not build fake op cli: %s: %s", err, out)
+	}
+
+	return binPath
+}
@@ -0,0 +1,34 @@
+package onepasswordcli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// opCmdWaitDelay is the time waited for the op command output after the command has been
+// killed, the processes started by op could still hold it.
+const opCmdWaitDelay = 5 * time.Second
+
+// newOpCmd returns an op command bound to the context, when the context is done the whole
+// process tree of the command is killed, not only op, so nothing started by op (e.g: a
+// prompt) keeps running or blocks waiting for its output.
+func newOpCmd(ctx context.Context, binPath string, env []string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, binPath, args...)
+	cmd.Env = env
+	cmd.WaitDelay = opCmdWaitDelay
+	killProcessTreeOnCancel(cmd)
+
+	return cmd
+}
+
+// opCmdContextError returns the error of an op command that failed, if it failed because the
+// context was done, the error will wrap the context error.
+func opCmdContextError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	return fmt.Errorf("op command killed: %w (%s)", ctx.Err(), err)
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package onepasswordcli
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessTreeOnCancel runs the command on its own process group and kills the group
+// when the command context is done.
+func killProcessTreeOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package onepasswordcli
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// killProcessTreeOnCancel kills the command and its child processes when the command
+// context is done.
+func killProcessTreeOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+		if err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+}
@@ -0,0 +1,128 @@
+package onepasswordcli
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryOpCliConfig is the configuration of the retrying OpCli.
+type RetryOpCliConfig struct {
+	// Cli is the OpCli that will execute the commands.
+	Cli OpCli
+	// MaxRetries is the number of times a command that failed with a transient error
+	// will be retried. 0 disables the retries.
+	MaxRetries int
+	// MaxWait is the maximum time that will be waited between retries.
+	MaxWait time.Duration
+	// BaseWait is the initial time that will be waited before retrying, this will
+	// be doubled on every retry up to the `MaxWait`.
+	BaseWait time.Duration
+}
+
+func (c *RetryOpCliConfig) defaults() error {
+	if c.Cli == nil {
+		return fmt.Errorf("op cli is required")
+	}
+
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("max retries can't be negative")
+	}
+
+	if c.MaxWait <= 0 {
+		c.MaxWait = 30 * time.Second
+	}
+
+	if c.BaseWait <= 0 {
+		c.BaseWait = 500 * time.Millisecond
+	}
+
+	if c.BaseWait > c.MaxWait {
+		c.BaseWait = c.MaxWait
+	}
+
+	return nil
+}
+
+type retryOpCli struct {
+	cli        OpCli
+	maxRetries int
+	maxWait    time.Duration
+	baseWait   time.Duration
+}
+
+// NewRetryOpCli returns an OpCli that retries the commands that failed with transient errors
+// (e.g: rate limits, network errors...) using exponential backoff with jitter.
+//
+// The commands that create objects (e.g: `vault create`, `user provision`) are only retried when
+// the request was not applied (e.g: rate limits, connection refused), on other failures (e.g: timeouts)
+// the object could have been created already, and retrying would create a duplicate.
+//
+// The retries will stop when the context is done or when waiting for the next retry would
+// exceed the context deadline, returning the last command result.
+func NewRetryOpCli(config RetryOpCliConfig) (OpCli, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return retryOpCli{
+		cli:        config.Cli,
+		maxRetries: config.MaxRetries,
+		maxWait:    config.MaxWait,
+		baseWait:   config.BaseWait,
+	}, nil
+}
+
+// OpVersion returns the op CLI version of the wrapped OpCli.
+func (r retryOpCli) OpVersion() OpVersion { return opVersionOf(r.cli) }
+
+func (r retryOpCli) RunOpCmd(ctx context.Context, args []string) (stdout, stderr string, err error) {
+	for attempt := 0; ; attempt++ {
+		stdout, stderr, err = r.cli.RunOpCmd(ctx, args)
+		if err == nil || attempt >= r.maxRetries {
+			return stdout, stderr, err
+		}
+
+		retryable, rateLimited := classifyOpCliError(stderr)
+		if !retryable || (isCreateOpCmd(args) && !notAppliedStderrRegexp.MatchString(stderr)) {
+			return stdout, stderr, err
+		}
+
+		// Don't wait if we already know that we will not be able to retry.
+		wait := r.backoff(attempt, rateLimited)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return stdout, stderr, err
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return stdout, stderr, err
+		case <-t.C:
+		}
+	}
+}
+
+// isCreateOpCmd returns true if the op CLI command creates an object.
+func isCreateOpCmd(args []string) bool {
+	return len(args) > 1 && (args[1] == "create" || args[1] == "provision")
+}
+
+// backoff returns the time to wait before the next retry.
+func (r retryOpCli) backoff(attempt int, rateLimited bool) time.Duration {
+	wait := r.maxWait
+
+	// Rate limits take time to be released, so in that case we don't start with small waits.
+	if !rateLimited && attempt < 32 {
+		if w := r.baseWait << attempt; w > 0 && w < r.maxWait {
+			wait = w
+		}
+	}
+
+	// Use half of the wait as jitter, this way concurrent commands don't retry at the same time.
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,171 @@
+package onepasswordcli_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclimock"
+)
+
+func TestRetryOpCliRunOpCmd(t *testing.T) {
+	const (
+		cmd         = `user get test-id --format json`
+		createCmd   = `vault create test --format json`
+		stdout      = `{"id":"test-id"}`
+		rateLimited = `[ERROR] 2022/03/17 10:00:00 Too many requests`
+		network     = `[ERROR] 2022/03/17 10:00:00 Get "https://my.1password.com/api/v1/user": read tcp: connection reset by peer`
+		notFound    = `[ERROR] 2022/03/17 10:00:00 "test-id" isn't a user in this account.`
+		refused     = `[ERROR] 2022/03/17 10:00:00 Post "https://my.1password.com/api/v1/vault": dial tcp: connection refused`
+		timedOut    = `[ERROR] 2022/03/17 10:00:00 Post "https://my.1password.com/api/v1/vault": net/http: request timed out`
+	)
+
+	tests := map[string]struct {
+		cmd        string
+		maxRetries int
+		maxWait    time.Duration
+		ctx        func() (context.Context, context.CancelFunc)
+		mock       func(m *onepasswordclimock.OpCli)
+		expStdout  string
+		expErr     bool
+	}{
+		"A command without errors should not be retried.": {
+			maxRetries: 3,
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, strings.Fields(cmd)).Once().Return(stdout, "", nil)
+			},
+			expStdout: stdout,
+		},
+
+		"A rate limited command should be retried.": {
+			maxRetries: 3,
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, strings.Fields(cmd)).Once().Return("", rateLimited, fmt.Errorf("exit status 1"))
+				m.On("RunOpCmd", mock.Anything, strings.Fields(cmd)).Once().Return(stdout, "", nil)
+			},
+			expStdout: stdout,
+		},
+
+		"A command with a network error should be retried.": {
+			maxRetries: 3,
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, strings.Fields(cmd)).Twice().Return("", network, fmt.Errorf("exit status 1"))
+				m.On("RunOpCmd", mock.Anything, strings.Fields(cmd)).Once().Return(stdout, "", nil)
+			},
+			expStdout: stdout,
+		},
+
+		"A create command with an error that could have been applied should not be retried.": {
+			cmd:        createCmd,
+			maxRetries: 3,
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, strings.Fields(createCmd)).Once().Return("", timedOut, fmt.Errorf("exit status 1"))
+			},
+			expErr: true,
+		},
+
+		"A create command with a rate limit error should be retried.": {
+			cmd:        createCmd,
+			maxRetries: 3,
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, strings.Fields(createCmd)).Once().Return("", rateLimited, fmt.Errorf("exit status 1"))
+				m.On("RunOpCmd", mock.Anything, strings.Fields(createCmd)).Once().Return(stdout, "", nil)
+			},
+			expStdout: stdout,
+		},
+
+		"A create command with an error before reaching the server should be retried.": {
+			cmd:        createCmd,
+			maxRetries: 3,
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, strings.Fields(createCmd)).Once().Return("", refused, fmt.Errorf("exit status 1"))
+				m.On("RunOpCmd", mock.Anything, strings.Fields(createCmd)).Once().Return(stdout, "", nil)
+			},
+			expStdout: stdout,
+		},
+
+		"A command with a non retryable error should not be retried.": {
+			maxRetries: 3,
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, strings.Fields(cmd)).Once().Return("", notFound, fmt.Errorf("exit status 1"))
+			},
+			expErr: true,
+		},
+
+		"A command should not be retried more than the max retries.": {
+			maxRetries: 2,
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, strings.Fields(cmd)).Times(3).Return("", rateLimited, fmt.Errorf("exit status 1"))
+			},
+			expErr: true,
+		},
+
+		"Having retries disabled, a command should not be retried.": {
+			maxRetries: 0,
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, strings.Fields(cmd)).Once().Return("", rateLimited, fmt.Errorf("exit status 1"))
+			},
+			expErr: true,
+		},
+
+		"A command should not be retried if the wait exceeds the context deadline.": {
+			maxRetries: 3,
+			maxWait:    time.Hour,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Second)
+			},
+			mock: func(m *onepasswordclimock.OpCli) {
+				m.On("RunOpCmd", mock.Anything, strings.Fields(cmd)).Once().Return("", rateLimited, fmt.Errorf("exit status 1"))
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			mc := &onepasswordclimock.OpCli{}
+			test.mock(mc)
+
+			maxWait := test.maxWait
+			if maxWait == 0 {
+				maxWait = time.Millisecond
+			}
+			cli, err := onepasswordcli.NewRetryOpCli(onepasswordcli.RetryOpCliConfig{
+				Cli:        mc,
+				MaxRetries: test.maxRetries,
+				MaxWait:    maxWait,
+			})
+			require.NoError(err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if test.ctx != nil {
+				ctx, cancel = test.ctx()
+			}
+			defer cancel()
+
+			runCmd := test.cmd
+			if runCmd == "" {
+				runCmd = cmd
+			}
+			gotStdout, _, err := cli.RunOpCmd(ctx, strings.Fields(runCmd))
+
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expStdout, gotStdout)
+			}
+
+			mc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package onepasswordcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+// NewServiceAccountRepository returns a 1password CLI (op) based repository for op CLIs
+// authenticated with a service account.
+//
+// Service accounts can't manage users nor groups, so these operations will fail
+// with `storage.ErrNotSupported` without calling op.
+func NewServiceAccountRepository(cli OpCli) (storage.Repository, error) {
+	repo, err := NewRepository(cli)
+	if err != nil {
+		return nil, err
+	}
+
+	return serviceAccountRepository{Repository: repo}, nil
+}
+
+type serviceAccountRepository struct {
+	*Repository
+}
+
+func errNotSupportedByServiceAccount(resource string) error {
+	return fmt.Errorf("1password service accounts can't manage %s, use a user account instead: %w", resource, storage.ErrNotSupported)
+}
+
+func (serviceAccountRepository) CreateUser(ctx context.Context, user model.User) (*model.User, error) {
+	return nil, errNotSupportedByServiceAccount("users")
+}
+
+func (serviceAccountRepository) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	return nil, errNotSupportedByServiceAccount("users")
+}
+
+func (serviceAccountRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return nil, errNotSupportedByServiceAccount("users")
+}
+
+func (serviceAccountRepository) EnsureUser(ctx context.Context, user model.User) (*model.User, error) {
+	return nil, errNotSupportedByServiceAccount("users")
+}
+
+func (serviceAccountRepository) DeleteUser(ctx context.Context, id string) error {
+	return errNotSupportedByServiceAccount("users")
+}
+
+func (serviceAccountRepository) ListVaultsByUser(ctx context.Context, userID string) (*[]model.Vault, error) {
+	return nil, errNotSupportedByServiceAccount("users")
+}
+
+func (serviceAccountRepository) CreateGroup(ctx context.Context, group model.Group) (*model.Group, error) {
+	return nil, errNotSupportedByServiceAccount("groups")
+}
+
+func (serviceAccountRepository) GetGroupByID(ctx context.Context, id string) (*model.Group, error) {
+	return nil, errNotSupportedByServiceAccount("groups")
+}
+
+func (serviceAccountRepository) GetGroupByName(ctx context.Context, name string) (*model.Group, error) {
+	return nil, errNotSupportedByServiceAccount("groups")
+}
+
+func (serviceAccountRepository) EnsureGroup(ctx context.Context, group model.Group) (*model.Group, error) {
+	return nil, errNotSupportedByServiceAccount("groups")
+}
+
+func (serviceAccountRepository) DeleteGroup(ctx context.Context, id string) error {
+	return errNotSupportedByServiceAccount("groups")
+}
+
+func (serviceAccountRepository) EnsureMembership(ctx context.Context, membership model.Membership) error {
+	return errNotSupportedByServiceAccount("group members")
+}
+
+func (serviceAccountRepository) DeleteMembership(ctx context.Context, membership model.Membership) error {
+	return errNotSupportedByServiceAccount("group members")
+}
+
+func (serviceAccountRepository) GetMembershipByID(ctx context.Context, groupID, userID string) (*model.Membership, error) {
+	return nil, errNotSupportedByServiceAccount("group members")
+}
+
+func (serviceAccountRepository) ListMembershipsByGroup(ctx context.Context, groupID string) (*[]model.Membership, error) {
+	return nil, errNotSupportedByServiceAccount("group members")
+}
@@ -0,0 +1,86 @@
+package onepasswordcli_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclimock"
+)
+
+func TestServiceAccountRepository(t *testing.T) {
+	tests := map[string]struct {
+		exec            func(r storage.Repository) error
+		mock            func(m *onepasswordclimock.OpCli)
+		expNotSupported bool
+	}{
+		"Creating a user should not be supported.": {
+			exec: func(r storage.Repository) error {
+				_, err := r.CreateUser(context.TODO(), model.User{Email: "test@test.io", Name: "Test00"})
+				return err
+			},
+			mock:            func(m *onepasswordclimock.OpCli) {},
+			expNotSupported: true,
+		},
+
+		"Getting a group should not be supported.": {
+			exec: func(r storage.Repository) error {
+				_, err := r.GetGroupByID(context.TODO(), "test-id")
+				return err
+			},
+			mock:            func(m *onepasswordclimock.OpCli) {},
+			expNotSupported: true,
+		},
+
+		"Ensuring a group member should not be supported.": {
+			exec: func(r storage.Repository) error {
+				return r.EnsureMembership(context.TODO(), model.Membership{UserID: "test-00", GroupID: "group-00"})
+			},
+			mock:            func(m *onepasswordclimock.OpCli) {},
+			expNotSupported: true,
+		},
+
+		"Getting a vault should be supported.": {
+			exec: func(r storage.Repository) error {
+				_, err := r.GetVaultByID(context.TODO(), "test-id")
+				return err
+			},
+			mock: func(m *onepasswordclimock.OpCli) {
+				expCmd := `vault get test-id --format json`
+				stdout := `{"id":"test-id","name":"Test00","description":"Test 00"}`
+				m.On("RunOpCmd", mock.Anything, strings.Fields(expCmd)).Once().Return(stdout, "", nil)
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			mc := &onepasswordclimock.OpCli{}
+			test.mock(mc)
+
+			repo, err := onepasswordcli.NewServiceAccountRepository(mc)
+			require.NoError(err)
+
+			err = test.exec(repo)
+
+			if test.expNotSupported {
+				assert.True(errors.Is(err, storage.ErrNotSupported))
+			} else {
+				assert.NoError(err)
+			}
+
+			mc.AssertExpectations(t)
+		})
+	}
+}
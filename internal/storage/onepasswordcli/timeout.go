@@ -0,0 +1,64 @@
+package onepasswordcli
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimeoutOpCliConfig is the configuration of the timeout OpCli.
+type TimeoutOpCliConfig struct {
+	// Cli is the OpCli that will execute the commands.
+	Cli OpCli
+	// Timeout is the maximum time a single op command can run, when reached the command
+	// (and the processes it started) is killed.
+	Timeout time.Duration
+}
+
+func (c *TimeoutOpCliConfig) defaults() error {
+	if c.Cli == nil {
+		return fmt.Errorf("op cli is required")
+	}
+
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout can't be negative")
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = 2 * time.Minute
+	}
+
+	return nil
+}
+
+type timeoutOpCli struct {
+	cli     OpCli
+	timeout time.Duration
+}
+
+// NewTimeoutOpCli returns an OpCli that limits the time every op command can run, this way a
+// hung op (e.g: waiting on a prompt) doesn't block forever.
+//
+// The timeout applies per command (including the signin when the session expired), so
+// wrapped by a retrying OpCli, every retry has its own timeout.
+func NewTimeoutOpCli(config TimeoutOpCliConfig) (OpCli, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return timeoutOpCli{
+		cli:     config.Cli,
+		timeout: config.Timeout,
+	}, nil
+}
+
+// OpVersion returns the op CLI version of the wrapped OpCli.
+func (t timeoutOpCli) OpVersion() OpVersion { return opVersionOf(t.cli) }
+
+func (t timeoutOpCli) RunOpCmd(ctx context.Context, args []string) (stdout, stderr string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	return t.cli.RunOpCmd(ctx, args)
+}
@@ -0,0 +1,68 @@
+package onepasswordcli_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclimock"
+)
+
+func TestTimeoutOpCliRunOpCmd(t *testing.T) {
+	const cmd = `user get test-id --format json`
+
+	tests := map[string]struct {
+		timeout     time.Duration
+		ctx         func() (context.Context, context.CancelFunc)
+		expDeadline time.Duration
+	}{
+		"A command should have the timeout as deadline.": {
+			timeout:     time.Minute,
+			ctx:         func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			expDeadline: time.Minute,
+		},
+
+		"A command with a context deadline before the timeout should keep the context deadline.": {
+			timeout: time.Minute,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Second)
+			},
+			expDeadline: time.Second,
+		},
+
+		"A command without timeout should use the default one.": {
+			ctx:         func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			expDeadline: 2 * time.Minute,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			mc := &onepasswordclimock.OpCli{}
+			var deadline time.Time
+			mc.On("RunOpCmd", mock.Anything, strings.Fields(cmd)).Once().Run(func(args mock.Arguments) {
+				deadline, _ = args.Get(0).(context.Context).Deadline()
+			}).Return("", "", nil)
+
+			cli, err := onepasswordcli.NewTimeoutOpCli(onepasswordcli.TimeoutOpCliConfig{Cli: mc, Timeout: test.timeout})
+			require.NoError(err)
+
+			ctx, cancel := test.ctx()
+			defer cancel()
+			_, _, err = cli.RunOpCmd(ctx, strings.Fields(cmd))
+			require.NoError(err)
+
+			assert.WithinDuration(time.Now().Add(test.expDeadline), deadline, 5*time.Second)
+			mc.AssertExpectations(t)
+		})
+	}
+}
@@ -14,7 +14,7 @@ func (r Repository) CreateUser(ctx context.Context, user model.User) (*model.Use
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	ou := opUser{}
@@ -34,7 +34,7 @@ func (r Repository) GetUserByID(ctx context.Context, id string) (*model.User, er
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	ou := opUser{}
@@ -54,7 +54,7 @@ func (r Repository) GetUserByEmail(ctx context.Context, email string) (*model.Us
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	ou := opUser{}
@@ -74,7 +74,7 @@ func (r Repository) EnsureUser(ctx context.Context, user model.User) (*model.Use
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	return &user, nil
@@ -86,7 +86,7 @@ func (r Repository) DeleteUser(ctx context.Context, id string) error {
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return opCliCmdError(err, stderr)
 	}
 
 	return nil
@@ -2,6 +2,7 @@ package onepasswordcli_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli"
 	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/onepasswordcli/onepasswordclimock"
 )
@@ -72,10 +74,11 @@ func TestRepositoryCreateUser(t *testing.T) {
 
 func TestRepositoryGetUserByID(t *testing.T) {
 	tests := map[string]struct {
-		id      string
-		mock    func(m *onepasswordclimock.OpCli)
-		expUser *model.User
-		expErr  bool
+		id          string
+		mock        func(m *onepasswordclimock.OpCli)
+		expUser     *model.User
+		expErr      bool
+		expNotFound bool
 	}{
 		"Getting a user correctly, should return the user data.": {
 			id: "test-id",
@@ -99,6 +102,17 @@ func TestRepositoryGetUserByID(t *testing.T) {
 			},
 			expErr: true,
 		},
+
+		"Getting a missing user, should fail with a not found error.": {
+			id: "test-id",
+			mock: func(m *onepasswordclimock.OpCli) {
+				expCmd := `user get test-id --format json`
+				stderr := `[ERROR] 2022/03/17 10:00:00 "test-id" isn't a user in this account. Specify the user with their UUID, name, or email.`
+				m.On("RunOpCmd", mock.Anything, strings.Fields(expCmd)).Once().Return("", stderr, fmt.Errorf("exit status 1"))
+			},
+			expErr:      true,
+			expNotFound: true,
+		},
 	}
 
 	for name, test := range tests {
@@ -116,6 +130,7 @@ func TestRepositoryGetUserByID(t *testing.T) {
 
 			if test.expErr {
 				assert.Error(err)
+				assert.Equal(test.expNotFound, errors.Is(err, storage.ErrNotFound))
 			} else if assert.NoError(err) {
 				assert.Equal(test.expUser, gotUser)
 			}
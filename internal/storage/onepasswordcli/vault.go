@@ -21,7 +21,7 @@ func (r Repository) CreateVault(ctx context.Context, vault model.Vault) (*model.
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	ov := opVault{}
@@ -41,7 +41,7 @@ func (r Repository) GetVaultByID(ctx context.Context, id string) (*model.Vault,
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	ov := opVault{}
@@ -61,7 +61,7 @@ func (r Repository) ListVaultsByUser(ctx context.Context, userID string) (*[]mod
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	ov := []opVault{}
@@ -84,7 +84,7 @@ func (r Repository) GetVaultByName(ctx context.Context, name string) (*model.Vau
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	ov := opVault{}
@@ -104,7 +104,7 @@ func (r Repository) EnsureVault(ctx context.Context, vault model.Vault) (*model.
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
 	return &vault, nil
@@ -116,7 +116,7 @@ func (r Repository) DeleteVault(ctx context.Context, id string) error {
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return opCliCmdError(err, stderr)
 	}
 
 	return nil
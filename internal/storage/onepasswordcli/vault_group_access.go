@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 )
 
 func (r *Repository) EnsureVaultGroupAccess(ctx context.Context, groupAccess model.VaultGroupAccess) error {
@@ -19,7 +20,7 @@ func (r *Repository) EnsureVaultGroupAccess(ctx context.Context, groupAccess mod
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return opCliCmdError(err, stderr)
 	}
 
 	return nil
@@ -31,44 +32,52 @@ func (r *Repository) DeleteVaultGroupAccess(ctx context.Context, vaultID string,
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return opCliCmdError(err, stderr)
 	}
 
 	return nil
 }
 
 func (r *Repository) GetVaultGroupAccessByID(ctx context.Context, vaultID string, groupID string) (*model.VaultGroupAccess, error) {
+	accesses, err := r.ListVaultGroupAccessesByVault(ctx, vaultID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range *accesses {
+		if a.GroupID == groupID {
+			return &a, nil
+		}
+	}
+
+	return nil, fmt.Errorf("group access %q in vault %q: %w", groupID, vaultID, storage.ErrNotFound)
+}
+
+func (r *Repository) ListVaultGroupAccessesByVault(ctx context.Context, vaultID string) (*[]model.VaultGroupAccess, error) {
 	cmdArgs := &onePasswordCliCmd{}
 	cmdArgs.VaultArg().GroupArg().ListArg().RawStrArg(vaultID).FormatJSONFlag()
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
-	// List vault groups and get the correct group.
-	accesses := []opVaultGroupAccess{}
-	err = json.Unmarshal([]byte(stdout), &accesses)
+	opAccesses := []opVaultGroupAccess{}
+	err = json.Unmarshal([]byte(stdout), &opAccesses)
 	if err != nil {
 		return nil, fmt.Errorf("could not unmarshal op cli stdout: %w", err)
 	}
-	var access *opVaultGroupAccess
-	for _, a := range accesses {
-		if a.GroupID == groupID {
-			access = &a
-			break
-		}
-	}
 
-	if access == nil {
-		return nil, fmt.Errorf("group access %q in vault %q not found", groupID, vaultID)
+	accesses := []model.VaultGroupAccess{}
+	for _, a := range opAccesses {
+		accesses = append(accesses, model.VaultGroupAccess{
+			VaultID:     vaultID,
+			GroupID:     a.GroupID,
+			Permissions: mapOpToModelPermissions(a.Permissions),
+		})
 	}
 
-	return &model.VaultGroupAccess{
-		VaultID:     vaultID,
-		GroupID:     groupID,
-		Permissions: mapOpToModelPermissions(access.Permissions),
-	}, nil
+	return &accesses, nil
 }
 
 const (
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
 )
 
 func (r *Repository) EnsureVaultUserAccess(ctx context.Context, userAccess model.VaultUserAccess) error {
@@ -19,7 +20,7 @@ func (r *Repository) EnsureVaultUserAccess(ctx context.Context, userAccess model
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return opCliCmdError(err, stderr)
 	}
 
 	return nil
@@ -31,44 +32,52 @@ func (r *Repository) DeleteVaultUserAccess(ctx context.Context, vaultID string,
 
 	_, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return opCliCmdError(err, stderr)
 	}
 
 	return nil
 }
 
 func (r *Repository) GetVaultUserAccessByID(ctx context.Context, vaultID string, userID string) (*model.VaultUserAccess, error) {
+	accesses, err := r.ListVaultUserAccessesByVault(ctx, vaultID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range *accesses {
+		if a.UserID == userID {
+			return &a, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user access %q in vault %q: %w", userID, vaultID, storage.ErrNotFound)
+}
+
+func (r *Repository) ListVaultUserAccessesByVault(ctx context.Context, vaultID string) (*[]model.VaultUserAccess, error) {
 	cmdArgs := &onePasswordCliCmd{}
 	cmdArgs.VaultArg().UserArg().ListArg().RawStrArg(vaultID).FormatJSONFlag()
 
 	stdout, stderr, err := r.cli.RunOpCmd(ctx, cmdArgs.GetArgs())
 	if err != nil {
-		return nil, fmt.Errorf("op cli command failed: %w: %s", err, stderr)
+		return nil, opCliCmdError(err, stderr)
 	}
 
-	// List vault users and get the correct user.
-	accesses := []opVaultUserAccess{}
-	err = json.Unmarshal([]byte(stdout), &accesses)
+	opAccesses := []opVaultUserAccess{}
+	err = json.Unmarshal([]byte(stdout), &opAccesses)
 	if err != nil {
 		return nil, fmt.Errorf("could not unmarshal op cli stdout: %w", err)
 	}
-	var access *opVaultUserAccess
-	for _, a := range accesses {
-		if a.UserID == userID {
-			access = &a
-			break
-		}
-	}
 
-	if access == nil {
-		return nil, fmt.Errorf("user access %q in vault %q not found", userID, vaultID)
+	accesses := []model.VaultUserAccess{}
+	for _, a := range opAccesses {
+		accesses = append(accesses, model.VaultUserAccess{
+			VaultID:     vaultID,
+			UserID:      a.UserID,
+			Permissions: mapOpToModelPermissions(a.Permissions),
+		})
 	}
 
-	return &model.VaultUserAccess{
-		VaultID:     vaultID,
-		UserID:      userID,
-		Permissions: mapOpToModelPermissions(access.Permissions),
-	}, nil
+	return &accesses, nil
 }
 
 type opVaultUserAccess struct {
@@ -0,0 +1,96 @@
+package onepasswordcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedOpVersion is returned when the op CLI version is not supported by the provider.
+var ErrUnsupportedOpVersion = errors.New("unsupported op cli version")
+
+// OpVersion is the semantic version of an op CLI.
+type OpVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v OpVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less returns true if the version is lower than o.
+func (v OpVersion) Less(o OpVersion) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// OpVersioner is implemented by the OpClis that know the version of the op CLI they execute,
+// the repository uses it to select the command arguments and JSON formats of each version.
+type OpVersioner interface {
+	OpVersion() OpVersion
+}
+
+// Supported op CLI versions range: [MinSupportedOpVersion, MaxUnsupportedOpVersion).
+// The provider depends on the op v2 commands and JSON formats, and on item edits with a
+// template (`op item edit --template`), added in 2.23.0.
+var (
+	MinSupportedOpVersion   = OpVersion{Major: 2, Minor: 23, Patch: 0}
+	MaxUnsupportedOpVersion = OpVersion{Major: 3, Minor: 0, Patch: 0}
+)
+
+var opVersionRegexp = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// parseOpVersion parses the output of `op --version` (e.g: `2.24.0`, `2.25.0-beta.01`).
+func parseOpVersion(s string) (OpVersion, error) {
+	m := opVersionRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return OpVersion{}, fmt.Errorf("invalid op cli version %q", strings.TrimSpace(s))
+	}
+
+	// Regexp only matches digits.
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	patch, _ := strconv.Atoi(m[3])
+
+	return OpVersion{Major: major, Minor: minor, Patch: patch}, nil
+}
+
+// detectOpVersion gets the op CLI version and checks that it's supported.
+func detectOpVersion(ctx context.Context, binPath string, env []string) (OpVersion, error) {
+	out, err := newOpCmd(ctx, binPath, env, "--version").Output()
+	if err != nil {
+		err = opCmdContextError(ctx, err)
+		return OpVersion{}, fmt.Errorf("could not get op cli version (is %q a valid op cli binary?): %w", binPath, err)
+	}
+
+	v, err := parseOpVersion(string(out))
+	if err != nil {
+		return OpVersion{}, err
+	}
+
+	if v.Less(MinSupportedOpVersion) || !v.Less(MaxUnsupportedOpVersion) {
+		return v, fmt.Errorf("%w: %q is %s, the provider requires op >=%s and <%s; install a supported op version (https://app-updates.agilebits.com/product_history/CLI2) or point `op_cli_path` to one",
+			ErrUnsupportedOpVersion, binPath, v, MinSupportedOpVersion, MaxUnsupportedOpVersion)
+	}
+
+	return v, nil
+}
+
+// opVersionOf returns the op CLI version of an OpCli, if unknown the zero version.
+func opVersionOf(cli OpCli) OpVersion {
+	if v, ok := cli.(OpVersioner); ok {
+		return v.OpVersion()
+	}
+
+	return OpVersion{}
+}
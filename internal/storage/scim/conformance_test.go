@@ -0,0 +1,27 @@
+package scim_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/scim"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/storagetest"
+)
+
+func TestRepositoryConformance(t *testing.T) {
+	storagetest.RunRepositoryTests(t, func(t *testing.T) storage.Repository {
+		api := newSCIMAPI()
+		api.users = map[string]map[string]interface{}{}
+		api.groups = map[string]map[string]interface{}{}
+		srv := httptest.NewServer(api)
+		t.Cleanup(srv.Close)
+
+		repo, err := scim.NewRepository(scim.RepositoryConfig{URL: srv.URL, Token: testToken})
+		require.NoError(t, err)
+
+		return repo
+	})
+}
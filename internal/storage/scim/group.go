@@ -0,0 +1,133 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+func (r *repository) CreateGroup(ctx context.Context, group model.Group) (*model.Group, error) {
+	err := checkGroupDescription(group)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1password allows multiple groups with the same name, we add this to make sure
+	// this doesn't happen.
+	_, err = r.GetGroupByName(ctx, group.Name)
+	if err == nil {
+		return nil, fmt.Errorf("group with name %q already exists", group.Name)
+	}
+
+	sg := scimGroup{
+		Schemas:     []string{schemaGroup},
+		DisplayName: group.Name,
+	}
+
+	created := scimGroup{}
+	err = r.do(ctx, http.MethodPost, "/Groups", nil, sg, &created)
+	if err != nil {
+		return nil, err
+	}
+
+	gotGroup := mapSCIMToModelGroup(created)
+
+	return &gotGroup, nil
+}
+
+func (r *repository) GetGroupByID(ctx context.Context, id string) (*model.Group, error) {
+	sg, err := r.getGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	gotGroup := mapSCIMToModelGroup(*sg)
+
+	return &gotGroup, nil
+}
+
+func (r *repository) GetGroupByName(ctx context.Context, name string) (*model.Group, error) {
+	list := scimListResponse{}
+	err := r.do(ctx, http.MethodGet, "/Groups", filterEq("displayName", name), nil, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Resources) == 0 {
+		return nil, fmt.Errorf("group %q: %w", name, storage.ErrNotFound)
+	}
+
+	sg := scimGroup{}
+	err = json.Unmarshal(list.Resources[0], &sg)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal scim group: %w", err)
+	}
+
+	gotGroup := mapSCIMToModelGroup(sg)
+
+	return &gotGroup, nil
+}
+
+func (r *repository) EnsureGroup(ctx context.Context, group model.Group) (*model.Group, error) {
+	err := checkGroupDescription(group)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := newPatchOp(scimPatchOperation{Op: "replace", Path: "displayName", Value: group.Name})
+	err = r.do(ctx, http.MethodPatch, "/Groups/"+url.PathEscape(group.ID), nil, patch, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (r *repository) DeleteGroup(ctx context.Context, id string) error {
+	return r.do(ctx, http.MethodDelete, "/Groups/"+url.PathEscape(id), nil, nil, nil)
+}
+
+func (r *repository) getGroup(ctx context.Context, id string) (*scimGroup, error) {
+	sg := scimGroup{}
+	err := r.do(ctx, http.MethodGet, "/Groups/"+url.PathEscape(id), nil, nil, &sg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sg, nil
+}
+
+type scimGroup struct {
+	Schemas     []string          `json:"schemas,omitempty"`
+	ID          string            `json:"id,omitempty"`
+	DisplayName string            `json:"displayName"`
+	Members     []scimGroupMember `json:"members,omitempty"`
+}
+
+type scimGroupMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// checkGroupDescription fails if the group has a description, SCIM groups don't have description
+// so it would be lost.
+func checkGroupDescription(group model.Group) error {
+	if group.Description != "" {
+		return fmt.Errorf("group descriptions are unsupported by the 1password SCIM bridge backend (use an empty description): %w", storage.ErrNotSupported)
+	}
+
+	return nil
+}
+
+// mapSCIMToModelGroup maps a SCIM group, SCIM groups don't have description.
+func mapSCIMToModelGroup(g scimGroup) model.Group {
+	return model.Group{
+		ID:   g.ID,
+		Name: g.DisplayName,
+	}
+}
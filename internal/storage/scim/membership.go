@@ -0,0 +1,58 @@
+package scim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+func (r *repository) EnsureMembership(ctx context.Context, membership model.Membership) error {
+	// SCIM doesn't know about group roles.
+	if membership.Role != model.MembershipRoleMember {
+		return fmt.Errorf("group member roles other than member are unsupported by the 1password SCIM bridge backend: %w", storage.ErrNotSupported)
+	}
+
+	patch := newPatchOp(scimPatchOperation{
+		Op:    "add",
+		Path:  "members",
+		Value: []scimGroupMember{{Value: membership.UserID}},
+	})
+	return r.do(ctx, http.MethodPatch, "/Groups/"+url.PathEscape(membership.GroupID), nil, patch, nil)
+}
+
+func (r *repository) DeleteMembership(ctx context.Context, membership model.Membership) error {
+	// SCIM removes missing members without failing.
+	_, err := r.GetMembershipByID(ctx, membership.GroupID, membership.UserID)
+	if err != nil {
+		return err
+	}
+
+	patch := newPatchOp(scimPatchOperation{
+		Op:   "remove",
+		Path: fmt.Sprintf(`members[value eq "%s"]`, escapeFilterValue(membership.UserID)),
+	})
+	return r.do(ctx, http.MethodPatch, "/Groups/"+url.PathEscape(membership.GroupID), nil, patch, nil)
+}
+
+func (r *repository) GetMembershipByID(ctx context.Context, groupID, userID string) (*model.Membership, error) {
+	sg, err := r.getGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range sg.Members {
+		if m.Value == userID {
+			return &model.Membership{
+				UserID:  userID,
+				GroupID: groupID,
+				Role:    model.MembershipRoleMember,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("member %q in group %q: %w", userID, groupID, storage.ErrNotFound)
+}
@@ -0,0 +1,246 @@
+package scim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+const (
+	schemaUser    = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup   = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaPatchOp = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	contentType   = "application/scim+json"
+)
+
+// RepositoryConfig is the configuration of the SCIM repository.
+type RepositoryConfig struct {
+	// URL is the 1password SCIM bridge URL (e.g: https://scim.mycompany.com).
+	URL string
+	// Token is the 1password SCIM bridge bearer token.
+	Token string
+	// HTTPClient is the client used to call the SCIM API.
+	HTTPClient *http.Client
+}
+
+func (c *RepositoryConfig) defaults() error {
+	if c.URL == "" {
+		return fmt.Errorf("scim url is required")
+	}
+	c.URL = strings.TrimSuffix(c.URL, "/")
+
+	if c.Token == "" {
+		return fmt.Errorf("scim token is required")
+	}
+
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return nil
+}
+
+type repository struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewRepository returns a 1password SCIM bridge based repository.
+//
+// SCIM only knows how to manage users, groups and group members, the rest of the operations
+// (vaults, items and accesses) will fail with `storage.ErrNotSupported`.
+// SCIM groups don't have description, so group descriptions are not managed.
+func NewRepository(config RepositoryConfig) (storage.Repository, error) {
+	err := config.defaults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return &repository{
+		url:    config.URL,
+		token:  config.Token,
+		client: config.HTTPClient,
+	}, nil
+}
+
+// scimError is the error returned by the SCIM API.
+type scimError struct {
+	Detail string `json:"detail"`
+}
+
+// do makes a request to the SCIM API, the body (if any) will be sent as JSON and
+// the response will be unmarshaled into out (if any).
+func (r *repository) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	u := r.url + "/scim/v2" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("could not marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+r.token)
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("scim api request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("could not read scim api response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		serr := scimError{}
+		_ = json.Unmarshal(data, &serr)
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("scim api request failed: %s %s: %w: %s", method, path, storage.ErrNotFound, serr.Detail)
+		}
+
+		return fmt.Errorf("scim api request failed: %s %s: %d: %s", method, path, resp.StatusCode, serr.Detail)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(data, out)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal scim api response: %w", err)
+	}
+
+	return nil
+}
+
+// filterEq returns a SCIM equality filter query.
+func filterEq(attribute, value string) url.Values {
+	return url.Values{"filter": []string{fmt.Sprintf(`%s eq "%s"`, attribute, escapeFilterValue(value))}}
+}
+
+var filterValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// escapeFilterValue escapes a value to be used as a SCIM filter string (a JSON string).
+func escapeFilterValue(value string) string {
+	return filterValueEscaper.Replace(value)
+}
+
+type scimListResponse struct {
+	TotalResults int               `json:"totalResults"`
+	Resources    []json.RawMessage `json:"Resources"`
+}
+
+type scimPatchOp struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []scimPatchOperation `json:"Operations"`
+}
+
+type scimPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+func newPatchOp(ops ...scimPatchOperation) scimPatchOp {
+	return scimPatchOp{
+		Schemas:    []string{schemaPatchOp},
+		Operations: ops,
+	}
+}
+
+func errNotSupported(resource string) error {
+	return fmt.Errorf("managing %s is unsupported by the 1password SCIM bridge backend: %w", resource, storage.ErrNotSupported)
+}
+
+func (r *repository) CreateVault(ctx context.Context, vault model.Vault) (*model.Vault, error) {
+	return nil, errNotSupported("vaults")
+}
+
+func (r *repository) GetVaultByID(ctx context.Context, id string) (*model.Vault, error) {
+	return nil, errNotSupported("vaults")
+}
+
+func (r *repository) GetVaultByName(ctx context.Context, name string) (*model.Vault, error) {
+	return nil, errNotSupported("vaults")
+}
+
+func (r *repository) EnsureVault(ctx context.Context, vault model.Vault) (*model.Vault, error) {
+	return nil, errNotSupported("vaults")
+}
+
+func (r *repository) DeleteVault(ctx context.Context, id string) error {
+	return errNotSupported("vaults")
+}
+
+func (r *repository) ListVaultsByUser(ctx context.Context, userID string) (*[]model.Vault, error) {
+	return nil, errNotSupported("vaults")
+}
+
+func (r *repository) EnsureVaultGroupAccess(ctx context.Context, groupAccess model.VaultGroupAccess) error {
+	return errNotSupported("vault accesses")
+}
+
+func (r *repository) DeleteVaultGroupAccess(ctx context.Context, vaultID string, groupID string) error {
+	return errNotSupported("vault accesses")
+}
+
+func (r *repository) GetVaultGroupAccessByID(ctx context.Context, vaultID string, groupID string) (*model.VaultGroupAccess, error) {
+	return nil, errNotSupported("vault accesses")
+}
+
+func (r *repository) EnsureVaultUserAccess(ctx context.Context, userAccess model.VaultUserAccess) error {
+	return errNotSupported("vault accesses")
+}
+
+func (r *repository) DeleteVaultUserAccess(ctx context.Context, vaultID string, userID string) error {
+	return errNotSupported("vault accesses")
+}
+
+func (r *repository) GetVaultUserAccessByID(ctx context.Context, vaultID string, userID string) (*model.VaultUserAccess, error) {
+	return nil, errNotSupported("vault accesses")
+}
+
+func (r *repository) CreateItem(ctx context.Context, item model.Item) (*model.Item, error) {
+	return nil, errNotSupported("items")
+}
+
+func (r *repository) GetItemByID(ctx context.Context, id string) (*model.Item, error) {
+	return nil, errNotSupported("items")
+}
+
+func (r *repository) GetItemByTitle(ctx context.Context, vaultID string, title string) (*model.Item, error) {
+	return nil, errNotSupported("items")
+}
+
+func (r *repository) EnsureItem(ctx context.Context, item model.Item) (*model.Item, error) {
+	return nil, errNotSupported("items")
+}
+
+func (r *repository) DeleteItem(ctx context.Context, id string) error {
+	return errNotSupported("items")
+}
@@ -0,0 +1,409 @@
+package scim_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage/scim"
+)
+
+const testToken = "test-token"
+
+// scimAPI is a minimal in memory stand-in of the 1password SCIM bridge API.
+type scimAPI struct {
+	mu     sync.Mutex
+	users  map[string]map[string]interface{}
+	groups map[string]map[string]interface{}
+	nextID int
+}
+
+func newSCIMAPI() *scimAPI {
+	return &scimAPI{
+		users: map[string]map[string]interface{}{
+			"user-00": {"id": "user-00", "userName": "test00@test.io", "displayName": "Test00", "active": true},
+			"user-01": {"id": "user-01", "userName": "test01@test.io", "displayName": "Test01", "active": true},
+		},
+		groups: map[string]map[string]interface{}{
+			"group-00": {"id": "group-00", "displayName": "Group00", "members": []interface{}{map[string]interface{}{"value": "user-00"}}},
+		},
+	}
+}
+
+var (
+	filterRegexp         = regexp.MustCompile(`^\w+ eq "((?:[^"\\]|\\.)*)"$`)
+	memberFilterRegexp   = regexp.MustCompile(`^members\[value eq "((?:[^"\\]|\\.)*)"\]$`)
+	filterValueUnescaper = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+)
+
+func (s *scimAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"status": "401", "detail": "Invalid token"})
+		return
+	}
+
+	notFound := map[string]interface{}{"status": "404", "detail": "Not found"}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/scim/v2/"), "/")
+
+	var objs map[string]map[string]interface{}
+	var filterAttr string
+	switch parts[0] {
+	case "Users":
+		objs, filterAttr = s.users, "userName"
+	case "Groups":
+		objs, filterAttr = s.groups, "displayName"
+	default:
+		writeJSON(w, http.StatusNotFound, notFound)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		value := ""
+		if filter := r.URL.Query().Get("filter"); filter != "" {
+			m := filterRegexp.FindStringSubmatch(filter)
+			if m == nil {
+				writeJSON(w, http.StatusBadRequest, map[string]interface{}{"status": "400", "detail": "Invalid filter"})
+				return
+			}
+			value = filterValueUnescaper.Replace(m[1])
+		}
+		res := []interface{}{}
+		for _, o := range objs {
+			if value == "" || o[filterAttr] == value {
+				res = append(res, o)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"totalResults": len(res), "Resources": res})
+
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		obj := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&obj)
+		// Like SCIM, user names are unique.
+		for _, o := range objs {
+			if parts[0] == "Users" && o["userName"] == obj["userName"] {
+				writeJSON(w, http.StatusConflict, map[string]interface{}{"status": "409", "detail": "User already exists"})
+				return
+			}
+		}
+		obj["id"] = fmt.Sprintf("new-%02d", s.nextID)
+		s.nextID++
+		objs[obj["id"].(string)] = obj
+		writeJSON(w, http.StatusCreated, obj)
+
+	case len(parts) == 2:
+		obj, ok := objs[parts[1]]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, notFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, obj)
+		case http.MethodDelete:
+			delete(objs, parts[1])
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPatch:
+			patch := struct {
+				Operations []struct {
+					Op    string          `json:"op"`
+					Path  string          `json:"path"`
+					Value json.RawMessage `json:"value"`
+				}
+			}{}
+			_ = json.NewDecoder(r.Body).Decode(&patch)
+			for _, op := range patch.Operations {
+				members, _ := obj["members"].([]interface{})
+				switch {
+				case op.Op == "replace":
+					var v interface{}
+					_ = json.Unmarshal(op.Value, &v)
+					obj[op.Path] = v
+				case op.Op == "add" && op.Path == "members":
+					var v []interface{}
+					_ = json.Unmarshal(op.Value, &v)
+					obj["members"] = append(members, v...)
+				case op.Op == "remove":
+					m := memberFilterRegexp.FindStringSubmatch(op.Path)
+					if m == nil {
+						writeJSON(w, http.StatusBadRequest, map[string]interface{}{"status": "400", "detail": "Invalid path"})
+						return
+					}
+					value := filterValueUnescaper.Replace(m[1])
+					newMembers := []interface{}{}
+					for _, mb := range members {
+						if mb.(map[string]interface{})["value"] != value {
+							newMembers = append(newMembers, mb)
+						}
+					}
+					obj["members"] = newMembers
+				}
+			}
+			writeJSON(w, http.StatusOK, obj)
+		}
+
+	default:
+		writeJSON(w, http.StatusNotFound, notFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestRepository(t *testing.T) {
+	tests := map[string]struct {
+		token    string
+		exec     func(r storage.Repository) (interface{}, error)
+		expObj   interface{}
+		expErr   bool
+		expErrIs error
+	}{
+		"Creating a user should return the user with its ID.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.CreateUser(context.TODO(), model.User{Email: "test02@test.io", Name: "Test02"})
+			},
+			expObj: &model.User{ID: "new-00", Email: "test02@test.io", Name: "Test02"},
+		},
+
+		"Getting a user by ID should return the user.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetUserByID(context.TODO(), "user-00")
+			},
+			expObj: &model.User{ID: "user-00", Email: "test00@test.io", Name: "Test00"},
+		},
+
+		"Getting a missing user by ID should fail with not found.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetUserByID(context.TODO(), "user-99")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotFound,
+		},
+
+		"Getting a user by email should return the user.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetUserByEmail(context.TODO(), "test01@test.io")
+			},
+			expObj: &model.User{ID: "user-01", Email: "test01@test.io", Name: "Test01"},
+		},
+
+		"Getting a missing user by email should fail with not found.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetUserByEmail(context.TODO(), "test99@test.io")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotFound,
+		},
+
+		"Updating a user should update its name.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				_, err := r.EnsureUser(context.TODO(), model.User{ID: "user-00", Email: "test00@test.io", Name: "Test00-modified"})
+				if err != nil {
+					return nil, err
+				}
+				return r.GetUserByID(context.TODO(), "user-00")
+			},
+			expObj: &model.User{ID: "user-00", Email: "test00@test.io", Name: "Test00-modified"},
+		},
+
+		"Deleting a user should delete the user.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				err := r.DeleteUser(context.TODO(), "user-00")
+				if err != nil {
+					return nil, err
+				}
+				return r.GetUserByID(context.TODO(), "user-00")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotFound,
+		},
+
+		"Creating a group should return the group with its ID.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.CreateGroup(context.TODO(), model.Group{Name: "Group01"})
+			},
+			expObj: &model.Group{ID: "new-00", Name: "Group01"},
+		},
+
+		"Creating a group with a description should not be supported.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.CreateGroup(context.TODO(), model.Group{Name: "Group01", Description: "Group 01"})
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotSupported,
+		},
+
+		"Creating a group with an existing name should fail.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.CreateGroup(context.TODO(), model.Group{Name: "Group00"})
+			},
+			expErr: true,
+		},
+
+		"Getting a group by name should return the group.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetGroupByName(context.TODO(), "Group00")
+			},
+			expObj: &model.Group{ID: "group-00", Name: "Group00"},
+		},
+
+		"Renaming a group should update its name.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				_, err := r.EnsureGroup(context.TODO(), model.Group{ID: "group-00", Name: "Group00-modified"})
+				if err != nil {
+					return nil, err
+				}
+				return r.GetGroupByID(context.TODO(), "group-00")
+			},
+			expObj: &model.Group{ID: "group-00", Name: "Group00-modified"},
+		},
+
+		"Setting a group description should not be supported.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.EnsureGroup(context.TODO(), model.Group{ID: "group-00", Name: "Group00", Description: "Group 00"})
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotSupported,
+		},
+
+		"Getting a group member should return the membership.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetMembershipByID(context.TODO(), "group-00", "user-00")
+			},
+			expObj: &model.Membership{GroupID: "group-00", UserID: "user-00", Role: model.MembershipRoleMember},
+		},
+
+		"Getting a missing group member should fail with not found.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetMembershipByID(context.TODO(), "group-00", "user-01")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotFound,
+		},
+
+		"Adding a group member should add the user to the group.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				err := r.EnsureMembership(context.TODO(), model.Membership{GroupID: "group-00", UserID: "user-01"})
+				if err != nil {
+					return nil, err
+				}
+				return r.GetMembershipByID(context.TODO(), "group-00", "user-01")
+			},
+			expObj: &model.Membership{GroupID: "group-00", UserID: "user-01", Role: model.MembershipRoleMember},
+		},
+
+		"Adding a group manager should not be supported.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return nil, r.EnsureMembership(context.TODO(), model.Membership{GroupID: "group-00", UserID: "user-01", Role: model.MembershipRoleManager})
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotSupported,
+		},
+
+		"Removing a group member should remove the user from the group.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				err := r.DeleteMembership(context.TODO(), model.Membership{GroupID: "group-00", UserID: "user-00"})
+				if err != nil {
+					return nil, err
+				}
+				return r.GetMembershipByID(context.TODO(), "group-00", "user-00")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotFound,
+		},
+
+		"Removing a group member with quotes and backslashes on the ID should only remove that user from the group.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				m := model.Membership{GroupID: "group-00", UserID: `user-"02\`}
+				err := r.EnsureMembership(context.TODO(), m)
+				if err != nil {
+					return nil, err
+				}
+				err = r.DeleteMembership(context.TODO(), m)
+				if err != nil {
+					return nil, err
+				}
+				_, err = r.GetMembershipByID(context.TODO(), m.GroupID, m.UserID)
+				if !errors.Is(err, storage.ErrNotFound) {
+					return nil, fmt.Errorf("expected not found membership, got: %w", err)
+				}
+				return r.GetMembershipByID(context.TODO(), "group-00", "user-00")
+			},
+			expObj: &model.Membership{GroupID: "group-00", UserID: "user-00", Role: model.MembershipRoleMember},
+		},
+
+		"Getting a group by a name with quotes and backslashes should return the group.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				_, err := r.CreateGroup(context.TODO(), model.Group{Name: `Group "01"\`})
+				if err != nil {
+					return nil, err
+				}
+				return r.GetGroupByName(context.TODO(), `Group "01"\`)
+			},
+			expObj: &model.Group{ID: "new-00", Name: `Group "01"\`},
+		},
+
+		"Managing vaults should not be supported.": {
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetVaultByID(context.TODO(), "vault-00")
+			},
+			expErr:   true,
+			expErrIs: storage.ErrNotSupported,
+		},
+
+		"Using an invalid token should fail.": {
+			token: "wrong",
+			exec: func(r storage.Repository) (interface{}, error) {
+				return r.GetUserByID(context.TODO(), "user-00")
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			assert := assert.New(t)
+
+			srv := httptest.NewServer(newSCIMAPI())
+			defer srv.Close()
+
+			token := test.token
+			if token == "" {
+				token = testToken
+			}
+			repo, err := scim.NewRepository(scim.RepositoryConfig{URL: srv.URL, Token: token})
+			require.NoError(err)
+
+			gotObj, err := test.exec(repo)
+
+			if test.expErr {
+				assert.Error(err)
+				if test.expErrIs != nil {
+					assert.True(errors.Is(err, test.expErrIs), err)
+				}
+			} else if assert.NoError(err) {
+				assert.Equal(test.expObj, gotObj)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+func (r *repository) CreateUser(ctx context.Context, user model.User) (*model.User, error) {
+	su := mapModelToSCIMUser(user)
+
+	created := scimUser{}
+	err := r.do(ctx, http.MethodPost, "/Users", nil, su, &created)
+	if err != nil {
+		return nil, err
+	}
+
+	gotUser := mapSCIMToModelUser(created)
+
+	return &gotUser, nil
+}
+
+func (r *repository) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	su := scimUser{}
+	err := r.do(ctx, http.MethodGet, "/Users/"+url.PathEscape(id), nil, nil, &su)
+	if err != nil {
+		return nil, err
+	}
+
+	gotUser := mapSCIMToModelUser(su)
+
+	return &gotUser, nil
+}
+
+func (r *repository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	list := scimListResponse{}
+	err := r.do(ctx, http.MethodGet, "/Users", filterEq("userName", email), nil, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Resources) == 0 {
+		return nil, fmt.Errorf("user %q: %w", email, storage.ErrNotFound)
+	}
+
+	su := scimUser{}
+	err = json.Unmarshal(list.Resources[0], &su)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal scim user: %w", err)
+	}
+
+	gotUser := mapSCIMToModelUser(su)
+
+	return &gotUser, nil
+}
+
+func (r *repository) EnsureUser(ctx context.Context, user model.User) (*model.User, error) {
+	patch := newPatchOp(scimPatchOperation{Op: "replace", Path: "displayName", Value: user.Name})
+	err := r.do(ctx, http.MethodPatch, "/Users/"+url.PathEscape(user.ID), nil, patch, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *repository) DeleteUser(ctx context.Context, id string) error {
+	return r.do(ctx, http.MethodDelete, "/Users/"+url.PathEscape(id), nil, nil, nil)
+}
+
+type scimUser struct {
+	Schemas     []string    `json:"schemas,omitempty"`
+	ID          string      `json:"id,omitempty"`
+	UserName    string      `json:"userName"`
+	DisplayName string      `json:"displayName"`
+	Emails      []scimEmail `json:"emails,omitempty"`
+	Active      bool        `json:"active"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+func mapModelToSCIMUser(u model.User) scimUser {
+	return scimUser{
+		Schemas:     []string{schemaUser},
+		ID:          u.ID,
+		UserName:    u.Email,
+		DisplayName: u.Name,
+		Emails:      []scimEmail{{Value: u.Email, Primary: true}},
+		Active:      true,
+	}
+}
+
+func mapSCIMToModelUser(u scimUser) model.User {
+	return model.User{
+		ID:    u.ID,
+		Email: u.UserName,
+		Name:  u.DisplayName,
+	}
+}
@@ -2,10 +2,19 @@ package storage
 
 import (
 	"context"
+	"errors"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
 )
 
+// ErrNotFound is returned by the repositories when the requested object doesn't exist.
+// Callers should check it with `errors.Is`, as implementations wrap it with more context.
+var ErrNotFound = errors.New("not found")
+
+// ErrNotSupported is returned by the repositories when the operation can't be performed by
+// the repository implementation (e.g: the backend or the authentication method doesn't allow it).
+var ErrNotSupported = errors.New("not supported")
+
 type Repository interface {
 	CreateUser(ctx context.Context, user model.User) (*model.User, error)
 	GetUserByID(ctx context.Context, id string) (*model.User, error)
@@ -44,3 +53,21 @@ type Repository interface {
 	EnsureItem(ctx context.Context, item model.Item) (*model.Item, error)
 	DeleteItem(ctx context.Context, id string) error
 }
+
+// GroupMembershipLister is an optional interface that repositories can implement when
+// they are able to get all the memberships of a group at once (e.g: to be cached).
+type GroupMembershipLister interface {
+	ListMembershipsByGroup(ctx context.Context, groupID string) (*[]model.Membership, error)
+}
+
+// VaultGroupAccessLister is an optional interface that repositories can implement when
+// they are able to get all the group accesses of a vault at once (e.g: to be cached).
+type VaultGroupAccessLister interface {
+	ListVaultGroupAccessesByVault(ctx context.Context, vaultID string) (*[]model.VaultGroupAccess, error)
+}
+
+// VaultUserAccessLister is an optional interface that repositories can implement when
+// they are able to get all the user accesses of a vault at once (e.g: to be cached).
+type VaultUserAccessLister interface {
+	ListVaultUserAccessesByVault(ctx context.Context, vaultID string) (*[]model.VaultUserAccess, error)
+}
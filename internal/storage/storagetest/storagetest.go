@@ -0,0 +1,414 @@
+// Package storagetest has a conformance test suite for the `storage.Repository` implementations,
+// so every backend has the same semantics the provider relies on.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/model"
+	"github.com/slok/terraform-provider-onepasswordorg/internal/storage"
+)
+
+// RepositoryFactory returns a new empty repository for a test. Repositories that can't create
+// vaults (e.g: Connect) must only have a vault named `vault`.
+type RepositoryFactory func(t *testing.T) storage.Repository
+
+// RunRepositoryTests runs the conformance tests on the repositories returned by the factory, a new
+// repository is created for every test.
+//
+// The tests check the CRUD, lookups, not found errors (`storage.ErrNotFound`) and accesses semantics.
+// The optional list interfaces (e.g: `storage.GroupMembershipLister`) are tested when the repository
+// implements them, and the tests are skipped when the repository doesn't support an operation
+// (`storage.ErrNotSupported`).
+func RunRepositoryTests(t *testing.T, newRepository RepositoryFactory) {
+	// Run in order, the backends may share resources between tests (e.g: env vars).
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo storage.Repository)
+	}{
+		{name: "Users", test: testUsers},
+		{name: "Groups", test: testGroups},
+		{name: "Vaults", test: testVaults},
+		{name: "Memberships", test: testMemberships},
+		{name: "VaultGroupAccesses", test: testVaultGroupAccesses},
+		{name: "VaultUserAccesses", test: testVaultUserAccesses},
+		{name: "Items", test: testItems},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, newRepository(t))
+		})
+	}
+}
+
+// requireSupported skips the test if the repository doesn't support the operation.
+func requireSupported(t *testing.T, err error) {
+	t.Helper()
+
+	if errors.Is(err, storage.ErrNotSupported) {
+		t.Skipf("operation not supported by the repository: %s", err)
+	}
+	require.NoError(t, err)
+}
+
+func testUsers(t *testing.T, repo storage.Repository) {
+	ctx := context.TODO()
+
+	user, err := repo.CreateUser(ctx, model.User{Email: "user@example.com", Name: "User"})
+	requireSupported(t, err)
+	assert.NotEmpty(t, user.ID)
+	assert.Equal(t, model.User{ID: user.ID, Email: "user@example.com", Name: "User"}, *user)
+
+	_, err = repo.CreateUser(ctx, model.User{Email: "user@example.com", Name: "Other"})
+	assert.Error(t, err, "users with the same email can't be created")
+
+	got, err := repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+	got, err = repo.GetUserByEmail(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+
+	// Users are updated by ID.
+	user.Name = "User renamed"
+	got, err = repo.EnsureUser(ctx, *user)
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+	got, err = repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+
+	_, err = repo.EnsureUser(ctx, model.User{ID: "missing", Email: "missing@example.com", Name: "Missing"})
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, repo.DeleteUser(ctx, user.ID))
+	_, err = repo.GetUserByID(ctx, user.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = repo.GetUserByEmail(ctx, "user@example.com")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteUser(ctx, user.ID), storage.ErrNotFound)
+}
+
+func testGroups(t *testing.T, repo storage.Repository) {
+	ctx := context.TODO()
+
+	// Backends without group descriptions (e.g: SCIM) must fail instead of losing them.
+	description := "Group"
+	group, err := repo.CreateGroup(ctx, model.Group{Name: "group", Description: description})
+	if errors.Is(err, storage.ErrNotSupported) {
+		description = ""
+		group, err = repo.CreateGroup(ctx, model.Group{Name: "group"})
+	}
+	requireSupported(t, err)
+	assert.NotEmpty(t, group.ID)
+	assert.Equal(t, model.Group{ID: group.ID, Name: "group", Description: description}, *group)
+
+	_, err = repo.CreateGroup(ctx, model.Group{Name: "group"})
+	assert.Error(t, err, "groups with the same name can't be created")
+
+	got, err := repo.GetGroupByID(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, group, got)
+	got, err = repo.GetGroupByName(ctx, "group")
+	require.NoError(t, err)
+	assert.Equal(t, group, got)
+
+	if description != "" {
+		group.Description = "Group modified"
+	} else {
+		_, err = repo.EnsureGroup(ctx, model.Group{ID: group.ID, Name: group.Name, Description: "Group modified"})
+		assert.ErrorIs(t, err, storage.ErrNotSupported)
+	}
+	got, err = repo.EnsureGroup(ctx, *group)
+	require.NoError(t, err)
+	assert.Equal(t, group, got)
+	got, err = repo.GetGroupByID(ctx, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, group, got)
+
+	_, err = repo.EnsureGroup(ctx, model.Group{ID: "missing", Name: "missing"})
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, repo.DeleteGroup(ctx, group.ID))
+	_, err = repo.GetGroupByID(ctx, group.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = repo.GetGroupByName(ctx, "group")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteGroup(ctx, group.ID), storage.ErrNotFound)
+}
+
+func testVaults(t *testing.T, repo storage.Repository) {
+	ctx := context.TODO()
+
+	vault, err := repo.CreateVault(ctx, model.Vault{Name: "vault", Description: "Vault"})
+	if errors.Is(err, storage.ErrNotSupported) {
+		// Only the lookups can be checked on the existing vault.
+		vault, err := repo.GetVaultByName(ctx, "vault")
+		requireSupported(t, err)
+		got, err := repo.GetVaultByID(ctx, vault.ID)
+		require.NoError(t, err)
+		assert.Equal(t, vault, got)
+		_, err = repo.GetVaultByID(ctx, "missing")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		_, err = repo.GetVaultByName(ctx, "missing")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	}
+	requireSupported(t, err)
+	assert.NotEmpty(t, vault.ID)
+	assert.Equal(t, model.Vault{ID: vault.ID, Name: "vault", Description: "Vault"}, *vault)
+
+	_, err = repo.CreateVault(ctx, model.Vault{Name: "vault"})
+	assert.Error(t, err, "vaults with the same name can't be created")
+
+	got, err := repo.GetVaultByID(ctx, vault.ID)
+	require.NoError(t, err)
+	assert.Equal(t, vault, got)
+	got, err = repo.GetVaultByName(ctx, "vault")
+	require.NoError(t, err)
+	assert.Equal(t, vault, got)
+
+	vault.Name = "vault-renamed"
+	vault.Description = "Vault modified"
+	got, err = repo.EnsureVault(ctx, *vault)
+	require.NoError(t, err)
+	assert.Equal(t, vault, got)
+	got, err = repo.GetVaultByName(ctx, "vault-renamed")
+	require.NoError(t, err)
+	assert.Equal(t, vault, got)
+	_, err = repo.GetVaultByName(ctx, "vault")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	_, err = repo.EnsureVault(ctx, model.Vault{ID: "missing", Name: "missing"})
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, repo.DeleteVault(ctx, vault.ID))
+	_, err = repo.GetVaultByID(ctx, vault.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteVault(ctx, vault.ID), storage.ErrNotFound)
+}
+
+func testMemberships(t *testing.T, repo storage.Repository) {
+	ctx := context.TODO()
+
+	user, err := repo.CreateUser(ctx, model.User{Email: "user@example.com", Name: "User"})
+	requireSupported(t, err)
+	group, err := repo.CreateGroup(ctx, model.Group{Name: "group"})
+	requireSupported(t, err)
+
+	_, err = repo.GetMembershipByID(ctx, group.ID, user.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	membership := model.Membership{GroupID: group.ID, UserID: user.ID, Role: model.MembershipRoleMember}
+	require.NoError(t, repo.EnsureMembership(ctx, membership))
+	got, err := repo.GetMembershipByID(ctx, group.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &membership, got)
+
+	// Ensuring an existing membership updates the role, backends with only member roles
+	// (e.g: SCIM) must fail instead of ignoring it.
+	manager := membership
+	manager.Role = model.MembershipRoleManager
+	err = repo.EnsureMembership(ctx, manager)
+	if !errors.Is(err, storage.ErrNotSupported) {
+		require.NoError(t, err)
+		membership = manager
+	}
+	got, err = repo.GetMembershipByID(ctx, group.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &membership, got)
+
+	if lister, ok := repo.(storage.GroupMembershipLister); ok {
+		ms, err := lister.ListMembershipsByGroup(ctx, group.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &[]model.Membership{membership}, ms)
+	}
+
+	require.NoError(t, repo.DeleteMembership(ctx, membership))
+	_, err = repo.GetMembershipByID(ctx, group.ID, user.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteMembership(ctx, membership), storage.ErrNotFound)
+
+	if lister, ok := repo.(storage.GroupMembershipLister); ok {
+		ms, err := lister.ListMembershipsByGroup(ctx, group.ID)
+		require.NoError(t, err)
+		assert.Empty(t, *ms)
+	}
+}
+
+func testVaultGroupAccesses(t *testing.T, repo storage.Repository) {
+	ctx := context.TODO()
+
+	group, err := repo.CreateGroup(ctx, model.Group{Name: "group"})
+	requireSupported(t, err)
+	vault, err := repo.CreateVault(ctx, model.Vault{Name: "vault"})
+	requireSupported(t, err)
+
+	_, err = repo.GetVaultGroupAccessByID(ctx, vault.ID, group.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	access := model.VaultGroupAccess{VaultID: vault.ID, GroupID: group.ID, Permissions: model.AccessPermissions{AllowViewing: true, AllowEditing: true}}
+	require.NoError(t, repo.EnsureVaultGroupAccess(ctx, access))
+	got, err := repo.GetVaultGroupAccessByID(ctx, vault.ID, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &access, got)
+
+	// Ensuring an existing access replaces the permissions.
+	access.Permissions = model.AccessPermissions{AllowViewing: true}
+	require.NoError(t, repo.EnsureVaultGroupAccess(ctx, access))
+	got, err = repo.GetVaultGroupAccessByID(ctx, vault.ID, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &access, got)
+
+	if lister, ok := repo.(storage.VaultGroupAccessLister); ok {
+		as, err := lister.ListVaultGroupAccessesByVault(ctx, vault.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &[]model.VaultGroupAccess{access}, as)
+	}
+
+	// The user vaults include the ones the user has access to through a group.
+	user, err := repo.CreateUser(ctx, model.User{Email: "user@example.com", Name: "User"})
+	if !errors.Is(err, storage.ErrNotSupported) {
+		require.NoError(t, err)
+		require.NoError(t, repo.EnsureMembership(ctx, model.Membership{GroupID: group.ID, UserID: user.ID, Role: model.MembershipRoleMember}))
+		vaults, err := repo.ListVaultsByUser(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &[]model.Vault{*vault}, vaults)
+	}
+
+	require.NoError(t, repo.DeleteVaultGroupAccess(ctx, vault.ID, group.ID))
+	_, err = repo.GetVaultGroupAccessByID(ctx, vault.ID, group.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteVaultGroupAccess(ctx, vault.ID, group.ID), storage.ErrNotFound)
+
+	if user != nil {
+		vaults, err := repo.ListVaultsByUser(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, *vaults)
+	}
+}
+
+func testVaultUserAccesses(t *testing.T, repo storage.Repository) {
+	ctx := context.TODO()
+
+	user, err := repo.CreateUser(ctx, model.User{Email: "user@example.com", Name: "User"})
+	requireSupported(t, err)
+	vault, err := repo.CreateVault(ctx, model.Vault{Name: "vault", Description: "Vault"})
+	requireSupported(t, err)
+	_, err = repo.CreateVault(ctx, model.Vault{Name: "other-vault"})
+	require.NoError(t, err)
+
+	_, err = repo.GetVaultUserAccessByID(ctx, vault.ID, user.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	access := model.VaultUserAccess{VaultID: vault.ID, UserID: user.ID, Permissions: model.AccessPermissions{ViewItems: true, ManageVault: true}}
+	require.NoError(t, repo.EnsureVaultUserAccess(ctx, access))
+	got, err := repo.GetVaultUserAccessByID(ctx, vault.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &access, got)
+
+	// Ensuring an existing access replaces the permissions.
+	access.Permissions = model.AccessPermissions{ViewItems: true}
+	require.NoError(t, repo.EnsureVaultUserAccess(ctx, access))
+	got, err = repo.GetVaultUserAccessByID(ctx, vault.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &access, got)
+
+	if lister, ok := repo.(storage.VaultUserAccessLister); ok {
+		as, err := lister.ListVaultUserAccessesByVault(ctx, vault.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &[]model.VaultUserAccess{access}, as)
+	}
+
+	// The user vaults are the ones the user has access to.
+	vaults, err := repo.ListVaultsByUser(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &[]model.Vault{*vault}, vaults)
+
+	require.NoError(t, repo.DeleteVaultUserAccess(ctx, vault.ID, user.ID))
+	_, err = repo.GetVaultUserAccessByID(ctx, vault.ID, user.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteVaultUserAccess(ctx, vault.ID, user.ID), storage.ErrNotFound)
+
+	vaults, err = repo.ListVaultsByUser(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, *vaults)
+}
+
+func testItems(t *testing.T, repo storage.Repository) {
+	ctx := context.TODO()
+
+	vault, err := repo.CreateVault(ctx, model.Vault{Name: "vault"})
+	if errors.Is(err, storage.ErrNotSupported) {
+		vault, err = repo.GetVaultByName(ctx, "vault")
+	}
+	requireSupported(t, err)
+
+	section := model.Section{ID: "s1", Label: "Section"}
+	item := model.Item{
+		Vault:    *vault,
+		Title:    "item",
+		Category: "login",
+		Tags:     []string{"tag"},
+		URLs:     []model.URL{{URL: "https://example.com", Primary: true}},
+		Sections: []model.Section{section},
+		Fields: []model.Field{
+			{ID: "password", Type: "CONCEALED", Purpose: "PASSWORD", Label: "password", Value: "secret"},
+			{ID: "f1", Type: "STRING", Label: "field", Value: "value", Section: &section},
+		},
+	}
+	created, err := repo.CreateItem(ctx, item)
+	requireSupported(t, err)
+	assert.NotEmpty(t, created.ID)
+	item.ID = created.ID
+	assertItem(t, item, created)
+
+	got, err := repo.GetItemByID(ctx, item.ID)
+	require.NoError(t, err)
+	assertItem(t, item, got)
+	got, err = repo.GetItemByTitle(ctx, vault.ID, "item")
+	require.NoError(t, err)
+	assertItem(t, item, got)
+
+	// Ensuring an item replaces the content, the removed fields and sections are removed.
+	item.Title = "item-renamed"
+	item.Fields = item.Fields[:1]
+	item.Fields[0].Value = "other-secret"
+	item.Sections = []model.Section{}
+	got, err = repo.EnsureItem(ctx, item)
+	require.NoError(t, err)
+	assertItem(t, item, got)
+	got, err = repo.GetItemByTitle(ctx, vault.ID, "item-renamed")
+	require.NoError(t, err)
+	assertItem(t, item, got)
+	_, err = repo.GetItemByTitle(ctx, vault.ID, "item")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	missing := item
+	missing.ID = "missing"
+	_, err = repo.EnsureItem(ctx, missing)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, repo.DeleteItem(ctx, item.ID))
+	_, err = repo.GetItemByID(ctx, item.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteItem(ctx, item.ID), storage.ErrNotFound)
+}
+
+// assertItem checks the item, only the ID of the item vault is checked because backends may
+// not return the vault name (e.g: Connect).
+func assertItem(t *testing.T, exp model.Item, got *model.Item) {
+	t.Helper()
+
+	require.NotNil(t, got)
+	gotItem := *got
+	assert.Equal(t, exp.Vault.ID, gotItem.Vault.ID)
+	exp.Vault, gotItem.Vault = model.Vault{}, model.Vault{}
+	assert.Equal(t, exp, gotItem)
+}
@@ -0,0 +1,148 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	// InstrumentationName is the name of the OpenTelemetry tracers and meters of the provider.
+	InstrumentationName = "github.com/slok/terraform-provider-onepasswordorg"
+
+	serviceName = "terraform-provider-onepasswordorg"
+
+	// EnvVarExporterFilePath is the env var that sets a local file to export the traces and
+	// metrics to (as JSON lines), instead of using OTLP.
+	EnvVarExporterFilePath = "OTEL_EXPORTER_FILE_PATH"
+	envVarExporterPrefix   = "OTEL_EXPORTER_"
+)
+
+var state struct {
+	sync.Mutex
+	configured bool
+	enabled    bool
+	shutdowns  []func(context.Context) error
+}
+
+// Setup configures the global OpenTelemetry tracer and meter providers, only when any
+// `OTEL_EXPORTER_*` env var is set, otherwise telemetry is disabled and the global
+// providers stay as no-op ones.
+//
+// By default traces and metrics are exported using OTLP over HTTP (configured with the
+// standard `OTEL_EXPORTER_OTLP_*` env vars), if `OTEL_EXPORTER_FILE_PATH` is set they
+// will be written to that file instead.
+//
+// Setup can be called multiple times (e.g: provider aliases), only the first one will
+// configure the providers.
+func Setup(ctx context.Context) (enabled bool, err error) {
+	state.Lock()
+	defer state.Unlock()
+
+	if state.configured {
+		return state.enabled, nil
+	}
+
+	if !exporterEnvSet() {
+		state.configured = true
+		return false, nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("could not create telemetry resource: %w", err)
+	}
+
+	var (
+		traceExporter  sdktrace.SpanExporter
+		metricExporter sdkmetric.Exporter
+	)
+	if path := os.Getenv(EnvVarExporterFilePath); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return false, fmt.Errorf("could not open telemetry file: %w", err)
+		}
+		state.shutdowns = append(state.shutdowns, func(context.Context) error { return f.Close() })
+
+		traceExporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			return false, fmt.Errorf("could not create file trace exporter: %w", err)
+		}
+
+		metricExporter, err = stdoutmetric.New(stdoutmetric.WithWriter(f))
+		if err != nil {
+			return false, fmt.Errorf("could not create file metric exporter: %w", err)
+		}
+	} else {
+		traceExporter, err = otlptracehttp.New(ctx)
+		if err != nil {
+			return false, fmt.Errorf("could not create OTLP trace exporter: %w", err)
+		}
+
+		metricExporter, err = otlpmetrichttp.New(ctx)
+		if err != nil {
+			return false, fmt.Errorf("could not create OTLP metric exporter: %w", err)
+		}
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(traceExporter), sdktrace.WithResource(res))
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)), sdkmetric.WithResource(res))
+	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
+
+	// Providers must be shut down (flushed) before closing the file.
+	state.shutdowns = append([]func(context.Context) error{tp.Shutdown, mp.Shutdown}, state.shutdowns...)
+	state.configured = true
+	state.enabled = true
+
+	return true, nil
+}
+
+// Shutdown flushes the pending telemetry and releases the exporters, it should be called
+// when the plugin exits.
+func Shutdown(ctx context.Context) error {
+	state.Lock()
+	defer state.Unlock()
+
+	errs := []error{}
+	for _, shutdown := range state.shutdowns {
+		if err := shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	state.shutdowns = nil
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("could not shutdown telemetry: %w", err)
+	}
+
+	return nil
+}
+
+func exporterEnvSet() bool {
+	for _, env := range os.Environ() {
+		kv := strings.SplitN(env, "=", 2)
+		if strings.HasPrefix(kv[0], envVarExporterPrefix) && len(kv) == 2 && kv[1] != "" {
+			return true
+		}
+	}
+
+	return false
+}
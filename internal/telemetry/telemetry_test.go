@@ -0,0 +1,38 @@
+package telemetry_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	"github.com/slok/terraform-provider-onepasswordorg/internal/telemetry"
+)
+
+func TestSetupFileExporter(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "telemetry.json")
+	t.Setenv(telemetry.EnvVarExporterFilePath, path)
+
+	enabled, err := telemetry.Setup(context.TODO())
+	require.NoError(err)
+	assert.True(enabled)
+
+	_, span := otel.Tracer(telemetry.InstrumentationName).Start(context.TODO(), "test-span")
+	span.End()
+
+	// Shutdown should flush the telemetry into the file.
+	err = telemetry.Shutdown(context.TODO())
+	require.NoError(err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(err)
+	assert.Contains(string(data), "test-span")
+	assert.Contains(string(data), "terraform-provider-onepasswordorg")
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"context"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
 
 	"github.com/slok/terraform-provider-onepasswordorg/internal/provider"
 )
@@ -10,9 +12,17 @@ import (
 const providerName = "registry.terraform.io/slok/onepasswordorg"
 
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: func() *schema.Provider {
-			return provider.Provider()
-		},
-	})
+	server, err := provider.ProviderServer(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = tf6server.Serve(providerName, server)
+
+	// Remove the temporary data of the provider instances (e.g: op config dirs).
+	_ = provider.CleanUp()
+
+	if err != nil {
+		log.Fatal(err)
+	}
 }